@@ -1,13 +1,20 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/Shaked/agentic-repo/internal/cli"
+	"github.com/Shaked/agentic-repo/internal/plugin"
 )
 
 func main() {
 	if err := cli.Execute(); err != nil {
+		// Plugin subcommands exit with the plugin's own status
+		var exitErr *plugin.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
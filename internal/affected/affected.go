@@ -0,0 +1,100 @@
+// Package affected maps the files a change touches to the projects of a
+// repository that own them and the projects that depend on those.
+package affected
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/arch"
+)
+
+// Project is a project touched by a change
+type Project struct {
+	// Dir is the root-relative directory in slash form, "." for the root
+	Dir string
+	// Changed lists the project's changed files; it is empty for
+	// projects affected only through their dependencies
+	Changed []string
+	// Via lists the changed projects this one depends on, directly or
+	// transitively
+	Via []string
+}
+
+// Result is the outcome of matching a change to projects
+type Result struct {
+	// Projects are sorted by Dir
+	Projects []Project
+	// Unowned lists the changed files outside every project
+	Unowned []string
+}
+
+// Match assigns each changed file, root-relative in slash form, to the
+// deepest project directory containing it and adds the projects that
+// depend on a changed one according to deps, which may be nil
+func Match(changed, dirs []string, deps *arch.Graph) Result {
+	var result Result
+	owned := map[string][]string{}
+	for _, file := range changed {
+		if dir, ok := owner(file, dirs); ok {
+			owned[dir] = append(owned[dir], file)
+		} else {
+			result.Unowned = append(result.Unowned, file)
+		}
+	}
+
+	via := map[string][]string{}
+	if deps != nil {
+		for dir := range owned {
+			for _, dependent := range deps.Impact(dir) {
+				via[dependent] = append(via[dependent], dir)
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		changed, direct := owned[dir]
+		dependsOn, indirect := via[dir]
+		if !direct && !indirect {
+			continue
+		}
+		sort.Strings(changed)
+		sort.Strings(dependsOn)
+		result.Projects = append(result.Projects, Project{Dir: dir, Changed: changed, Via: dependsOn})
+	}
+	sort.Slice(result.Projects, func(i, j int) bool { return result.Projects[i].Dir < result.Projects[j].Dir })
+	sort.Strings(result.Unowned)
+	return result
+}
+
+// Dirs returns the directories of the affected projects
+func (r Result) Dirs() []string {
+	dirs := make([]string, 0, len(r.Projects))
+	for _, p := range r.Projects {
+		dirs = append(dirs, p.Dir)
+	}
+	return dirs
+}
+
+// owner returns the deepest of dirs that contains file; "." contains
+// every file
+func owner(file string, dirs []string) (string, bool) {
+	best, found := "", false
+	for _, dir := range dirs {
+		if dir != "." && file != dir && !strings.HasPrefix(file, dir+"/") {
+			continue
+		}
+		if !found || depth(dir) > depth(best) {
+			best, found = dir, true
+		}
+	}
+	return best, found
+}
+
+// depth counts the elements of a root-relative directory, 0 for the root
+func depth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
@@ -0,0 +1,53 @@
+package affected
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/arch"
+)
+
+func TestMatch(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"libs/core/go.mod":    "module example.com/core\n",
+		"services/api/go.mod": "module example.com/api\n\nrequire example.com/core v0.0.0\n",
+		"services/web/go.mod": "module example.com/web\n\nrequire example.com/api v0.0.0\n",
+		"tools/go.mod":        "module example.com/tools\n",
+	} {
+		writeFile(t, root, name, content)
+	}
+	dirs := []string{"libs/core", "services/api", "services/web", "tools", "tools/gen"}
+	deps := arch.LoadProjects(root, dirs)
+
+	got := Match([]string{"libs/core/core.go", "tools/gen/main.go", "README.md", "libs/core/go.mod", "libs/corex/x.go"}, dirs, deps)
+	want := Result{
+		Projects: []Project{
+			{Dir: "libs/core", Changed: []string{"libs/core/core.go", "libs/core/go.mod"}},
+			{Dir: "services/api", Via: []string{"libs/core"}},
+			{Dir: "services/web", Via: []string{"libs/core"}},
+			{Dir: "tools/gen", Changed: []string{"tools/gen/main.go"}},
+		},
+		Unowned: []string{"README.md", "libs/corex/x.go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Match() =\n%+v\nwant\n%+v", got, want)
+	}
+	if dirs := got.Dirs(); !reflect.DeepEqual(dirs, []string{"libs/core", "services/api", "services/web", "tools/gen"}) {
+		t.Errorf("Dirs() = %v", dirs)
+	}
+}
+
+func TestMatch_RootProject(t *testing.T) {
+	got := Match([]string{"main.go", "web/app.ts"}, []string{".", "web"}, nil)
+	want := Result{Projects: []Project{
+		{Dir: ".", Changed: []string{"main.go"}},
+		{Dir: "web", Changed: []string{"web/app.ts"}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Match() =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := Match(nil, []string{"."}, nil); len(got.Projects) != 0 {
+		t.Errorf("Match() without changes = %+v", got)
+	}
+}
@@ -0,0 +1,56 @@
+package affected
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ChangedFiles lists the files under dir that differ from the merge base
+// of base and HEAD: committed, staged and unstaged changes, deletions and
+// untracked files that are not ignored. Paths are relative to dir in
+// slash form. Only the local repository is read; nothing is fetched.
+func ChangedFiles(ctx context.Context, dir, base string) ([]string, error) {
+	mergeBase, err := git(ctx, dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the merge base of %s and HEAD: %w", base, err)
+	}
+
+	diff, err := git(ctx, dir, "diff", "--name-only", "--no-renames", "--relative", "-z", strings.TrimSpace(mergeBase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	untracked, err := git(ctx, dir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var files []string
+	for _, name := range strings.Split(diff+untracked, "\x00") {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// git runs a git command in dir and returns its standard output
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
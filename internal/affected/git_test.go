@@ -0,0 +1,76 @@
+package affected
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile creates a file relative to root
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// run runs git in dir with a fixed identity
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	run(t, root, "init", "-q", "-b", "main")
+	writeFile(t, root, "api/main.go", "package main\n")
+	writeFile(t, root, "web/app.ts", "export {}\n")
+	writeFile(t, root, "old.txt", "x\n")
+	writeFile(t, root, ".gitignore", "*.log\n")
+	run(t, root, "add", "-A")
+	run(t, root, "commit", "-q", "-m", "base")
+
+	run(t, root, "checkout", "-q", "-b", "feature")
+	writeFile(t, root, "api/main.go", "package main\n\nfunc main() {}\n")
+	run(t, root, "rm", "-q", "old.txt")
+	run(t, root, "commit", "-q", "-am", "change")
+	writeFile(t, root, "web/app.ts", "export const x = 1\n")
+	writeFile(t, root, "web/new file.ts", "export {}\n")
+	writeFile(t, root, "web/debug.log", "ignored\n")
+
+	files, err := ChangedFiles(context.Background(), root, "main")
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	want := []string{"api/main.go", "old.txt", "web/app.ts", "web/new file.ts"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ChangedFiles() = %q, want %q", files, want)
+	}
+
+	// From a subdirectory, paths are relative to it
+	files, err = ChangedFiles(context.Background(), filepath.Join(root, "web"), "main")
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	if want := []string{"app.ts", "new file.ts"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("ChangedFiles(web) = %q, want %q", files, want)
+	}
+
+	if _, err := ChangedFiles(context.Background(), root, "no-such-ref"); err == nil {
+		t.Error("ChangedFiles() with an unknown base should fail")
+	}
+}
@@ -0,0 +1,336 @@
+// Package agentignore finds the files of a repository that only waste an
+// agent's context — generated code, minified bundles, large binaries,
+// fixtures, snapshots and vendored trees — so that .agentignore can list
+// them with the reason each was added.
+package agentignore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/ignore"
+)
+
+// largeBinary is the size from which binary files are listed
+const largeBinary = 1 << 20
+
+// minifiedSize and minifiedLine are the size and average line length
+// from which a script or stylesheet counts as minified
+const (
+	minifiedSize = 8 << 10
+	minifiedLine = 500
+)
+
+// headerSize is how much of each file is read: generators put their
+// marker at the top, and binaries show a NUL byte early on
+const headerSize = 1024
+
+// Section titles, in the order they are written
+const (
+	Generated = "Generated code"
+	Minified  = "Minified bundles"
+	Binaries  = "Large binaries"
+	Fixtures  = "Test fixtures"
+	Snapshots = "Snapshots"
+	Vendored  = "Vendored code"
+)
+
+var sectionOrder = []string{Generated, Minified, Binaries, Fixtures, Snapshots, Vendored}
+
+// skipDirs are not scanned: the static part of .agentignore already
+// covers them
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "__pycache__": true, "venv": true,
+	"site-packages": true, "dist": true, "build": true, "target": true,
+	"bin": true, "out": true, "tmp": true, "temp": true, "logs": true,
+	"coverage": true, "htmlcov": true,
+}
+
+// dirKinds names the directories listed whole
+var dirKinds = map[string]string{
+	"fixtures": Fixtures, "__fixtures__": Fixtures, "test-fixtures": Fixtures,
+	"__snapshots__": Snapshots,
+	"third_party":   Vendored, "third-party": Vendored, "3rdparty": Vendored,
+}
+
+// suffixKinds are file name suffixes listed as a glob when any file has
+// them
+var suffixKinds = []struct {
+	suffix string
+	kind   string
+}{
+	{".pb.go", Generated},
+	{".pb.gw.go", Generated},
+	{"_pb2.py", Generated},
+	{"_pb2_grpc.py", Generated},
+	{"_pb2.pyi", Generated},
+	{"_pb.js", Generated},
+	{"_pb.d.ts", Generated},
+	{".g.dart", Generated},
+	{".min.js", Minified},
+	{".min.css", Minified},
+	{".snap", Snapshots},
+}
+
+// generatedMarker matches the comment that generators put at the top of
+// their output, e.g. "// Code generated by protoc-gen-go. DO NOT EDIT."
+var generatedMarker = regexp.MustCompile(`(?i)code generated .*do not edit|generated by .*do not edit|@generated|<auto-generated|this file (?:is|was) (?:automatically |auto-)generated`)
+
+// minifiable are the extensions checked for minified content
+var minifiable = map[string]bool{".js": true, ".mjs": true, ".cjs": true, ".css": true}
+
+// Entry is a pattern for .agentignore and why it was added
+type Entry struct {
+	// Pattern is anchored to the repository root unless it is a glob
+	// that applies anywhere
+	Pattern string
+	Reason  string
+}
+
+// Section groups the entries of one kind
+type Section struct {
+	Title   string
+	Entries []Entry
+}
+
+// Scan walks the repository at root, honouring .gitignore but not an
+// existing .agentignore, and returns the non-empty sections in a fixed
+// order with entries sorted by pattern
+func Scan(root string) ([]Section, error) {
+	s := &scanner{
+		root:      root,
+		entries:   map[string][]Entry{},
+		suffixes:  map[string][]string{},
+		files:     map[string]int{},
+		generated: map[string]int{},
+		markers:   map[string]string{},
+		mixed:     map[string]bool{},
+		head:      make([]byte, headerSize),
+	}
+	err := ignore.WalkWith(root, "", []string{ignore.GitIgnoreFile}, s.visit)
+	if err != nil {
+		return nil, err
+	}
+	return s.sections(), nil
+}
+
+// scanner collects findings during a walk
+type scanner struct {
+	root    string
+	entries map[string][]Entry
+	// suffixes maps each suffix rule to the files that matched it
+	suffixes map[string][]string
+	// files and generated count the files below each directory, and the
+	// generated ones among them
+	files     map[string]int
+	generated map[string]int
+	// markers maps generated files to the marker found in them
+	markers map[string]string
+	// mixed marks the directories holding something that is not counted
+	// in files: skipped directories, dot-files or suffix rule matches
+	mixed map[string]bool
+	// head is the buffer files are read into
+	head []byte
+}
+
+// visit classifies one walked entry
+func (s *scanner) visit(rel string, d fs.DirEntry) error {
+	name := d.Name()
+	if d.IsDir() {
+		if strings.HasPrefix(name, ".") || skipDirs[name] {
+			s.markMixed(rel)
+			return fs.SkipDir
+		}
+		if kind, ok := dirKinds[name]; ok {
+			s.markMixed(rel)
+			s.add(kind, Entry{Pattern: "/" + rel + "/", Reason: dirReason(kind, name)})
+			return fs.SkipDir
+		}
+		return nil
+	}
+	if strings.HasPrefix(name, ".") || !d.Type().IsRegular() {
+		s.markMixed(rel)
+		return nil
+	}
+
+	for _, rule := range suffixKinds {
+		if strings.HasSuffix(name, rule.suffix) {
+			s.markMixed(rel)
+			s.suffixes[rule.suffix] = append(s.suffixes[rule.suffix], rel)
+			return nil
+		}
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		s.markMixed(rel)
+		return nil
+	}
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		s.files[dir]++
+	}
+
+	f, err := os.Open(filepath.Join(s.root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	n, _ := io.ReadFull(f, s.head)
+	head := s.head[:n]
+
+	mark := marker(head)
+	switch {
+	case bytes.IndexByte(head, 0) >= 0:
+		if info.Size() >= largeBinary {
+			s.add(Binaries, Entry{Pattern: "/" + rel, Reason: fmt.Sprintf("binary file of %.1f MiB", float64(info.Size())/(1<<20))})
+		}
+	case mark != "":
+		s.markers[rel] = mark
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			s.generated[dir]++
+		}
+	case minifiable[path.Ext(name)] && info.Size() >= minifiedSize:
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil
+		}
+		if lines := countLines(f); info.Size()/int64(lines) >= minifiedLine {
+			s.add(Minified, Entry{Pattern: "/" + rel, Reason: fmt.Sprintf("minified: %d KiB on %d %s", info.Size()>>10, lines, plural(lines, "line", "lines"))})
+		}
+	}
+	return nil
+}
+
+// markMixed marks the directories above rel as holding an entry that
+// is not counted, so that they are never listed whole
+func (s *scanner) markMixed(rel string) {
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		s.mixed[dir] = true
+	}
+}
+
+// add records an entry of a kind
+func (s *scanner) add(kind string, e Entry) {
+	s.entries[kind] = append(s.entries[kind], e)
+}
+
+// sections turns the findings into entries: suffix rules become globs,
+// and generated files are listed by the highest directory holding
+// nothing else, skipped entries included
+func (s *scanner) sections() []Section {
+	for _, rule := range suffixKinds {
+		files := s.suffixes[rule.suffix]
+		if len(files) == 0 {
+			continue
+		}
+		reason := fmt.Sprintf("%d %s, e.g. %s", len(files), plural(len(files), "file", "files"), files[0])
+		s.add(rule.kind, Entry{Pattern: "*" + rule.suffix, Reason: reason})
+	}
+
+	listed := map[string]bool{}
+	for _, rel := range sortedKeys(s.markers) {
+		target := rel
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			if s.generated[dir] == s.files[dir] && s.files[dir] > 1 && !s.mixed[dir] {
+				target = dir
+			}
+		}
+		if listed[target] {
+			continue
+		}
+		listed[target] = true
+		if target == rel {
+			s.add(Generated, Entry{Pattern: "/" + rel, Reason: fmt.Sprintf("marked %q", s.markers[rel])})
+			continue
+		}
+		n := s.files[target]
+		s.add(Generated, Entry{Pattern: "/" + target + "/", Reason: fmt.Sprintf("all %d files marked like %q", n, s.markers[rel])})
+	}
+
+	var sections []Section
+	for _, title := range sectionOrder {
+		entries := s.entries[title]
+		if len(entries) == 0 {
+			continue
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Pattern < entries[j].Pattern })
+		sections = append(sections, Section{Title: title, Entries: entries})
+	}
+	return sections
+}
+
+// commentPrefixes start the comment lines searched for a marker
+var commentPrefixes = []string{"//", "#", "/*", "*", "<!--", "--", ";"}
+
+// marker returns the generated marker comment in the head of a file, if
+// any, without its comment delimiters
+func marker(head []byte) string {
+	if len(head) > headerSize {
+		head = head[:headerSize]
+	}
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		comment := false
+		for _, p := range commentPrefixes {
+			if strings.HasPrefix(line, p) {
+				comment = true
+				break
+			}
+		}
+		if comment && generatedMarker.MatchString(line) {
+			line = strings.TrimSpace(strings.TrimLeft(line, "/#*-<!;"))
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(line, "-->"), "*/"))
+		}
+	}
+	return ""
+}
+
+// dirReason explains why a directory of a kind was listed
+func dirReason(kind, name string) string {
+	switch kind {
+	case Fixtures:
+		return "test data, read only when a test needs it"
+	case Snapshots:
+		return "recorded test output"
+	default:
+		return fmt.Sprintf("third-party code in %s/", name)
+	}
+}
+
+// countLines counts the lines of r, at least one
+func countLines(r io.Reader) int {
+	buf := make([]byte, 32<<10)
+	n := 1
+	for {
+		c, err := r.Read(buf)
+		n += bytes.Count(buf[:c], []byte("\n"))
+		if err != nil {
+			return n
+		}
+	}
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,161 @@
+package agentignore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files relative to root
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	minified := strings.Repeat("var a=1;", 2000)
+	writeTree(t, root, map[string]string{
+		".gitignore":                  "ignored/\n",
+		".agentignore":                "gen/\n",
+		"main.go":                     "package main\n",
+		"api/order.pb.go":             "package api\n",
+		"api/order_grpc.pb.go":        "package api\n",
+		"py/order_pb2.py":             "# proto\n",
+		"gen/a.go":                    "// Code generated by stringer. DO NOT EDIT.\n\npackage gen\n",
+		"gen/sub/b.go":                "// Code generated by stringer. DO NOT EDIT.\n\npackage sub\n",
+		"internal/mixed.go":           "// Code generated by go-bindata. DO NOT EDIT.\npackage internal\n",
+		"internal/real.go":            "package internal\n",
+		"web/app.min.js":              "x",
+		"web/bundle.js":               minified,
+		"web/source.js":               strings.Repeat("const a = 1;\n", 1000),
+		"web/__snapshots__/a.js.snap": "exports[`a`] = `x`;\n",
+		"web/Button.test.js.snap":     "",
+		"assets/video.bin":            "\x00" + strings.Repeat("x", largeBinary),
+		"assets/icon.png":             "\x89PNG\x00",
+		"tests/fixtures/a.json":       "{}",
+		"third_party/lib/lib.c":       "int x;\n",
+		"ignored/huge.bin":            "\x00" + strings.Repeat("x", largeBinary),
+		"node_modules/x/index.min.js": "x",
+		".cache/blob":                 "\x00" + strings.Repeat("x", largeBinary),
+	})
+
+	sections, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	got := map[string][]string{}
+	var titles []string
+	for _, s := range sections {
+		titles = append(titles, s.Title)
+		for _, e := range s.Entries {
+			got[s.Title] = append(got[s.Title], e.Pattern)
+		}
+	}
+
+	if want := []string{Generated, Minified, Binaries, Fixtures, Snapshots, Vendored}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("titles = %v, want %v", titles, want)
+	}
+	want := map[string][]string{
+		Generated: {"*.pb.go", "*_pb2.py", "/gen/", "/internal/mixed.go"},
+		Minified:  {"*.min.js", "/web/bundle.js"},
+		Binaries:  {"/assets/video.bin"},
+		Fixtures:  {"/tests/fixtures/"},
+		Snapshots: {"*.snap", "/web/__snapshots__/"},
+		Vendored:  {"/third_party/"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("patterns =\n%v\nwant\n%v", got, want)
+	}
+
+	reasons := map[string]string{}
+	for _, s := range sections {
+		for _, e := range s.Entries {
+			reasons[e.Pattern] = e.Reason
+		}
+	}
+	for pattern, want := range map[string]string{
+		"*.pb.go":            "2 files, e.g. api/order.pb.go",
+		"/gen/":              `all 2 files marked like "Code generated by stringer. DO NOT EDIT."`,
+		"/internal/mixed.go": `marked "Code generated by go-bindata. DO NOT EDIT."`,
+		"/web/bundle.js":     "minified: 15 KiB on 1 line",
+		"/assets/video.bin":  "binary file of 1.0 MiB",
+		"/third_party/":      "third-party code in third_party/",
+	} {
+		if reasons[pattern] != want {
+			t.Errorf("reason for %s = %q, want %q", pattern, reasons[pattern], want)
+		}
+	}
+}
+
+func TestScan_MixedDirectories(t *testing.T) {
+	root := t.TempDir()
+	gen := "// Code generated by stringer. DO NOT EDIT.\n\npackage gen\n"
+	writeTree(t, root, map[string]string{
+		"pkg/build/build.go":    "package build\n",
+		"pkg/gen/a.go":          gen,
+		"pkg/gen/b.go":          gen,
+		"web/fixtures/a.json":   "{}",
+		"web/gen/a.go":          gen,
+		"web/gen/b.go":          gen,
+		"proto/gen/a.go":        gen,
+		"proto/gen/b.go":        gen,
+		"proto/gen/order.pb.go": "package gen\n",
+	})
+
+	sections, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var got []string
+	for _, s := range sections {
+		if s.Title == Generated {
+			for _, e := range s.Entries {
+				got = append(got, e.Pattern)
+			}
+		}
+	}
+	// Directories holding skipped or globbed entries are never listed whole
+	want := []string{"*.pb.go", "/pkg/gen/", "/proto/gen/a.go", "/proto/gen/b.go", "/web/gen/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("generated patterns = %v, want %v", got, want)
+	}
+}
+
+func TestScan_Clean(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"main.go": "package main\n"})
+	sections, err := Scan(root)
+	if err != nil || len(sections) != 0 {
+		t.Errorf("Scan() = %v, %v; want no sections", sections, err)
+	}
+}
+
+func TestMarker(t *testing.T) {
+	tests := []struct {
+		head string
+		want string
+	}{
+		{"// Code generated by protoc-gen-go. DO NOT EDIT.\npackage v1\n", "Code generated by protoc-gen-go. DO NOT EDIT."},
+		{"# -*- coding: utf-8 -*-\n# Generated by the protocol buffer compiler.  DO NOT EDIT!\n", "Generated by the protocol buffer compiler.  DO NOT EDIT!"},
+		{"/**\n * @generated\n */\n", "@generated"},
+		{"// <auto-generated>\n", "<auto-generated>"},
+		{"<!-- This file was automatically generated -->\n", "This file was automatically generated"},
+		{"package main\n\nvar re = \"@generated\"\n", ""},
+		{"// Package gen generates code\n", ""},
+		{strings.Repeat("\n", headerSize) + "// Code generated by x. DO NOT EDIT.\n", ""},
+	}
+	for _, tt := range tests {
+		if got := marker([]byte(tt.head)); got != tt.want {
+			t.Errorf("marker(%q) = %q, want %q", tt.head, got, tt.want)
+		}
+	}
+}
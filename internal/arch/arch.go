@@ -0,0 +1,613 @@
+// Package arch derives a repository's architecture from its source code:
+// the components, how they import each other, their entry points and the
+// layers the import graph forms.
+package arch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxDiagramNodes bounds the Mermaid diagram; larger graphs are collapsed
+// to their top-level directories
+const maxDiagramNodes = 20
+
+// maxDiagramEdges bounds the arrows drawn in the Mermaid diagram
+const maxDiagramEdges = 24
+
+// maxFlowSteps bounds the components listed for one data flow
+const maxFlowSteps = 10
+
+// maxFlows bounds the data flows listed
+const maxFlows = 3
+
+// maxEntries bounds the entry points listed
+const maxEntries = 5
+
+// maxTableRows bounds the components table; the most imported components
+// are kept
+const maxTableRows = 15
+
+// maxLayers and maxLayerDirs bound the layers listed and the components
+// listed for each
+const (
+	maxLayers    = 8
+	maxLayerDirs = 10
+)
+
+// maxDocLength bounds the purpose of a component in the table, in
+// characters
+const maxDocLength = 80
+
+// skipDirs are directories that hold no first-party sources
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "testdata": true, "__pycache__": true,
+	"venv": true, "site-packages": true, "dist": true, "build": true,
+	"coverage": true, "out": true,
+}
+
+// units names a language's components, singular and plural
+var units = map[string][2]string{
+	"Go":     {"package", "packages"},
+	"Python": {"package", "packages"},
+}
+
+// Component is a unit of the import graph: a Go package, a Python
+// package or a TypeScript module directory
+type Component struct {
+	// Name is the component's own name, e.g. the Go package name
+	Name string
+	// Dir is the root-relative directory in slash form, "." for the root
+	Dir string
+	// Doc is the first sentence of the component's documentation
+	Doc string
+	// Imports are the Dirs of the components this one imports, sorted
+	Imports []string
+	// Interfaces are the exported interfaces the component declares
+	Interfaces []string
+	// Entry marks entry points such as Go main packages or Python
+	// __main__ modules
+	Entry bool
+	// Layer is 0 for components that import no other component and one
+	// more than the highest layer imported otherwise
+	Layer int
+}
+
+// Graph is the import graph of a repository
+type Graph struct {
+	// Language names the source language, e.g. "Go"
+	Language string
+	// Module is the module or package root name, e.g. a Go module path
+	Module string
+	// Components are sorted by Dir
+	Components []Component
+}
+
+// Layer lists the components of one layer
+type Layer struct {
+	Level int
+	Dirs  []string
+	// More counts the components left out beyond maxLayerDirs
+	More int
+}
+
+// Table lists the components worth describing one by one
+type Table struct {
+	Rows []Component
+	// More counts the components left out
+	More int
+}
+
+// Flow lists the components an entry point reaches, outermost layer first
+type Flow struct {
+	Entry string
+	Steps []string
+	// More counts the steps left out beyond maxFlowSteps
+	More int
+}
+
+// newGraph sorts the components, drops imports of unknown components and
+// computes the layers
+func newGraph(language, module string, components []Component) *Graph {
+	sort.Slice(components, func(i, j int) bool { return components[i].Dir < components[j].Dir })
+
+	known := map[string]bool{}
+	for _, c := range components {
+		known[c.Dir] = true
+	}
+	for i := range components {
+		var imports []string
+		for _, imp := range components[i].Imports {
+			if known[imp] && imp != components[i].Dir && !slices.Contains(imports, imp) {
+				imports = append(imports, imp)
+			}
+		}
+		sort.Strings(imports)
+		components[i].Imports = imports
+	}
+
+	g := &Graph{Language: language, Module: module, Components: components}
+	g.computeLayers()
+	return g
+}
+
+// computeLayers assigns each component its layer; import cycles are
+// broken at the import that closes them
+func (g *Graph) computeLayers() {
+	index := map[string]int{}
+	for i, c := range g.Components {
+		index[c.Dir] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(g.Components))
+	var visit func(i int) int
+	visit = func(i int) int {
+		if state[i] != unvisited {
+			return g.Components[i].Layer
+		}
+		state[i] = visiting
+		layer := 0
+		for _, imp := range g.Components[i].Imports {
+			if j := index[imp]; state[j] != visiting {
+				layer = max(layer, visit(j)+1)
+			}
+		}
+		g.Components[i].Layer = layer
+		state[i] = done
+		return layer
+	}
+	for i := range g.Components {
+		visit(i)
+	}
+}
+
+// Entries returns the entry point components
+func (g *Graph) Entries() []Component {
+	var entries []Component
+	for _, c := range g.Components {
+		if c.Entry {
+			entries = append(entries, c)
+		}
+	}
+	return entries
+}
+
+// EntryPoints returns the first maxEntries entry points with their
+// purpose shortened to maxDocLength
+func (g *Graph) EntryPoints() Table {
+	var table Table
+	for _, c := range g.Entries() {
+		if len(table.Rows) == maxEntries {
+			table.More++
+			continue
+		}
+		c.Doc = shorten(c.Doc, maxDocLength)
+		table.Rows = append(table.Rows, c)
+	}
+	return table
+}
+
+// Summary describes the graph in one sentence, e.g. "Go module
+// `example.com/app` with 3 packages."
+func (g *Graph) Summary() string {
+	kind := "project"
+	if g.Language == "Go" {
+		kind = "module"
+	}
+	unit, ok := units[g.Language]
+	if !ok {
+		unit = [2]string{"directory", "directories"}
+	}
+	name := unit[1]
+	if len(g.Components) == 1 {
+		name = unit[0]
+	}
+	return fmt.Sprintf("%s %s `%s` with %d %s.", g.Language, kind, g.Module, len(g.Components), name)
+}
+
+// Cycles returns the import cycles, each as the sorted Dirs of the
+// components that import each other
+func (g *Graph) Cycles() [][]string {
+	index := map[string]int{}
+	for i, c := range g.Components {
+		index[c.Dir] = i
+	}
+
+	// Tarjan's strongly connected components
+	var (
+		cycles  [][]string
+		stack   []int
+		counter int
+	)
+	order := make([]int, len(g.Components))
+	low := make([]int, len(g.Components))
+	onStack := make([]bool, len(g.Components))
+	var visit func(i int)
+	visit = func(i int) {
+		counter++
+		order[i], low[i] = counter, counter
+		stack = append(stack, i)
+		onStack[i] = true
+		for _, imp := range g.Components[i].Imports {
+			j := index[imp]
+			if order[j] == 0 {
+				visit(j)
+				low[i] = min(low[i], low[j])
+			} else if onStack[j] {
+				low[i] = min(low[i], order[j])
+			}
+		}
+		if low[i] != order[i] {
+			return
+		}
+		var scc []string
+		for {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[j] = false
+			scc = append(scc, g.Components[j].Dir)
+			if j == i {
+				break
+			}
+		}
+		// Self imports are dropped by newGraph, so single components are
+		// never cycles
+		if len(scc) > 1 {
+			sort.Strings(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for i := range g.Components {
+		if order[i] == 0 {
+			visit(i)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// Flows returns, for the first maxFlows entry points, the components each
+// reaches directly or transitively, ordered from the outermost layer
+// inwards
+func (g *Graph) Flows() []Flow {
+	flows := g.allFlows()
+	if len(flows) > maxFlows {
+		flows = flows[:maxFlows]
+	}
+	return flows
+}
+
+// MoreFlows counts the data flows left out beyond maxFlows
+func (g *Graph) MoreFlows() int {
+	return max(len(g.allFlows())-maxFlows, 0)
+}
+
+// allFlows returns the data flows of every entry point
+func (g *Graph) allFlows() []Flow {
+	index := map[string]int{}
+	for i, c := range g.Components {
+		index[c.Dir] = i
+	}
+
+	var flows []Flow
+	for _, entry := range g.Entries() {
+		seen := map[string]bool{entry.Dir: true}
+		var reached []Component
+		queue := entry.Imports
+		for len(queue) > 0 {
+			dir := queue[0]
+			queue = queue[1:]
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			c := g.Components[index[dir]]
+			reached = append(reached, c)
+			queue = append(queue, c.Imports...)
+		}
+		if len(reached) == 0 {
+			continue
+		}
+		sort.Slice(reached, func(i, j int) bool {
+			if reached[i].Layer != reached[j].Layer {
+				return reached[i].Layer > reached[j].Layer
+			}
+			return reached[i].Dir < reached[j].Dir
+		})
+
+		flow := Flow{Entry: entry.Dir}
+		for _, c := range reached {
+			if len(flow.Steps) == maxFlowSteps {
+				flow.More++
+				continue
+			}
+			flow.Steps = append(flow.Steps, c.Dir)
+		}
+		flows = append(flows, flow)
+	}
+	return flows
+}
+
+// Component returns the component at dir, or nil
+func (g *Graph) Component(dir string) *Component {
+	for i := range g.Components {
+		if g.Components[i].Dir == dir {
+			return &g.Components[i]
+		}
+	}
+	return nil
+}
+
+// Edges counts the imports between components
+func (g *Graph) Edges() int {
+	n := 0
+	for _, c := range g.Components {
+		n += len(c.Imports)
+	}
+	return n
+}
+
+// Dependents returns the Dirs of the components that import dir directly
+func (g *Graph) Dependents(dir string) []string {
+	var dirs []string
+	for _, c := range g.Components {
+		if slices.Contains(c.Imports, dir) {
+			dirs = append(dirs, c.Dir)
+		}
+	}
+	return dirs
+}
+
+// Impact returns the Dirs of the components that import dir directly or
+// transitively, sorted: everything a change to dir can break
+func (g *Graph) Impact(dir string) []string {
+	seen := map[string]bool{dir: true}
+	var dirs []string
+	queue := []string{dir}
+	for len(queue) > 0 {
+		for _, d := range g.Dependents(queue[0]) {
+			if !seen[d] {
+				seen[d] = true
+				dirs = append(dirs, d)
+				queue = append(queue, d)
+			}
+		}
+		queue = queue[1:]
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// Layers groups the component directories by layer, lowest first, up to
+// maxLayers
+func (g *Graph) Layers() []Layer {
+	layers := g.allLayers()
+	if len(layers) > maxLayers {
+		layers = layers[:maxLayers]
+	}
+	return layers
+}
+
+// MoreLayers counts the layers left out beyond maxLayers
+func (g *Graph) MoreLayers() int {
+	return max(len(g.allLayers())-maxLayers, 0)
+}
+
+// allLayers groups the component directories of every layer
+func (g *Graph) allLayers() []Layer {
+	var layers []Layer
+	for _, c := range g.Components {
+		for len(layers) <= c.Layer {
+			layers = append(layers, Layer{Level: len(layers)})
+		}
+		if layer := &layers[c.Layer]; len(layer.Dirs) < maxLayerDirs {
+			layer.Dirs = append(layer.Dirs, c.Dir)
+		} else {
+			layer.More++
+		}
+	}
+	return layers
+}
+
+// Table returns the maxTableRows components with the most dependents,
+// sorted by Dir, with their purpose shortened to maxDocLength
+func (g *Graph) Table() Table {
+	rows := slices.Clone(g.Components)
+	var table Table
+	if len(rows) > maxTableRows {
+		dependents := map[string]int{}
+		for _, c := range rows {
+			for _, imp := range c.Imports {
+				dependents[imp]++
+			}
+		}
+		sort.SliceStable(rows, func(i, j int) bool { return dependents[rows[i].Dir] > dependents[rows[j].Dir] })
+		table.More = len(rows) - maxTableRows
+		rows = rows[:maxTableRows]
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Dir < rows[j].Dir })
+	}
+	for i := range rows {
+		rows[i].Doc = shorten(rows[i].Doc, maxDocLength)
+	}
+	table.Rows = rows
+	return table
+}
+
+// shorten cuts s at the last word boundary within n characters and marks
+// the cut with an ellipsis
+func shorten(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	cut := string(runes[:n])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:—-") + "…"
+}
+
+// Mermaid renders the import graph as a Mermaid flowchart, edges pointing
+// from importer to imported. Graphs with more than maxDiagramNodes
+// components are collapsed to their first two directory levels, or their
+// first if that is still too many, and only the first maxDiagramEdges
+// edges are drawn.
+func (g *Graph) Mermaid() string {
+	depth := 0
+	if len(g.Components) > maxDiagramNodes {
+		depth = 2
+		if len(g.nodes(depth)) > maxDiagramNodes {
+			depth = 1
+		}
+	}
+
+	nodes := g.nodes(depth)
+	edges := map[[2]string]bool{}
+	for _, c := range g.Components {
+		from := collapse(c.Dir, depth)
+		for _, imp := range c.Imports {
+			if to := collapse(imp, depth); to != from {
+				edges[[2]string{from, to}] = true
+			}
+		}
+	}
+	sorted := make([][2]string, 0, len(edges))
+	for e := range edges {
+		sorted = append(sorted, e)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+
+	more := 0
+	if len(sorted) > maxDiagramEdges {
+		more = len(sorted) - maxDiagramEdges
+		sorted = sorted[:maxDiagramEdges]
+	}
+	// Too many nodes even collapsed: only those with an arrow are drawn
+	if len(nodes) > maxDiagramNodes {
+		drawn := map[string]bool{}
+		for _, e := range sorted {
+			drawn[e[0]], drawn[e[1]] = true, true
+		}
+		nodes = slices.DeleteFunc(nodes, func(n string) bool { return !drawn[n] })
+	}
+
+	var b strings.Builder
+	b.WriteString("graph TD\n")
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", nodeID(n), g.label(n))
+	}
+	for _, e := range sorted {
+		fmt.Fprintf(&b, "    %s --> %s\n", nodeID(e[0]), nodeID(e[1]))
+	}
+	if more > 0 {
+		fmt.Fprintf(&b, "    %%%% … %d more imports\n", more)
+	}
+	return b.String()
+}
+
+// nodes returns the sorted diagram nodes with directories collapsed to
+// depth
+func (g *Graph) nodes(depth int) []string {
+	var nodes []string
+	seen := map[string]bool{}
+	for _, c := range g.Components {
+		if n := collapse(c.Dir, depth); !seen[n] {
+			seen[n] = true
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// label names a diagram node; the root directory is named after the module
+func (g *Graph) label(dir string) string {
+	if dir == "." && g.Module != "" {
+		return g.Module[strings.LastIndex(g.Module, "/")+1:]
+	}
+	return dir
+}
+
+// collapse shortens dir to its first depth elements; 0 keeps it whole
+func collapse(dir string, depth int) string {
+	if depth == 0 {
+		return dir
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// nodeID turns a directory into a Mermaid node identifier
+func nodeID(dir string) string {
+	if dir == "." {
+		return "root"
+	}
+	id := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, dir)
+	return "n_" + id
+}
+
+// walkSources calls fn with the root-relative slash path and content of
+// every file under root with one of exts. Hidden directories, virtual
+// environments and dependency or build output are skipped.
+func walkSources(root string, exts []string, fn func(rel string, content []byte)) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			name := d.Name()
+			if skipDirs[name] || strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "pyvenv.cfg")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(exts, filepath.Ext(path)) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		fn(filepath.ToSlash(rel), content)
+		return nil
+	})
+}
+
+// firstSentence returns the first sentence of a doc comment on one line,
+// with pipes escaped for Markdown tables
+func firstSentence(doc string) string {
+	doc = strings.ReplaceAll(strings.Join(strings.Fields(doc), " "), "|", `\|`)
+	if i := strings.Index(doc, ". "); i >= 0 {
+		return doc[:i+1]
+	}
+	return doc
+}
@@ -0,0 +1,280 @@
+package arch
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNewGraph_Layers(t *testing.T) {
+	g := newGraph("Go", "example.com/app", []Component{
+		{Dir: "internal/store", Imports: []string{"internal/model", "internal/model", "github.com/lib/pq"}},
+		{Dir: "cmd/app", Imports: []string{"internal/api", "internal/store"}, Entry: true},
+		{Dir: "internal/model", Imports: []string{"internal/model"}},
+		{Dir: "internal/api", Imports: []string{"internal/store", "internal/model"}},
+	})
+
+	var dirs []string
+	for _, c := range g.Components {
+		dirs = append(dirs, c.Dir)
+	}
+	if want := []string{"cmd/app", "internal/api", "internal/model", "internal/store"}; !reflect.DeepEqual(dirs, want) {
+		t.Errorf("components = %v, want sorted %v", dirs, want)
+	}
+	if imports := g.Components[3].Imports; !reflect.DeepEqual(imports, []string{"internal/model"}) {
+		t.Errorf("store imports = %v, want only known components, once", imports)
+	}
+
+	want := []Layer{
+		{Level: 0, Dirs: []string{"internal/model"}},
+		{Level: 1, Dirs: []string{"internal/store"}},
+		{Level: 2, Dirs: []string{"internal/api"}},
+		{Level: 3, Dirs: []string{"cmd/app"}},
+	}
+	if got := g.Layers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Layers() = %+v, want %+v", got, want)
+	}
+	if entries := g.Entries(); len(entries) != 1 || entries[0].Dir != "cmd/app" {
+		t.Errorf("Entries() = %+v", entries)
+	}
+}
+
+func TestNewGraph_Cycle(t *testing.T) {
+	g := newGraph("Python", "app", []Component{
+		{Dir: "a", Imports: []string{"b"}},
+		{Dir: "b", Imports: []string{"c"}},
+		{Dir: "c", Imports: []string{"a"}},
+	})
+	for _, c := range g.Components {
+		if c.Layer < 0 || c.Layer > 2 {
+			t.Errorf("%s layer = %d, want a layer within the cycle length", c.Dir, c.Layer)
+		}
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	g := newGraph("Go", "example.com/app", []Component{
+		{Dir: ".", Imports: []string{"internal/api-v2"}},
+		{Dir: "internal/api-v2"},
+	})
+	want := "graph TD\n" +
+		"    root[\"app\"]\n" +
+		"    n_internal_api_v2[\"internal/api-v2\"]\n" +
+		"    root --> n_internal_api_v2\n"
+	if got := g.Mermaid(); got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMermaid_CollapsesLargeGraphs(t *testing.T) {
+	var components []Component
+	for i := 0; i <= maxDiagramNodes; i++ {
+		dir := "internal/store/" + strings.Repeat("x", i+1)
+		components = append(components, Component{Dir: dir})
+	}
+	components = append(components, Component{Dir: "cmd/app", Imports: []string{"internal/store/x", "internal/store/xx"}})
+
+	got := newGraph("Go", "example.com/app", components).Mermaid()
+	want := "graph TD\n" +
+		"    n_cmd_app[\"cmd/app\"]\n" +
+		"    n_internal_store[\"internal/store\"]\n" +
+		"    n_cmd_app --> n_internal_store\n"
+	if got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFirstSentence(t *testing.T) {
+	tests := []struct {
+		doc      string
+		expected string
+	}{
+		{"Parses input.\nMore details follow. And more.", "Parses input."},
+		{"Handles a|b\n  split lines", `Handles a\|b split lines`},
+		{"Version 1.2 is supported", "Version 1.2 is supported"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := firstSentence(tt.doc); got != tt.expected {
+			t.Errorf("firstSentence(%q) = %q, want %q", tt.doc, got, tt.expected)
+		}
+	}
+}
+
+func TestCycles(t *testing.T) {
+	g := newGraph("Python", "app", []Component{
+		{Dir: "a", Imports: []string{"b"}},
+		{Dir: "b", Imports: []string{"c", "a"}},
+		{Dir: "c", Imports: []string{"b"}},
+		{Dir: "d", Imports: []string{"a", "d"}},
+		{Dir: "e", Imports: []string{"f"}},
+		{Dir: "f", Imports: []string{"e"}},
+	})
+	want := [][]string{{"a", "b", "c"}, {"e", "f"}}
+	if got := g.Cycles(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Cycles() = %v, want %v", got, want)
+	}
+	if got := newGraph("Go", "m", []Component{{Dir: "a", Imports: []string{"b"}}, {Dir: "b"}}).Cycles(); got != nil {
+		t.Errorf("Cycles() of an acyclic graph = %v", got)
+	}
+}
+
+func TestFlows(t *testing.T) {
+	g := newGraph("Go", "example.com/app", []Component{
+		{Dir: "cmd/app", Imports: []string{"internal/api"}, Entry: true},
+		{Dir: "cmd/tool", Entry: true},
+		{Dir: "internal/api", Imports: []string{"internal/store", "internal/model"}},
+		{Dir: "internal/model"},
+		{Dir: "internal/store", Imports: []string{"internal/model"}},
+		{Dir: "internal/unused"},
+	})
+	want := []Flow{{Entry: "cmd/app", Steps: []string{"internal/api", "internal/store", "internal/model"}}}
+	if got := g.Flows(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flows() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFlows_Truncated(t *testing.T) {
+	entry := Component{Dir: "main", Entry: true}
+	components := []Component{}
+	for i := 0; i < maxFlowSteps+3; i++ {
+		dir := "pkg" + strings.Repeat("x", i)
+		entry.Imports = append(entry.Imports, dir)
+		components = append(components, Component{Dir: dir})
+	}
+	flows := newGraph("Go", "m", append(components, entry)).Flows()
+	if len(flows) != 1 || len(flows[0].Steps) != maxFlowSteps || flows[0].More != 3 {
+		t.Errorf("Flows() = %+v, want %d steps and 3 more", flows, maxFlowSteps)
+	}
+}
+
+func TestFlows_ManyEntries(t *testing.T) {
+	var components []Component
+	for i := 0; i < maxFlows+2; i++ {
+		dir := "cmd/tool" + strings.Repeat("x", i)
+		components = append(components, Component{Dir: dir, Imports: []string{"lib"}, Entry: true})
+	}
+	g := newGraph("Go", "m", append(components, Component{Dir: "lib"}))
+	if flows := g.Flows(); len(flows) != maxFlows || g.MoreFlows() != 2 {
+		t.Errorf("Flows() = %d flows and %d more, want %d and 2", len(flows), g.MoreFlows(), maxFlows)
+	}
+}
+
+func TestTable(t *testing.T) {
+	var components []Component
+	for i := 0; i < maxTableRows+2; i++ {
+		components = append(components, Component{Dir: "pkg/" + strings.Repeat("x", i+1)})
+	}
+	// The last pkg/ component would be left out by Dir, but it is the
+	// only one imported, so it is kept
+	components = append(components, Component{
+		Dir:     "cmd/app",
+		Imports: []string{"pkg/" + strings.Repeat("x", maxTableRows+2)},
+		Doc:     strings.Repeat("Handles requests and more ", 10),
+	})
+	table := newGraph("Go", "m", components).Table()
+
+	if len(table.Rows) != maxTableRows || table.More != 3 {
+		t.Fatalf("Table() = %d rows and %d more, want %d and 3", len(table.Rows), table.More, maxTableRows)
+	}
+	var dirs []string
+	for _, c := range table.Rows {
+		dirs = append(dirs, c.Dir)
+	}
+	if !sort.StringsAreSorted(dirs) {
+		t.Errorf("rows = %v, want sorted by Dir", dirs)
+	}
+	if !slices.Contains(dirs, "pkg/"+strings.Repeat("x", maxTableRows+2)) {
+		t.Errorf("rows = %v, want the imported component kept", dirs)
+	}
+	for _, c := range table.Rows {
+		if c.Dir == "cmd/app" && (len([]rune(c.Doc)) > maxDocLength+1 || !strings.HasSuffix(c.Doc, "…")) {
+			t.Errorf("doc = %q, want it shortened to %d characters", c.Doc, maxDocLength)
+		}
+	}
+}
+
+func TestLayers_Truncated(t *testing.T) {
+	var components []Component
+	for i := 0; i < maxLayerDirs+4; i++ {
+		components = append(components, Component{Dir: "pkg" + strings.Repeat("x", i)})
+	}
+	layers := newGraph("Go", "m", components).Layers()
+	if len(layers) != 1 || len(layers[0].Dirs) != maxLayerDirs || layers[0].More != 4 {
+		t.Errorf("Layers() = %+v, want %d dirs and 4 more", layers, maxLayerDirs)
+	}
+
+	// A chain of imports makes one layer per component
+	components = nil
+	for i := 0; i < maxLayers+3; i++ {
+		c := Component{Dir: fmt.Sprintf("pkg%02d", i)}
+		if i > 0 {
+			c.Imports = []string{fmt.Sprintf("pkg%02d", i-1)}
+		}
+		components = append(components, c)
+	}
+	g := newGraph("Go", "m", components)
+	if len(g.Layers()) != maxLayers || g.MoreLayers() != 3 {
+		t.Errorf("Layers() = %d layers and %d more, want %d and 3", len(g.Layers()), g.MoreLayers(), maxLayers)
+	}
+}
+
+func TestMermaid_TruncatesEdges(t *testing.T) {
+	// Six apps each importing the same five libraries: 30 edges
+	var components, libs []Component
+	for i := 0; i < 5; i++ {
+		libs = append(libs, Component{Dir: fmt.Sprintf("lib%d", i)})
+	}
+	for i := 0; i < 6; i++ {
+		app := Component{Dir: fmt.Sprintf("app%d", i)}
+		for _, lib := range libs {
+			app.Imports = append(app.Imports, lib.Dir)
+		}
+		components = append(components, app)
+	}
+	got := newGraph("Go", "m", append(components, libs...)).Mermaid()
+	if n := strings.Count(got, "-->"); n != maxDiagramEdges {
+		t.Errorf("Mermaid() draws %d edges, want %d", n, maxDiagramEdges)
+	}
+	if !strings.HasSuffix(got, "    %% … 6 more imports\n") {
+		t.Errorf("Mermaid() should note the left out edges:\n%s", got)
+	}
+}
+
+func TestMermaid_FlatLargeGraph(t *testing.T) {
+	// Too many top-level directories to collapse: only nodes with an
+	// arrow are drawn
+	var components []Component
+	for i := 0; i <= maxDiagramNodes; i++ {
+		components = append(components, Component{Dir: fmt.Sprintf("pkg%02d", i)})
+	}
+	components = append(components, Component{Dir: "app", Imports: []string{"pkg00"}})
+
+	got := newGraph("Go", "m", components).Mermaid()
+	want := "graph TD\n" +
+		"    n_app[\"app\"]\n" +
+		"    n_pkg00[\"pkg00\"]\n" +
+		"    n_app --> n_pkg00\n"
+	if got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		graph    *Graph
+		expected string
+	}{
+		{newGraph("Go", "example.com/app", []Component{{Dir: "."}, {Dir: "a"}}), "Go module `example.com/app` with 2 packages."},
+		{newGraph("Python", "shop", []Component{{Dir: "shop"}}), "Python project `shop` with 1 package."},
+		{newGraph("TypeScript", "web", []Component{{Dir: "src"}, {Dir: "lib"}}), "TypeScript project `web` with 2 directories."},
+	}
+	for _, tt := range tests {
+		if got := tt.graph.Summary(); got != tt.expected {
+			t.Errorf("Summary() = %q, want %q", got, tt.expected)
+		}
+	}
+}
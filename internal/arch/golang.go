@@ -0,0 +1,178 @@
+package arch
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadGo parses the Go packages of the module at root without building
+// them or touching the network. Nested modules, vendor, testdata and
+// hidden directories are skipped, as are test files.
+func LoadGo(root string) (*Graph, error) {
+	module, err := goModulePath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []Component
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root {
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || name == "node_modules" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		c, ok, err := parseGoPackage(root, path, module)
+		if err != nil {
+			return err
+		}
+		if ok {
+			components = append(components, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Go packages: %w", err)
+	}
+
+	return newGraph("Go", module, components), nil
+}
+
+// goModulePath reads the module path from root's go.mod
+func goModulePath(root string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read go.mod: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			path := strings.TrimSpace(rest)
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+			if path != "" {
+				return path, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no module path in %s", filepath.Join(root, "go.mod"))
+}
+
+// parseGoPackage parses the non-test Go files of dir. It reports false
+// when the directory holds no package.
+func parseGoPackage(root, dir, module string) (Component, bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Component{}, false, err
+	}
+
+	rel, _ := filepath.Rel(root, dir)
+	c := Component{Dir: filepath.ToSlash(rel)}
+	fset := token.NewFileSet()
+	imports := map[string]bool{}
+	found := false
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil || ignored(file) {
+			// Files that do not parse would not build either
+			continue
+		}
+
+		found = true
+		if c.Name == "" || c.Name == "main" && file.Name.Name != "main" {
+			c.Name = file.Name.Name
+		}
+		if file.Doc != nil && c.Doc == "" {
+			c.Doc = packageDoc(file.Name.Name, file.Doc.Text())
+		}
+		for _, imp := range file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			if path == module {
+				imports["."] = true
+			} else if rest, ok := strings.CutPrefix(path, module+"/"); ok {
+				imports[rest] = true
+			}
+		}
+		c.Interfaces = append(c.Interfaces, exportedInterfaces(file)...)
+	}
+	if !found {
+		return Component{}, false, nil
+	}
+
+	c.Entry = c.Name == "main"
+	for imp := range imports {
+		c.Imports = append(c.Imports, imp)
+	}
+	sort.Strings(c.Interfaces)
+	return c, true, nil
+}
+
+// packageDoc returns the first sentence of a package comment without the
+// conventional "Package name" opening, e.g. "Runs external plugins."
+func packageDoc(name, doc string) string {
+	sentence := firstSentence(doc)
+	rest, ok := strings.CutPrefix(sentence, "Package "+name+" ")
+	if !ok || rest == "" {
+		return sentence
+	}
+	return strings.ToUpper(rest[:1]) + rest[1:]
+}
+
+// ignored reports whether a file is excluded by a "//go:build ignore"
+// constraint, the convention for generator scripts
+func ignored(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if strings.TrimSpace(comment.Text) == "//go:build ignore" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exportedInterfaces returns the exported interface types of a file
+func exportedInterfaces(file *ast.File) []string {
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.IsExported() {
+				names = append(names, ts.Name.Name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,103 @@
+package arch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files relative to root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadGo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":    "module example.com/shop\n\ngo 1.22\n",
+		"shop.go":   "// Package shop is the public API. It wraps the store.\npackage shop\n\nimport _ \"example.com/shop/internal/store\"\n",
+		"gen.go":    "//go:build ignore\n\npackage main\n\nimport _ \"example.com/shop/cmd/shop\"\n",
+		"broken.go": "package shop\n\nfunc {",
+		"cmd/shop/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/shop"
+	"example.com/shop/internal/store"
+)
+
+func main() { fmt.Println(shop.X, store.Y) }
+`,
+		"internal/store/store.go": `// Package store persists orders.
+package store
+
+import "database/sql"
+
+// Store persists orders
+type Store interface{ Save() error }
+
+type cache interface{ get() }
+
+var Y sql.DB
+`,
+		"internal/store/store_test.go": "package store\n\nimport _ \"example.com/shop/cmd/shop\"\n",
+		"internal/store/testdata/x.go": "package x\n",
+		"tools/go.mod":                 "module example.com/shop/tools\n",
+		"tools/tools.go":               "package tools\n",
+		".hidden/h.go":                 "package hidden\n",
+		"docs/README.md":               "# Docs\n",
+	})
+
+	g, err := LoadGo(root)
+	if err != nil {
+		t.Fatalf("LoadGo() error = %v", err)
+	}
+	if g.Language != "Go" || g.Module != "example.com/shop" {
+		t.Errorf("graph = %s %s", g.Language, g.Module)
+	}
+
+	want := []Component{
+		{Name: "shop", Dir: ".", Doc: "Is the public API.", Imports: []string{"internal/store"}, Layer: 1},
+		{Name: "main", Dir: "cmd/shop", Imports: []string{".", "internal/store"}, Entry: true, Layer: 2},
+		{Name: "store", Dir: "internal/store", Doc: "Persists orders.", Interfaces: []string{"Store"}},
+	}
+	if !reflect.DeepEqual(g.Components, want) {
+		t.Errorf("Components =\n%+v\nwant\n%+v", g.Components, want)
+	}
+}
+
+func TestLoadGo_NoModule(t *testing.T) {
+	if _, err := LoadGo(t.TempDir()); err == nil {
+		t.Error("LoadGo() without go.mod should fail")
+	}
+}
+
+func TestGoModulePath(t *testing.T) {
+	tests := []struct {
+		gomod    string
+		expected string
+	}{
+		{"module example.com/a\n", "example.com/a"},
+		{"// comment\nmodule \"example.com/quoted\"\n", "example.com/quoted"},
+		{"modulex example.com/a\n", ""},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{"go.mod": tt.gomod})
+		got, _ := goModulePath(root)
+		if got != tt.expected {
+			t.Errorf("goModulePath(%q) = %q, want %q", tt.gomod, got, tt.expected)
+		}
+	}
+}
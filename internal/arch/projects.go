@@ -0,0 +1,290 @@
+package arch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// gradleInclude matches the project paths of a settings.gradle include
+	gradleInclude = regexp.MustCompile(`['"](:?[\w.-]+(?::[\w.-]+)*)['"]`)
+	// gradleProject matches a project(":lib") dependency
+	gradleProject = regexp.MustCompile(`project\s*\(\s*(?:path\s*[:=]\s*)?['"](:[\w:.-]+)['"]`)
+	// pyPathDep matches a Poetry or uv path source, e.g. { path = "../lib" }
+	pyPathDep = regexp.MustCompile(`\bpath\s*=\s*["']([^"']+)["']`)
+	// pyFileDep matches a PEP 508 direct reference to a local directory
+	pyFileDep = regexp.MustCompile(`@\s*file:(?://)?(?:/?\$\{PROJECT_ROOT\}/)?([^"'\s;,]+)`)
+	// pyWorkspaceDep matches a uv workspace source, e.g. lib = { workspace = true }
+	pyWorkspaceDep = regexp.MustCompile(`(?m)^\s*([\w.-]+)\s*=\s*\{[^}\n]*\bworkspace\s*=\s*true`)
+	// pyNameSeparators matches the runs PEP 503 normalizes to "-"
+	pyNameSeparators = regexp.MustCompile(`[-_.]+`)
+)
+
+// projectRef is a dependency of a project: either the identity of
+// another project (e.g. "go:example.com/lib") or a root-relative path
+type projectRef struct {
+	key  string
+	path string
+}
+
+// pomFile is the part of a pom.xml that identifies modules
+type pomFile struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Parent     struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+	} `xml:"parent"`
+	Dependencies []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+	} `xml:"dependencies>dependency"`
+}
+
+// LoadProjects resolves the dependencies between the projects of a
+// monorepo, given as root-relative directories, from their manifests:
+// Go requires and local replaces (which go.work workspaces resolve to
+// the same modules), package.json dependencies on workspace package
+// names or file:, link: and workspace: specifiers, Maven dependencies on
+// sibling modules, Gradle project() dependencies and the local path,
+// file: and uv workspace sources of pyproject.toml and requirements
+// files. Each project is a component; its layer is its build order.
+func LoadProjects(root string, dirs []string) *Graph {
+	identities := map[string]string{}
+	refs := map[string][]projectRef{}
+	for _, dir := range dirs {
+		keys, deps := readManifests(root, dir)
+		for _, key := range keys {
+			identities[key] = dir
+		}
+		refs[dir] = deps
+	}
+	for key, dir := range gradleProjects(root) {
+		if _, ok := identities[key]; !ok {
+			identities[key] = dir
+		}
+	}
+
+	components := make([]Component, 0, len(dirs))
+	for _, dir := range dirs {
+		c := Component{Name: path.Base(dir), Dir: dir}
+		for _, ref := range refs[dir] {
+			target, ok := identities[ref.key]
+			if ref.key == "" {
+				target, ok = owningProject(ref.path, dirs)
+			}
+			if ok {
+				c.Imports = append(c.Imports, target)
+			}
+		}
+		components = append(components, c)
+	}
+	abs, _ := filepath.Abs(root)
+	return newGraph("", filepath.Base(abs), components)
+}
+
+// readManifests returns the identities a project publishes and the
+// dependencies it declares
+func readManifests(root, dir string) (keys []string, refs []projectRef) {
+	read := func(name string) []byte {
+		content, _ := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), name))
+		return content
+	}
+	local := func(p string) projectRef {
+		return projectRef{path: path.Join(dir, filepath.ToSlash(p))}
+	}
+
+	if content := read("go.mod"); content != nil {
+		k, r := goModRefs(content)
+		keys = append(keys, k...)
+		for _, ref := range r {
+			if ref.path != "" {
+				ref = local(ref.path)
+			}
+			refs = append(refs, ref)
+		}
+	}
+
+	if content := read("package.json"); content != nil {
+		var pkg struct {
+			Name                 string            `json:"name"`
+			Dependencies         map[string]string `json:"dependencies"`
+			DevDependencies      map[string]string `json:"devDependencies"`
+			PeerDependencies     map[string]string `json:"peerDependencies"`
+			OptionalDependencies map[string]string `json:"optionalDependencies"`
+		}
+		if json.Unmarshal(content, &pkg) == nil {
+			if pkg.Name != "" {
+				keys = append(keys, "npm:"+pkg.Name)
+			}
+			for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+				for _, name := range sortedKeys(deps) {
+					version := deps[name]
+					if p, ok := cutAnyPrefix(version, "file:", "link:", "portal:"); ok {
+						refs = append(refs, local(p))
+					} else {
+						// workspace: specifiers and plain versions both
+						// link to a workspace package of that name
+						refs = append(refs, projectRef{key: "npm:" + name})
+					}
+				}
+			}
+		}
+	}
+
+	if content := read("pom.xml"); content != nil {
+		var pom pomFile
+		if xml.Unmarshal(content, &pom) == nil {
+			group := pom.GroupID
+			if group == "" {
+				group = pom.Parent.GroupID
+			}
+			keys = append(keys, "maven:"+group+":"+pom.ArtifactID)
+			if pom.Parent.ArtifactID != "" {
+				refs = append(refs, projectRef{key: "maven:" + pom.Parent.GroupID + ":" + pom.Parent.ArtifactID})
+			}
+			for _, d := range pom.Dependencies {
+				refs = append(refs, projectRef{key: "maven:" + d.GroupID + ":" + d.ArtifactID})
+			}
+		}
+	}
+
+	for _, name := range []string{"build.gradle", "build.gradle.kts"} {
+		for _, m := range gradleProject.FindAllStringSubmatch(string(read(name)), -1) {
+			refs = append(refs, projectRef{key: "gradle:" + m[1]})
+		}
+	}
+
+	if content := read("pyproject.toml"); content != nil {
+		if m := pyProjectName.FindSubmatch(content); m != nil {
+			keys = append(keys, "py:"+normalizePyName(string(m[1])))
+		}
+		for _, m := range pyPathDep.FindAllStringSubmatch(string(content), -1) {
+			refs = append(refs, local(m[1]))
+		}
+		for _, m := range pyFileDep.FindAllStringSubmatch(string(content), -1) {
+			refs = append(refs, local(m[1]))
+		}
+		for _, m := range pyWorkspaceDep.FindAllStringSubmatch(string(content), -1) {
+			refs = append(refs, projectRef{key: "py:" + normalizePyName(m[1])})
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+	for _, e := range entries {
+		if name := e.Name(); !e.IsDir() && strings.HasPrefix(name, "requirements") && strings.HasSuffix(name, ".txt") {
+			for _, line := range strings.Split(string(read(name)), "\n") {
+				line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-e "))
+				if strings.HasPrefix(line, "./") || strings.HasPrefix(line, "../") {
+					refs = append(refs, local(strings.Fields(line)[0]))
+				} else if m := pyFileDep.FindStringSubmatch(line); m != nil {
+					refs = append(refs, local(m[1]))
+				}
+			}
+		}
+	}
+	return keys, refs
+}
+
+// goModRefs returns the module path of a go.mod as a key, its requires as
+// keys and its local replacements as paths
+func goModRefs(content []byte) (keys []string, refs []projectRef) {
+	block := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == ")" {
+			block = ""
+			continue
+		}
+		directive := block
+		if block == "" {
+			directive, fields = fields[0], fields[1:]
+			if len(fields) > 0 && fields[0] == "(" {
+				block = directive
+				continue
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch directive {
+		case "module":
+			keys = append(keys, "go:"+strings.Trim(fields[0], `"`))
+		case "require":
+			refs = append(refs, projectRef{key: "go:" + fields[0]})
+		case "replace":
+			for i, f := range fields {
+				if f == "=>" && i+1 < len(fields) {
+					target := fields[i+1]
+					if strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") {
+						refs = append(refs, projectRef{path: target})
+					}
+				}
+			}
+		}
+	}
+	return keys, refs
+}
+
+// gradleProjects maps the project paths included by the root
+// settings.gradle, e.g. ":libs:core", to their directories
+func gradleProjects(root string) map[string]string {
+	projects := map[string]string{}
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if !strings.HasPrefix(strings.TrimSpace(line), "include") {
+				continue
+			}
+			for _, m := range gradleInclude.FindAllStringSubmatch(line, -1) {
+				p := ":" + strings.TrimPrefix(m[1], ":")
+				projects["gradle:"+p] = strings.ReplaceAll(p[1:], ":", "/")
+			}
+		}
+	}
+	return projects
+}
+
+// owningProject returns the project directory that contains p
+func owningProject(p string, dirs []string) (string, bool) {
+	best := ""
+	for _, dir := range dirs {
+		if (p == dir || dir != "." && strings.HasPrefix(p, dir+"/")) && len(dir) > len(best) {
+			best = dir
+		}
+	}
+	return best, best != ""
+}
+
+// normalizePyName normalizes a Python distribution name as PEP 503 does
+func normalizePyName(name string) string {
+	return strings.ToLower(pyNameSeparators.ReplaceAllString(name, "-"))
+}
+
+// cutAnyPrefix strips the first of prefixes s starts with
+func cutAnyPrefix(s string, prefixes ...string) (string, bool) {
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			return rest, true
+		}
+	}
+	return s, false
+}
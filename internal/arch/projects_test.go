@@ -0,0 +1,105 @@
+package arch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadProjects(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work": "go 1.22\n\nuse (\n\t./services/api\n\t./libs/auth\n)\n",
+		"services/api/go.mod": `module example.com/api
+
+require (
+	example.com/auth v0.0.0 // indirect
+	github.com/spf13/cobra v1.8.0
+)
+
+replace example.com/shared => ../../libs/shared/go
+`,
+		"libs/auth/go.mod":             "module example.com/auth\n",
+		"libs/shared/go/go.mod":        "module example.com/shared\n",
+		"web/package.json":             `{"name": "web", "dependencies": {"@acme/ui": "workspace:*", "react": "^18"}, "devDependencies": {"tokens": "file:../packages/tokens"}}`,
+		"packages/ui/package.json":     `{"name": "@acme/ui", "peerDependencies": {"react": "^18"}, "dependencies": {"tokens": "1.0.0"}}`,
+		"packages/tokens/package.json": `{"name": "tokens"}`,
+		"java/core/pom.xml": `<project>
+  <parent><groupId>com.acme</groupId><artifactId>parent</artifactId></parent>
+  <artifactId>core</artifactId>
+</project>`,
+		"java/app/pom.xml": `<project>
+  <groupId>com.acme</groupId>
+  <artifactId>app</artifactId>
+  <dependencies>
+    <dependency><groupId>com.acme</groupId><artifactId>core</artifactId></dependency>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId></dependency>
+  </dependencies>
+</project>`,
+		"settings.gradle":            "rootProject.name = 'acme'\ninclude ':android:app', 'android:lib'\n",
+		"android/app/build.gradle":   "dependencies {\n  implementation project(':android:lib')\n}\n",
+		"android/lib/build.gradle":   "dependencies {}\n",
+		"py/worker/pyproject.toml":   "[project]\nname = \"worker\"\ndependencies = [\"models @ file:///${PROJECT_ROOT}/../models\", \"requests\"]\n\n[tool.uv.sources]\nclient = { workspace = true }\n",
+		"py/models/pyproject.toml":   "[project]\nname = \"Acme_Models\"\n\n[tool.poetry.dependencies]\nshared = { path = \"../../libs/shared/py\", develop = true }\n",
+		"py/client/pyproject.toml":   "[project]\nname = \"client\"\n",
+		"py/client/requirements.txt": "-e ../models\nrequests==2.0\n",
+		"libs/shared/py/setup.py":    "",
+	})
+
+	dirs := []string{"services/api", "libs/auth", "libs/shared", "web", "packages/ui", "packages/tokens", "java/core", "java/app", "android/app", "android/lib", "py/worker", "py/models", "py/client"}
+	g := LoadProjects(root, dirs)
+
+	want := map[string][]string{
+		"services/api":    {"libs/auth", "libs/shared"},
+		"web":             {"packages/tokens", "packages/ui"},
+		"packages/ui":     {"packages/tokens"},
+		"java/app":        {"java/core"},
+		"android/app":     {"android/lib"},
+		"py/worker":       {"py/client", "py/models"},
+		"py/models":       {"libs/shared"},
+		"py/client":       {"py/models"},
+		"libs/auth":       nil,
+		"libs/shared":     nil,
+		"packages/tokens": nil,
+		"java/core":       nil,
+		"android/lib":     nil,
+	}
+	for _, c := range g.Components {
+		if !reflect.DeepEqual(c.Imports, want[c.Dir]) {
+			t.Errorf("%s depends on %v, want %v", c.Dir, c.Imports, want[c.Dir])
+		}
+	}
+
+	if got := g.Dependents("libs/shared"); !reflect.DeepEqual(got, []string{"py/models", "services/api"}) {
+		t.Errorf("Dependents(libs/shared) = %v", got)
+	}
+	if got := g.Impact("py/models"); !reflect.DeepEqual(got, []string{"py/client", "py/worker"}) {
+		t.Errorf("Impact(py/models) = %v", got)
+	}
+	if got := g.Impact("libs/shared"); !reflect.DeepEqual(got, []string{"py/client", "py/models", "py/worker", "services/api"}) {
+		t.Errorf("Impact(libs/shared) = %v", got)
+	}
+	if got := g.Layers()[0].Dirs; !reflect.DeepEqual(got, []string{"android/lib", "java/core", "libs/auth", "libs/shared", "packages/tokens"}) {
+		t.Errorf("first build layer = %v", got)
+	}
+}
+
+func TestGoModRefs(t *testing.T) {
+	keys, refs := goModRefs([]byte(`module "example.com/a" // root
+
+require example.com/b v1.0.0
+require (
+	example.com/c v1.0.0
+)
+replace (
+	example.com/b => ./b
+	example.com/c v1.0.0 => example.com/fork v1.1.0
+)
+`))
+	if want := []string{"go:example.com/a"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	want := []projectRef{{key: "go:example.com/b"}, {key: "go:example.com/c"}, {path: "./b"}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("refs = %+v, want %+v", refs, want)
+	}
+}
@@ -0,0 +1,236 @@
+package arch
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// pyImport matches "import a.b as c, d"
+	pyImport = regexp.MustCompile(`^\s*import\s+(.+)`)
+	// pyFromImport matches "from .a.b import x, y" and "from a import (x,"
+	pyFromImport = regexp.MustCompile(`^\s*from\s+(\.*)([\w.]*)\s+import\s+\(?\s*([^#]*)`)
+	// pyInterface matches abstract base classes and protocols
+	pyInterface = regexp.MustCompile(`^class\s+([A-Za-z]\w*)\s*\(([^)]*\b(?:ABC|Protocol|ABCMeta)\b[^)]*)\)`)
+	// pyMainGuard matches a script entry point
+	pyMainGuard = regexp.MustCompile(`(?m)^if\s+__name__\s*==\s*["']__main__["']`)
+	// pyScript matches a console script, e.g. app = "pkg.cli:main"
+	pyScript = regexp.MustCompile(`(?m)^\s*[\w.-]+\s*=\s*["']([\w.]+):[\w.]+["']`)
+	// pyProjectName matches the project name in pyproject.toml
+	pyProjectName = regexp.MustCompile(`(?m)^name\s*=\s*["']([^"']+)["']`)
+	// pyDocstring matches a module docstring at the top of a file
+	pyDocstring = regexp.MustCompile(`^(?:\s*#[^\n]*\n)*\s*[rRuU]?("""|''')((?s).*?)("""|''')`)
+)
+
+// pyModule is a parsed Python source file
+type pyModule struct {
+	// name is the dotted module name, e.g. "app.api.routes"
+	name string
+	// file is the root-relative path in slash form
+	file    string
+	imports []string
+	source  string
+}
+
+// LoadPython parses the import statements of the Python sources under
+// root, a src/ layout included, and groups the modules into one
+// component per directory. Tests, virtual environments and build output
+// are skipped.
+func LoadPython(root string) (*Graph, error) {
+	srcRoot := ""
+	if info, err := os.Stat(filepath.Join(root, "src")); err == nil && info.IsDir() {
+		srcRoot = "src"
+	}
+
+	var modules []pyModule
+	err := walkSources(root, []string{".py"}, func(rel string, content []byte) {
+		if isPythonTest(rel) {
+			return
+		}
+		modules = append(modules, pyModule{
+			name:    pyModuleName(rel, srcRoot),
+			file:    rel,
+			imports: pyImports(string(content)),
+			source:  string(content),
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Python modules: %w", err)
+	}
+
+	byName := map[string]pyModule{}
+	for _, m := range modules {
+		byName[m.name] = m
+	}
+	scripts := pyScripts(root)
+
+	components := map[string]*Component{}
+	for _, m := range modules {
+		dir := path.Dir(m.file)
+		c := components[dir]
+		if c == nil {
+			c = &Component{Name: path.Base(dir), Dir: dir}
+			if dir == "." || dir == srcRoot {
+				c.Name = pyProject(root)
+			}
+			components[dir] = c
+		}
+
+		base := path.Base(m.file)
+		if base == "__init__.py" {
+			if d := pyDocstring.FindStringSubmatch(m.source); d != nil {
+				c.Doc = firstSentence(d[2])
+			}
+		}
+		if base == "__main__.py" || pyMainGuard.MatchString(m.source) || scripts[m.name] {
+			c.Entry = true
+		}
+		for _, line := range strings.Split(m.source, "\n") {
+			if i := pyInterface.FindStringSubmatch(line); i != nil && !strings.HasPrefix(i[1], "_") {
+				c.Interfaces = append(c.Interfaces, i[1])
+			}
+		}
+		for _, imp := range m.imports {
+			if target, ok := resolvePython(imp, m, byName); ok {
+				c.Imports = append(c.Imports, path.Dir(target.file))
+			}
+		}
+	}
+
+	list := make([]Component, 0, len(components))
+	for _, c := range components {
+		sort.Strings(c.Interfaces)
+		list = append(list, *c)
+	}
+	return newGraph("Python", pyProject(root), list), nil
+}
+
+// pyModuleName returns the dotted module name of a source file relative
+// to the source root; packages are named by their __init__.py
+func pyModuleName(rel, srcRoot string) string {
+	if srcRoot != "" {
+		rel = strings.TrimPrefix(rel, srcRoot+"/")
+	}
+	rel = strings.TrimSuffix(rel, ".py")
+	rel = strings.TrimSuffix(rel, "/__init__")
+	return strings.ReplaceAll(rel, "/", ".")
+}
+
+// pyImports returns the modules a source file imports. Relative imports
+// keep their leading dots, and "from a import b" yields both "a.b" and
+// "a" since b may be a submodule.
+func pyImports(source string) []string {
+	var imports []string
+	for _, line := range strings.Split(source, "\n") {
+		if m := pyFromImport.FindStringSubmatch(line); m != nil {
+			base := m[1] + m[2]
+			for _, name := range strings.Split(m[3], ",") {
+				fields := strings.Fields(strings.Trim(name, "() \t\\"))
+				if len(fields) == 0 || fields[0] == "*" {
+					continue
+				}
+				sep := "."
+				if strings.HasSuffix(base, ".") {
+					sep = ""
+				}
+				imports = append(imports, base+sep+fields[0])
+			}
+			imports = append(imports, base)
+			continue
+		}
+		if m := pyImport.FindStringSubmatch(line); m != nil {
+			for _, name := range strings.Split(m[1], ",") {
+				if fields := strings.Fields(name); len(fields) > 0 && !strings.HasPrefix(fields[0], "#") {
+					imports = append(imports, fields[0])
+				}
+			}
+		}
+	}
+	return imports
+}
+
+// resolvePython finds the module an import refers to, trying the longest
+// known prefix of the dotted name
+func resolvePython(imp string, from pyModule, modules map[string]pyModule) (pyModule, bool) {
+	if dots := len(imp) - len(strings.TrimLeft(imp, ".")); dots > 0 {
+		// Relative to the importing package; __init__ modules are their
+		// own package
+		pkg := from.name
+		if path.Base(from.file) != "__init__.py" {
+			pkg = parentModule(pkg)
+		}
+		for i := 1; i < dots; i++ {
+			pkg = parentModule(pkg)
+		}
+		rest := strings.TrimLeft(imp, ".")
+		switch {
+		case pkg == "":
+			imp = rest
+		case rest == "":
+			imp = pkg
+		default:
+			imp = pkg + "." + rest
+		}
+	}
+
+	for name := imp; name != ""; name = parentModule(name) {
+		if m, ok := modules[name]; ok {
+			return m, true
+		}
+	}
+	return pyModule{}, false
+}
+
+// parentModule drops the last element of a dotted name
+func parentModule(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// isPythonTest reports whether a file is a test module or lives in a
+// tests directory
+func isPythonTest(rel string) bool {
+	base := path.Base(rel)
+	if strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py") || base == "conftest.py" {
+		return true
+	}
+	for _, part := range strings.Split(path.Dir(rel), "/") {
+		if part == "tests" || part == "test" {
+			return true
+		}
+	}
+	return false
+}
+
+// pyScripts returns the modules named by console scripts in
+// pyproject.toml
+func pyScripts(root string) map[string]bool {
+	content, err := os.ReadFile(filepath.Join(root, "pyproject.toml"))
+	if err != nil {
+		return nil
+	}
+	scripts := map[string]bool{}
+	for _, m := range pyScript.FindAllStringSubmatch(string(content), -1) {
+		scripts[m[1]] = true
+	}
+	return scripts
+}
+
+// pyProject returns the project name from pyproject.toml, or the name of
+// the root directory
+func pyProject(root string) string {
+	if content, err := os.ReadFile(filepath.Join(root, "pyproject.toml")); err == nil {
+		if m := pyProjectName.FindSubmatch(content); m != nil {
+			return string(m[1])
+		}
+	}
+	abs, _ := filepath.Abs(root)
+	return filepath.Base(abs)
+}
@@ -0,0 +1,118 @@
+package arch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadPython(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pyproject.toml": "[project]\nname = \"shop\"\n\n[project.scripts]\nshop = \"shop.cli:main\"\n",
+		"src/shop/__init__.py": `"""Online shop. Sells things."""
+`,
+		"src/shop/cli.py": `import argparse
+from shop.api import routes
+from .store import (
+    Store,
+)
+`,
+		"src/shop/api/__init__.py": "",
+		"src/shop/api/routes.py":   "from ..store.models import Order\nfrom . import handlers\n",
+		"src/shop/api/handlers.py": "import shop.store.models as m, os\n",
+		"src/shop/store/__init__.py": `from .models import Order
+
+class Store(ABC):
+    pass
+
+class _Cache(Protocol):
+    pass
+`,
+		"src/shop/store/models.py":         "from shop.api import routes  # cycle\n",
+		"src/shop/store/tests/test_x.py":   "from shop import cli\n",
+		"tests/test_cli.py":                "from shop import cli\n",
+		"scripts/seed.py":                  "from shop.store import Store\n\nif __name__ == \"__main__\":\n    pass\n",
+		".venv/lib/site.py":                "import shop\n",
+		"src/shop/__pycache__/x.py":        "import shop\n",
+		"build/lib/shop/__init__.py":       "import shop.api\n",
+		"docs/conf.py":                     "project = 'shop'\n",
+		"venv2/pyvenv.cfg":                 "home = /usr\n",
+		"venv2/lib/python3/site/mod.py":    "import shop\n",
+		"src/shop/store/models_helper.txt": "import shop.api\n",
+	})
+
+	g, err := LoadPython(root)
+	if err != nil {
+		t.Fatalf("LoadPython() error = %v", err)
+	}
+	if g.Language != "Python" || g.Module != "shop" {
+		t.Errorf("graph = %s %s", g.Language, g.Module)
+	}
+
+	want := []Component{
+		{Name: "docs", Dir: "docs"},
+		{Name: "scripts", Dir: "scripts", Imports: []string{"src/shop/store"}, Entry: true, Layer: 2},
+		{Name: "shop", Dir: "src/shop", Doc: "Online shop.", Imports: []string{"src/shop/api", "src/shop/store"}, Entry: true, Layer: 2},
+		{Name: "api", Dir: "src/shop/api", Imports: []string{"src/shop/store"}},
+		{Name: "store", Dir: "src/shop/store", Imports: []string{"src/shop/api"}, Interfaces: []string{"Store"}, Layer: 1},
+	}
+	if !reflect.DeepEqual(g.Components, want) {
+		t.Errorf("Components =\n%+v\nwant\n%+v", g.Components, want)
+	}
+	if cycles := g.Cycles(); !reflect.DeepEqual(cycles, [][]string{{"src/shop/api", "src/shop/store"}}) {
+		t.Errorf("Cycles() = %v", cycles)
+	}
+}
+
+func TestPyImports(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected []string
+	}{
+		{"import os, a.b as c\n", []string{"os", "a.b"}},
+		{"from a import b, c as d\n", []string{"a.b", "a.c", "a"}},
+		{"from . import x\n", []string{".x", "."}},
+		{"from ..pkg import *\n", []string{"..pkg"}},
+		{"from a import (\n    b,\n)\n", []string{"a"}},
+		{"# import os\nx = 1\n", nil},
+	}
+	for _, tt := range tests {
+		if got := pyImports(tt.source); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("pyImports(%q) = %v, want %v", tt.source, got, tt.expected)
+		}
+	}
+}
+
+func TestResolvePython(t *testing.T) {
+	modules := map[string]pyModule{}
+	for _, m := range []pyModule{
+		{name: "app", file: "app/__init__.py"},
+		{name: "app.api", file: "app/api/__init__.py"},
+		{name: "app.api.routes", file: "app/api/routes.py"},
+		{name: "app.db", file: "app/db.py"},
+	} {
+		modules[m.name] = m
+	}
+	routes := modules["app.api.routes"]
+	api := modules["app.api"]
+
+	tests := []struct {
+		imp      string
+		from     pyModule
+		expected string
+	}{
+		{"app.api.routes.handler", routes, "app/api/routes.py"},
+		{"app.api", routes, "app/api/__init__.py"},
+		{".", routes, "app/api/__init__.py"},
+		{"..db", routes, "app/db.py"},
+		{".routes", api, "app/api/routes.py"},
+		{"..db", api, "app/db.py"},
+		{"requests", routes, ""},
+	}
+	for _, tt := range tests {
+		got, _ := resolvePython(tt.imp, tt.from, modules)
+		if got.file != tt.expected {
+			t.Errorf("resolvePython(%q from %s) = %q, want %q", tt.imp, tt.from.name, got.file, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package arch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// jsExts are the source extensions in resolution order
+var jsExts = []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+var (
+	// jsImports match static imports and re-exports, side-effect imports,
+	// require calls and dynamic imports
+	jsImports = []*regexp.Regexp{
+		regexp.MustCompile(`\bfrom\s*['"]([^'"\n]+)['"]`),
+		regexp.MustCompile(`(?m)^\s*import\s*['"]([^'"\n]+)['"]`),
+		regexp.MustCompile(`\brequire\s*\(\s*['"]([^'"\n]+)['"]\s*\)`),
+		regexp.MustCompile(`\bimport\s*\(\s*['"]([^'"\n]+)['"]\s*\)`),
+	}
+	// jsInterface matches exported interfaces and abstract classes
+	jsInterface = regexp.MustCompile(`(?m)^export\s+(?:declare\s+)?(?:default\s+)?(?:interface|abstract\s+class)\s+([A-Za-z_$][\w$]*)`)
+	// jsDocBlock matches a block comment at the top of a file
+	jsDocBlock = regexp.MustCompile(`^\s*/\*\*?((?s).*?)\*/`)
+	// jsTrailingComma matches the trailing commas JSONC allows
+	jsTrailingComma = regexp.MustCompile(`,(\s*[}\]])`)
+)
+
+// jsPackage is a package.json of the repository
+type jsPackage struct {
+	Name    string          `json:"name"`
+	Main    string          `json:"main"`
+	Module  string          `json:"module"`
+	Bin     json.RawMessage `json:"bin"`
+	Exports json.RawMessage `json:"exports"`
+	// dir is the root-relative directory in slash form
+	dir string
+}
+
+// tsConfig holds the module resolution options of a tsconfig.json
+type tsConfig struct {
+	Extends         string `json:"extends"`
+	CompilerOptions struct {
+		BaseURL string              `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+}
+
+// jsResolver resolves import specifiers to root-relative source files
+type jsResolver struct {
+	files    map[string]bool
+	packages []jsPackage
+	// baseURL is the root-relative directory non-relative imports and
+	// path aliases resolve from, "" when tsconfig sets none
+	baseURL string
+	paths   map[string][]string
+}
+
+// LoadTypeScript parses the import, export-from and require statements of
+// the TypeScript and JavaScript sources under root and groups the modules
+// into one component per directory. Imports resolve through relative
+// paths, the baseUrl and paths aliases of tsconfig.json (or
+// jsconfig.json) and the names of workspace packages; anything else is an
+// external dependency. Tests, declaration files and build output are
+// skipped.
+func LoadTypeScript(root string) (*Graph, error) {
+	sources := map[string]string{}
+	err := walkSources(root, jsExts, func(rel string, content []byte) {
+		if !isJSTest(rel) && !strings.HasSuffix(rel, ".d.ts") && !strings.Contains(path.Base(rel), ".config.") {
+			sources[rel] = string(content)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TypeScript modules: %w", err)
+	}
+
+	r := &jsResolver{files: map[string]bool{}}
+	language := "JavaScript"
+	for rel := range sources {
+		r.files[rel] = true
+		if ext := path.Ext(rel); ext == ".ts" || ext == ".tsx" || ext == ".mts" || ext == ".cts" {
+			language = "TypeScript"
+		}
+	}
+	if r.packages, err = jsPackages(root); err != nil {
+		return nil, err
+	}
+	r.loadTSConfig(root)
+
+	entries := map[string]bool{}
+	entryPackages := r.packages
+	if len(entryPackages) == 0 || entryPackages[0].dir != "." {
+		// Plain projects without a root package.json use the conventions
+		entryPackages = append([]jsPackage{{dir: "."}}, entryPackages...)
+	}
+	for _, pkg := range entryPackages {
+		for _, file := range r.entryFiles(pkg) {
+			entries[file] = true
+		}
+	}
+
+	components := map[string]*Component{}
+	module := jsModule(root, r.packages)
+	for _, rel := range sortedKeys(sources) {
+		source := sources[rel]
+		dir := path.Dir(rel)
+		c := components[dir]
+		if c == nil {
+			c = &Component{Name: path.Base(dir), Dir: dir}
+			if dir == "." {
+				c.Name = module
+			}
+			components[dir] = c
+		}
+
+		if strings.TrimSuffix(path.Base(rel), path.Ext(rel)) == "index" && c.Doc == "" {
+			if m := jsDocBlock.FindStringSubmatch(source); m != nil {
+				c.Doc = firstSentence(jsDoc(m[1]))
+			}
+		}
+		if entries[rel] {
+			c.Entry = true
+		}
+		for _, m := range jsInterface.FindAllStringSubmatch(source, -1) {
+			c.Interfaces = append(c.Interfaces, m[1])
+		}
+		for _, spec := range jsSpecifiers(source) {
+			if target, ok := r.resolve(spec, rel); ok {
+				c.Imports = append(c.Imports, path.Dir(target))
+			}
+		}
+	}
+
+	list := make([]Component, 0, len(components))
+	for _, c := range components {
+		sort.Strings(c.Interfaces)
+		list = append(list, *c)
+	}
+	return newGraph(language, module, list), nil
+}
+
+// jsSpecifiers returns the module specifiers a source file imports
+func jsSpecifiers(source string) []string {
+	var specs []string
+	for _, re := range jsImports {
+		for _, m := range re.FindAllStringSubmatch(source, -1) {
+			specs = append(specs, m[1])
+		}
+	}
+	return specs
+}
+
+// resolve maps an import specifier in file from to a source file
+func (r *jsResolver) resolve(spec, from string) (string, bool) {
+	if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") || spec == "." || spec == ".." {
+		return r.file(path.Join(path.Dir(from), spec))
+	}
+
+	// tsconfig paths, longest pattern first as the compiler does
+	patterns := make([]string, 0, len(r.paths))
+	for p := range r.paths {
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+	for _, pattern := range patterns {
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		var match string
+		switch {
+		case !wildcard && spec == pattern:
+		case wildcard && strings.HasPrefix(spec, prefix) && strings.HasSuffix(spec, suffix) && len(spec) >= len(prefix)+len(suffix):
+			match = spec[len(prefix) : len(spec)-len(suffix)]
+		default:
+			continue
+		}
+		for _, target := range r.paths[pattern] {
+			if file, ok := r.file(path.Join(r.baseURL, strings.Replace(target, "*", match, 1))); ok {
+				return file, true
+			}
+		}
+	}
+
+	// Workspace packages, by name or subpath
+	for _, pkg := range r.packages {
+		if pkg.Name == "" {
+			continue
+		}
+		if spec == pkg.Name {
+			if files := r.entryFiles(pkg); len(files) > 0 {
+				return files[0], true
+			}
+		} else if sub, ok := strings.CutPrefix(spec, pkg.Name+"/"); ok {
+			if file, ok := r.file(path.Join(pkg.dir, sub)); ok {
+				return file, true
+			}
+			if file, ok := r.file(path.Join(pkg.dir, "src", sub)); ok {
+				return file, true
+			}
+		}
+	}
+
+	if r.baseURL != "" {
+		return r.file(path.Join(r.baseURL, spec))
+	}
+	return "", false
+}
+
+// file resolves a root-relative path the way bundlers do: as written,
+// with a source extension, as a directory index, and with a .js extension
+// standing for its TypeScript source
+func (r *jsResolver) file(p string) (string, bool) {
+	p = path.Clean(p)
+	if r.files[p] {
+		return p, true
+	}
+	base := p
+	switch path.Ext(p) {
+	case ".js", ".jsx", ".mjs", ".cjs":
+		base = strings.TrimSuffix(p, path.Ext(p))
+	}
+	for _, candidate := range []string{base, path.Join(p, "index")} {
+		for _, ext := range jsExts {
+			if r.files[candidate+ext] {
+				return candidate + ext, true
+			}
+		}
+	}
+	return "", false
+}
+
+// entryFiles returns the source files a package exposes through main,
+// module, bin and exports. Paths into build output are mapped back to
+// src/, and packages without such fields fall back to the conventional
+// src/index, src/main, index and main files.
+func (r *jsResolver) entryFiles(pkg jsPackage) []string {
+	targets := []string{pkg.Main, pkg.Module}
+	targets = append(targets, jsonStrings(pkg.Bin)...)
+	targets = append(targets, jsonStrings(pkg.Exports)...)
+
+	var files []string
+	seen := map[string]bool{}
+	add := func(target string) bool {
+		if target == "" {
+			return false
+		}
+		file, ok := r.file(path.Join(pkg.dir, target))
+		if !ok {
+			// "dist/cli.js" built from "src/cli.ts"
+			parts := strings.SplitN(path.Clean(target), "/", 2)
+			if len(parts) == 2 && (parts[0] == "dist" || parts[0] == "build" || parts[0] == "lib" || parts[0] == "out") {
+				file, ok = r.file(path.Join(pkg.dir, "src", parts[1]))
+			}
+		}
+		if ok && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+		return ok
+	}
+	for _, target := range targets {
+		add(target)
+	}
+	if len(files) == 0 {
+		for _, target := range []string{"src/index", "src/main", "index", "main"} {
+			if add(target) {
+				break
+			}
+		}
+	}
+	return files
+}
+
+// loadTSConfig reads the resolution options of tsconfig.json or
+// jsconfig.json at root, following relative extends
+func (r *jsResolver) loadTSConfig(root string) {
+	name := "tsconfig.json"
+	if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+		name = "jsconfig.json"
+	}
+	// extends chains are short; the bound guards against loops
+	for depth := 0; depth < 5 && name != ""; depth++ {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return
+		}
+		var config tsConfig
+		if err := json.Unmarshal(stripJSONC(content), &config); err != nil {
+			return
+		}
+		// Options of the extending file win
+		dir := path.Dir(name)
+		if config.CompilerOptions.BaseURL != "" && r.baseURL == "" {
+			r.baseURL = path.Join(dir, config.CompilerOptions.BaseURL)
+		}
+		if config.CompilerOptions.Paths != nil && r.paths == nil {
+			r.paths = config.CompilerOptions.Paths
+			if r.baseURL == "" {
+				// paths without baseUrl resolve from their own tsconfig
+				r.baseURL = dir
+			}
+		}
+
+		name = ""
+		if strings.HasPrefix(config.Extends, ".") {
+			name = path.Join(dir, config.Extends)
+			if path.Ext(name) != ".json" {
+				name += ".json"
+			}
+		}
+	}
+}
+
+// stripJSONC removes the comments and trailing commas tsconfig files allow
+func stripJSONC(content []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				out = append(out, content[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			out = append(out, '\n')
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := strings.Index(string(content[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		default:
+			out = append(out, c)
+		}
+	}
+	return jsTrailingComma.ReplaceAll(out, []byte("$1"))
+}
+
+// jsPackages reads the package.json files of the repository, the root
+// first
+func jsPackages(root string) ([]jsPackage, error) {
+	var packages []jsPackage
+	err := walkSources(root, []string{".json"}, func(rel string, content []byte) {
+		if path.Base(rel) != "package.json" {
+			return
+		}
+		var pkg jsPackage
+		if json.Unmarshal(content, &pkg) == nil {
+			pkg.dir = path.Dir(rel)
+			packages = append(packages, pkg)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json files: %w", err)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return strings.Count(packages[i].dir, "/") < strings.Count(packages[j].dir, "/") ||
+			strings.Count(packages[i].dir, "/") == strings.Count(packages[j].dir, "/") && packages[i].dir < packages[j].dir
+	})
+	return packages, nil
+}
+
+// jsModule names the project after the root package.json, or the root
+// directory
+func jsModule(root string, packages []jsPackage) string {
+	if len(packages) > 0 && packages[0].dir == "." && packages[0].Name != "" {
+		return packages[0].Name
+	}
+	abs, _ := filepath.Abs(root)
+	return filepath.Base(abs)
+}
+
+// jsonStrings returns the string values of a package.json field that is
+// either a string or a (nested) object of strings, e.g. bin and exports
+func jsonStrings(raw json.RawMessage) []string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return []string{s}
+	}
+	var m map[string]json.RawMessage
+	if json.Unmarshal(raw, &m) != nil {
+		return nil
+	}
+	var values []string
+	for _, key := range sortedKeys(m) {
+		values = append(values, jsonStrings(m[key])...)
+	}
+	return values
+}
+
+// jsDoc strips the leading asterisks and tag lines of a block comment
+func jsDoc(comment string) string {
+	var lines []string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if strings.HasPrefix(line, "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isJSTest reports whether a file is a test, story or mock
+func isJSTest(rel string) bool {
+	base := path.Base(rel)
+	if strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") || strings.Contains(base, ".stories.") {
+		return true
+	}
+	for _, part := range strings.Split(path.Dir(rel), "/") {
+		if part == "__tests__" || part == "__mocks__" || part == "tests" || part == "test" || part == "e2e" {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
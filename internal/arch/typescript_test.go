@@ -0,0 +1,109 @@
+package arch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadTypeScript(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json": `{"name": "web", "bin": {"web": "dist/cli.js"}, "workspaces": ["packages/*"]}`,
+		"tsconfig.json": `{
+  // shared options
+  "extends": "./tsconfig.base",
+  "compilerOptions": {
+    "paths": { "@/*": ["src/*"], "@config": ["src/config/index.ts"] },
+  },
+}`,
+		"tsconfig.base.json": `{"compilerOptions": {"baseUrl": "."}}`,
+		"src/cli.ts": `#!/usr/bin/env node
+import { serve } from "@/server";
+import config from '@config';
+import "./polyfills.js";
+`,
+		"src/polyfills.ts": "export {}\n",
+		"src/server/index.ts": `/**
+ * HTTP server. Serves the API.
+ * @packageDocumentation
+ */
+import { Router } from "./router";
+import { Order } from "@shop/models";
+import express from "express";
+export * from "./types";
+`,
+		"src/server/router.ts":              "const db = require('../db/client');\nexport interface Router {}\n",
+		"src/server/types.ts":               "export declare interface Handler {}\nexport abstract class Base {}\ninterface internal {}\n",
+		"src/db/client.js":                  "module.exports = {}\n",
+		"src/config/index.ts":               "export default {}\nconst lazy = () => import('src/db/client');\n",
+		"src/server/index.test.ts":          "import '../cli'\n",
+		"src/__tests__/x.ts":                "import '@/cli'\n",
+		"src/types.d.ts":                    "declare module 'x'\n",
+		"vite.config.ts":                    "import '@/cli'\n",
+		"packages/models/package.json":      `{"name": "@shop/models", "main": "dist/index.js"}`,
+		"packages/models/src/index.ts":      "export * from './order'\n",
+		"packages/models/src/order.ts":      "export interface Order {}\n",
+		"packages/models/dist/index.js":     "require('./order')\n",
+		"node_modules/express/index.js":     "require('../../src/cli')\n",
+		"node_modules/express/package.json": `{"name": "express"}`,
+	})
+
+	g, err := LoadTypeScript(root)
+	if err != nil {
+		t.Fatalf("LoadTypeScript() error = %v", err)
+	}
+	if g.Language != "TypeScript" || g.Module != "web" {
+		t.Errorf("graph = %s %s", g.Language, g.Module)
+	}
+
+	want := []Component{
+		{Name: "src", Dir: "packages/models/src", Interfaces: []string{"Order"}, Entry: true},
+		{Name: "src", Dir: "src", Imports: []string{"src/config", "src/server"}, Entry: true, Layer: 2},
+		{Name: "config", Dir: "src/config", Imports: []string{"src/db"}, Layer: 1},
+		{Name: "db", Dir: "src/db"},
+		{Name: "server", Dir: "src/server", Doc: "HTTP server.", Imports: []string{"packages/models/src", "src/db"}, Interfaces: []string{"Base", "Handler", "Router"}, Layer: 1},
+	}
+	if !reflect.DeepEqual(g.Components, want) {
+		t.Errorf("Components =\n%+v\nwant\n%+v", g.Components, want)
+	}
+}
+
+func TestLoadTypeScript_JavaScript(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"index.js":     "const lib = require('./lib')\n",
+		"lib/index.js": "module.exports = {}\n",
+	})
+
+	g, err := LoadTypeScript(root)
+	if err != nil {
+		t.Fatalf("LoadTypeScript() error = %v", err)
+	}
+	if g.Language != "JavaScript" {
+		t.Errorf("Language = %s, want JavaScript", g.Language)
+	}
+	want := []Component{
+		{Name: g.Module, Dir: ".", Imports: []string{"lib"}, Entry: true, Layer: 1},
+		{Name: "lib", Dir: "lib"},
+	}
+	if !reflect.DeepEqual(g.Components, want) {
+		t.Errorf("Components =\n%+v\nwant\n%+v", g.Components, want)
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"a": 1, // note` + "\n}", "{\"a\": 1 \n}"},
+		{`{"url": "http://x/*y*/"}`, `{"url": "http://x/*y*/"}`},
+		{`{/* block */"a": [1, 2,],}`, `{"a": [1, 2]}`},
+		{`{"q": "say \"//\""}`, `{"q": "say \"//\""}`},
+	}
+	for _, tt := range tests {
+		if got := string(stripJSONC([]byte(tt.input))); got != tt.expected {
+			t.Errorf("stripJSONC(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
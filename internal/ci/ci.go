@@ -0,0 +1,287 @@
+// Package ci reads a repository's CI configuration to learn how it is
+// really built, tested and linted. It understands GitHub Actions
+// workflows, GitLab CI, CircleCI and declarative Jenkinsfiles.
+package ci
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kind classifies a CI command
+type Kind string
+
+// Command kinds, in the order they run in a typical job
+const (
+	Setup Kind = "setup"
+	Build Kind = "build"
+	Test  Kind = "test"
+	Lint  Kind = "lint"
+)
+
+// Command is a setup, build, test or lint invocation in a CI job
+type Command struct {
+	Kind Kind
+	Run  string
+	// Dir is the root-relative working directory in slash form, "" for
+	// the root
+	Dir string
+}
+
+// Job is a CI job with the commands it runs
+type Job struct {
+	// Name identifies the job, e.g. "ci.yml: test"
+	Name string
+	// Environment describes what the job runs in: toolchains, container
+	// images, services and environment variables
+	Environment []string
+	Commands    []Command
+}
+
+// Pipeline is the repository's CI configuration
+type Pipeline struct {
+	Jobs []Job
+}
+
+// parsers read the CI files of each supported system
+var parsers = []func(root string) []Job{
+	parseGitHub,
+	parseGitLab,
+	parseCircleCI,
+	parseJenkinsfile,
+}
+
+// Parse reads every CI configuration under root. Files that are missing
+// or malformed contribute no jobs.
+func Parse(root string) Pipeline {
+	var p Pipeline
+	for _, parse := range parsers {
+		for _, job := range parse(root) {
+			if len(job.Commands) > 0 {
+				p.Jobs = append(p.Jobs, job)
+			}
+		}
+	}
+	return p
+}
+
+// For returns the part of the pipeline that runs in dir, a root-relative
+// slash path, or below it, with directories relative to dir. The root
+// ("" or ".") gets the whole pipeline.
+func (p Pipeline) For(dir string) Pipeline {
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	if dir == "" {
+		return p
+	}
+
+	var out Pipeline
+	for _, job := range p.Jobs {
+		var cmds []Command
+		for _, cmd := range job.Commands {
+			if cmd.Dir == dir {
+				cmd.Dir = ""
+			} else if rest, ok := strings.CutPrefix(cmd.Dir, dir+"/"); ok {
+				cmd.Dir = rest
+			} else {
+				continue
+			}
+			cmds = append(cmds, cmd)
+		}
+		if len(cmds) > 0 {
+			job.Commands = cmds
+			out.Jobs = append(out.Jobs, job)
+		}
+	}
+	return out
+}
+
+// Shell returns the command as run from the root, e.g.
+// "(cd api && go test ./...)"
+func (c Command) Shell() string {
+	if c.Dir == "" {
+		return c.Run
+	}
+	return fmt.Sprintf("(cd %s && %s)", c.Dir, c.Run)
+}
+
+// Runs returns the distinct shell commands of a kind across all jobs, in
+// order
+func (p Pipeline) Runs(kind Kind) []string {
+	var runs []string
+	seen := map[string]bool{}
+	for _, job := range p.Jobs {
+		for _, cmd := range job.Commands {
+			if run := cmd.Shell(); cmd.Kind == kind && !seen[run] {
+				seen[run] = true
+				runs = append(runs, run)
+			}
+		}
+	}
+	return runs
+}
+
+// Environment returns the distinct environment entries of all jobs,
+// sorted
+func (p Pipeline) Environment() []string {
+	seen := map[string]bool{}
+	var env []string
+	for _, job := range p.Jobs {
+		for _, e := range job.Environment {
+			if !seen[e] {
+				seen[e] = true
+				env = append(env, e)
+			}
+		}
+	}
+	sort.Strings(env)
+	return env
+}
+
+// Lines formats the distinct commands as a shell cheat sheet grouped by
+// kind, each with its kind and first job as an aligned comment
+func (p Pipeline) Lines() []string {
+	type entry struct{ run, note string }
+	var entries []entry
+	width := 0
+	for _, kind := range []Kind{Setup, Build, Test, Lint} {
+		seen := map[string]bool{}
+		for _, job := range p.Jobs {
+			for _, cmd := range job.Commands {
+				run := cmd.Shell()
+				if cmd.Kind != kind || seen[run] {
+					continue
+				}
+				seen[run] = true
+				entries = append(entries, entry{run, fmt.Sprintf("%s, %s", kind, job.Name)})
+				width = max(width, len(run))
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, fmt.Sprintf("%-*s  # %s", width, e.run, e.note))
+	}
+	return lines
+}
+
+// testRunner matches a command line that starts with a known test
+// runner, after optional environment assignments and a wrapper such as
+// "npx" or "poetry run"; "mvn package verify" is a test but "docker build
+// -t test ." is not
+var testRunner = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*=\S* )*(sudo )?((npx|bunx|pnpm exec|yarn exec|poetry run|uv run|pipenv run|pdm run|hatch run|bundle exec|xvfb-run( -a)?) )?(` +
+	`(pytest|py\.test|tox|nox|jest|vitest|mocha|karma|ava|tap|playwright test|cypress run|rspec|phpunit|ctest|go test|gotestsum|cargo test|cargo nextest|dotnet test|mix test|bazel test)\b|` +
+	`python3? -m (pytest|unittest|tox|nox)\b|` +
+	`(\./)?mvnw?( \S+)* (verify|test)\b|` +
+	`(\./)?gradlew?( \S+)* (check|test)\b|` +
+	`(make|just|task|mage)( \S+)* test[\w:.-]*( |$)|` +
+	`(npm|pnpm|yarn|bun)( run| run-script)? test[\w:.-]*( |$)|npm t( |$)|` +
+	`(\S*/)?\S*tests?\.sh( |$)|\S*/(run[-_]?)?tests?( |$)|run[-_]tests?( |$))`)
+
+// kindPatterns classify a command line that is neither setup nor a test;
+// the first match wins, so "npm run test:lint" is a lint
+var kindPatterns = []struct {
+	kind    Kind
+	pattern *regexp.Regexp
+}{
+	{Lint, regexp.MustCompile(`lint\b|\b(ruff|flake8|pylint|mypy|pyright|black --check|isort --check|eslint|prettier --check|tsc --noEmit|go vet|gofmt|staticcheck|pre-commit run|checkstyle|spotless:check|ktlint|detekt|rubocop|hadolint|shellcheck|cargo clippy|cargo fmt)\b`)},
+	{Test, testRunner},
+	{Build, regexp.MustCompile(`\b(build|compile|package|assemble|tsc|webpack|goreleaser)\b`)},
+}
+
+// setupPattern matches dependency installation, checked first so that
+// e.g. "pip install -e .[test]" is not taken for a test run
+var setupPattern = regexp.MustCompile(`^(npm ci|npm install|pnpm install|yarn install|yarn$|bun install|pip3? install|python3? -m pip install|pipenv install|poetry install|uv sync|uv pip install|go mod download|bundle install|composer install|(sudo )?apt(-get)? install|make (install|deps|setup))\b`)
+
+// skipPattern matches lines that only print, such as
+// echo "all tests passed"
+var skipPattern = regexp.MustCompile(`^(echo|printf)\b`)
+
+// classify returns the kind of a command line, or "" for commands that
+// are neither setup, build, test nor lint. Lines with CI expressions
+// such as ${{ matrix.tags }} are skipped: they cannot run outside CI.
+func classify(run string) Kind {
+	if skipPattern.MatchString(run) || strings.Contains(run, "${{") {
+		return ""
+	}
+	if setupPattern.MatchString(run) {
+		return Setup
+	}
+	for _, p := range kindPatterns {
+		if p.pattern.MatchString(run) {
+			return p.kind
+		}
+	}
+	return ""
+}
+
+// commands splits a shell script into its classified commands. Lines are
+// joined at trailing backslashes and split at "&&"; a leading "cd dir"
+// moves the working directory of the commands after it.
+func commands(script, dir string) []Command {
+	var cmds []Command
+	for _, line := range scriptLines(script) {
+		for _, part := range strings.Split(line, "&&") {
+			part = strings.TrimSpace(part)
+			if target, ok := strings.CutPrefix(part, "cd "); ok {
+				dir = joinDir(dir, strings.TrimSpace(target))
+				continue
+			}
+			if kind := classify(part); kind != "" {
+				cmds = append(cmds, Command{Kind: kind, Run: part, Dir: dir})
+			}
+		}
+	}
+	return cmds
+}
+
+// scriptLines returns the non-comment lines of a script with backslash
+// continuations joined
+func scriptLines(script string) []string {
+	var lines []string
+	current := ""
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if cont, ok := strings.CutSuffix(line, "\\"); ok {
+			current += strings.TrimSpace(cont) + " "
+			continue
+		}
+		line = strings.TrimSpace(current + line)
+		current = ""
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if current = strings.TrimSpace(current); current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// joinDir resolves a working directory against the current one; paths
+// leaving the repository, absolute paths and variables reset to the root
+func joinDir(dir, target string) string {
+	target = strings.Trim(target, `"'`)
+	if target == "" || strings.ContainsAny(target, "$~") || path.IsAbs(target) {
+		return ""
+	}
+	joined := path.Join(dir, target)
+	if joined == "." || strings.HasPrefix(joined, "..") {
+		return ""
+	}
+	return joined
+}
+
+// readFile reads a root-relative file, returning nil when it is missing
+func readFile(root, rel string) []byte {
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	return content
+}
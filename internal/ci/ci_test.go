@@ -0,0 +1,182 @@
+package ci
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files relative to root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		run      string
+		expected Kind
+	}{
+		{"go test -race ./...", Test},
+		{"make test", Test},
+		{"pnpm run test:unit", Test},
+		{"./mvnw -B verify", Test},
+		{"./gradlew check", Test},
+		{"tox -e py312", Test},
+		{"golangci-lint run", Lint},
+		{"npm run lint", Lint},
+		{"ruff check .", Lint},
+		{"pre-commit run --all-files", Lint},
+		{"go vet ./...", Lint},
+		{"go build ./...", Build},
+		{"pnpm build", Build},
+		{"./mvnw package -DskipTests", Build},
+		{"npm ci", Setup},
+		{"pip install -e .[test]", Setup},
+		{"go mod download", Setup},
+		{"CGO_ENABLED=1 go test ./...", Test},
+		{"poetry run pytest -x", Test},
+		{"python -m unittest discover", Test},
+		{"make -C api test-unit", Test},
+		{"./scripts/run-tests.sh --fast", Test},
+		{"echo done", ""},
+		{`echo "all tests passed"`, ""},
+		{"printf 'test ok\\n'", ""},
+		{"docker build -t test .", Build},
+		{"go test -race ./... -tags ${{ matrix.tags }}", ""},
+		{"test -f go.sum", ""},
+		{"git fetch --tags", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.run, func(t *testing.T) {
+			if got := classify(tt.run); got != tt.expected {
+				t.Errorf("classify(%q) = %q, want %q", tt.run, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	script := `# comment
+cd services/api && go mod download
+go test \
+  -race ./...
+cd ../web
+npm ci && npm test
+cd /tmp && make build`
+
+	want := []Command{
+		{Kind: Setup, Run: "go mod download", Dir: "services/api"},
+		{Kind: Test, Run: "go test -race ./...", Dir: "services/api"},
+		{Kind: Setup, Run: "npm ci", Dir: "services/web"},
+		{Kind: Test, Run: "npm test", Dir: "services/web"},
+		{Kind: Build, Run: "make build", Dir: ""},
+	}
+	if got := commands(script, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("commands() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	p := Pipeline{Jobs: []Job{
+		{
+			Name:        "ci.yml: api",
+			Environment: []string{"actions/setup-go (go-version: 1.22)"},
+			Commands: []Command{
+				{Kind: Test, Run: "go test ./...", Dir: "api"},
+				{Kind: Lint, Run: "golangci-lint run", Dir: "api"},
+			},
+		},
+		{
+			Name:        "ci.yml: all",
+			Environment: []string{"CI=true"},
+			Commands: []Command{
+				{Kind: Test, Run: "make test-all"},
+				{Kind: Test, Run: "go test ./...", Dir: "api/internal"},
+			},
+		},
+	}}
+
+	if got, want := p.Runs(Test), []string{"(cd api && go test ./...)", "make test-all", "(cd api/internal && go test ./...)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Runs(Test) = %v, want %v", got, want)
+	}
+	if got := p.Runs(Build); got != nil {
+		t.Errorf("Runs(Build) = %v, want nil", got)
+	}
+	if got, want := p.Environment(), []string{"CI=true", "actions/setup-go (go-version: 1.22)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Environment() = %v, want %v", got, want)
+	}
+
+	api := p.For("api")
+	if got, want := api.Runs(Test), []string{"go test ./...", "(cd internal && go test ./...)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("For(api).Runs(Test) = %v, want %v relative to api", got, want)
+	}
+	if len(api.Jobs) != 2 || len(api.Jobs[1].Commands) != 1 {
+		t.Errorf("For(api) = %+v, want both jobs with only api commands", api)
+	}
+	if web := p.For("web"); len(web.Jobs) != 0 {
+		t.Errorf("For(web) = %+v, want no jobs", web)
+	}
+	if root := p.For("."); !reflect.DeepEqual(root, p) {
+		t.Errorf("For(.) should return the whole pipeline")
+	}
+
+	want := []string{
+		"(cd api && go test ./...)           # test, ci.yml: api",
+		"make test-all                       # test, ci.yml: all",
+		"(cd api/internal && go test ./...)  # test, ci.yml: all",
+		"(cd api && golangci-lint run)       # lint, ci.yml: api",
+	}
+	if got := p.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".github/workflows/ci.yml": "jobs:\n  test:\n    steps:\n      - run: make test\n  release:\n    steps:\n      - run: echo release\n",
+		".gitlab-ci.yml":           "lint:\n  script: make lint\n",
+	})
+
+	p := Parse(root)
+	var names []string
+	for _, job := range p.Jobs {
+		names = append(names, job.Name)
+	}
+	if want := []string{"ci.yml: test", ".gitlab-ci.yml: lint"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Parse() jobs = %v, want %v (jobs without commands dropped)", names, want)
+	}
+
+	if p := Parse(t.TempDir()); p.Jobs != nil {
+		t.Errorf("Parse() without CI = %+v, want no jobs", p)
+	}
+}
+
+func TestParse_TestCommandsOnly(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".github/workflows/ci.yml": `jobs:
+  test:
+    steps:
+      - run: go test -race ./... -tags ${{ matrix.tags }}
+      - run: docker build -t test .
+      - run: go test ./...
+      - run: echo "all tests passed"
+`,
+	})
+
+	if got, want := Parse(root).Runs(Test), []string{"go test ./..."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Runs(Test) = %v, want %v", got, want)
+	}
+}
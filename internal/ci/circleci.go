@@ -0,0 +1,74 @@
+package ci
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// circleJob is a CircleCI job
+type circleJob struct {
+	Docker []struct {
+		Image string `yaml:"image"`
+	} `yaml:"docker"`
+	Machine     yaml.Node         `yaml:"machine"`
+	Environment map[string]string `yaml:"environment"`
+	Steps       []yaml.Node       `yaml:"steps"`
+}
+
+// parseCircleCI reads the jobs of .circleci/config.yml. Orb commands and
+// reusable commands are not expanded.
+func parseCircleCI(root string) []Job {
+	var config struct {
+		Jobs yaml.Node `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(readFile(root, ".circleci/config.yml"), &config); err != nil || config.Jobs.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var jobs []Job
+	nodes := config.Jobs.Content
+	for i := 0; i+1 < len(nodes); i += 2 {
+		var cj circleJob
+		nodes[i+1].Decode(&cj)
+
+		job := Job{Name: fmt.Sprintf("circleci: %s", nodes[i].Value)}
+		for _, step := range cj.Steps {
+			job.Commands = append(job.Commands, commands(circleRun(step), "")...)
+		}
+		for _, d := range cj.Docker {
+			if d.Image != "" {
+				job.Environment = append(job.Environment, "image "+d.Image)
+			}
+		}
+		if cj.Machine.Kind != 0 {
+			job.Environment = append(job.Environment, "machine executor")
+		}
+		job.Environment = append(job.Environment, envEntries(cj.Environment)...)
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// circleRun returns the command of a run step, which is either
+// "run: cmd" or "run: {command: cmd}"; other steps have none
+func circleRun(step yaml.Node) string {
+	var s struct {
+		Run yaml.Node `yaml:"run"`
+	}
+	if step.Kind != yaml.MappingNode || step.Decode(&s) != nil {
+		return ""
+	}
+	switch s.Run.Kind {
+	case yaml.ScalarNode:
+		return s.Run.Value
+	case yaml.MappingNode:
+		var run struct {
+			Command string `yaml:"command"`
+		}
+		if s.Run.Decode(&run) == nil {
+			return run.Command
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package ci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCircleCI(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{".circleci/config.yml": `version: 2.1
+jobs:
+  build:
+    docker:
+      - image: cimg/node:20.11
+    environment:
+      NODE_ENV: test
+    steps:
+      - checkout
+      - run: npm ci
+      - run:
+          name: Tests
+          command: npm test -- --ci
+      - save_cache:
+          key: deps
+workflows:
+  main:
+    jobs: [build]
+`})
+
+	want := []Job{{
+		Name:        "circleci: build",
+		Environment: []string{"image cimg/node:20.11", "NODE_ENV=test"},
+		Commands: []Command{
+			{Kind: Setup, Run: "npm ci"},
+			{Kind: Test, Run: "npm test -- --ci"},
+		},
+	}}
+	if got := parseCircleCI(root); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCircleCI() =\n%+v\nwant\n%+v", got, want)
+	}
+}
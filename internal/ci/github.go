@@ -0,0 +1,171 @@
+package ci
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// githubWorkflow is the subset of a GitHub Actions workflow that
+// describes how jobs run
+type githubWorkflow struct {
+	Env      map[string]string `yaml:"env"`
+	Defaults githubDefaults    `yaml:"defaults"`
+	Jobs     yaml.Node         `yaml:"jobs"`
+}
+
+// githubDefaults holds the default working directory of run steps
+type githubDefaults struct {
+	Run struct {
+		WorkingDirectory string `yaml:"working-directory"`
+	} `yaml:"run"`
+}
+
+// githubJob is a workflow job
+type githubJob struct {
+	Env       map[string]string    `yaml:"env"`
+	Defaults  githubDefaults       `yaml:"defaults"`
+	Container yaml.Node            `yaml:"container"`
+	Services  map[string]yaml.Node `yaml:"services"`
+	Steps     []struct {
+		Uses             string            `yaml:"uses"`
+		Run              string            `yaml:"run"`
+		With             map[string]string `yaml:"with"`
+		WorkingDirectory string            `yaml:"working-directory"`
+	} `yaml:"steps"`
+}
+
+// parseGitHub reads the workflows in .github/workflows
+func parseGitHub(root string) []Job {
+	var paths []string
+	for _, pattern := range []string{"*.yml", "*.yaml"} {
+		matches, _ := filepath.Glob(filepath.Join(root, ".github", "workflows", pattern))
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var jobs []Job
+	for _, p := range paths {
+		content, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		var wf githubWorkflow
+		if err := yaml.Unmarshal(content, &wf); err != nil || wf.Jobs.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(wf.Jobs.Content); i += 2 {
+			var gj githubJob
+			if err := wf.Jobs.Content[i+1].Decode(&gj); err != nil {
+				continue
+			}
+			jobs = append(jobs, githubToJob(filepath.Base(p), wf.Jobs.Content[i].Value, wf, gj))
+		}
+	}
+	return jobs
+}
+
+// githubToJob converts a workflow job
+func githubToJob(file, id string, wf githubWorkflow, gj githubJob) Job {
+	job := Job{Name: fmt.Sprintf("%s: %s", file, id)}
+
+	defaultDir := joinDir("", wf.Defaults.Run.WorkingDirectory)
+	if dir := gj.Defaults.Run.WorkingDirectory; dir != "" {
+		defaultDir = joinDir("", dir)
+	}
+
+	for _, step := range gj.Steps {
+		if step.Uses != "" {
+			if env := githubSetupAction(step.Uses, step.With); env != "" {
+				job.Environment = append(job.Environment, env)
+			}
+			continue
+		}
+		dir := defaultDir
+		if step.WorkingDirectory != "" {
+			dir = joinDir("", step.WorkingDirectory)
+		}
+		job.Commands = append(job.Commands, commands(step.Run, dir)...)
+	}
+
+	if image := githubImage(gj.Container); image != "" {
+		job.Environment = append(job.Environment, "container "+image)
+	}
+	for _, name := range sortedKeys(gj.Services) {
+		if image := githubImage(gj.Services[name]); image != "" {
+			job.Environment = append(job.Environment, fmt.Sprintf("service %s (%s)", name, image))
+		}
+	}
+	job.Environment = append(job.Environment, envEntries(wf.Env)...)
+	job.Environment = append(job.Environment, envEntries(gj.Env)...)
+	return job
+}
+
+// githubSetupAction describes a toolchain setup action with its version
+// inputs, e.g. "actions/setup-go (go-version: 1.22)"; other actions are
+// not part of the environment
+func githubSetupAction(uses string, with map[string]string) string {
+	action, _, _ := strings.Cut(uses, "@")
+	if !strings.Contains(path.Base(action), "setup") {
+		return ""
+	}
+
+	var inputs []string
+	for _, key := range sortedKeys(with) {
+		value := with[key]
+		if strings.Contains(value, "${{") {
+			continue
+		}
+		if strings.Contains(key, "version") || key == "distribution" || key == "cache" {
+			inputs = append(inputs, fmt.Sprintf("%s: %s", key, value))
+		}
+	}
+	if len(inputs) == 0 {
+		return action
+	}
+	return fmt.Sprintf("%s (%s)", action, strings.Join(inputs, ", "))
+}
+
+// githubImage returns the image of a container or service, which is
+// either a string or a mapping with an image key
+func githubImage(node yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.MappingNode:
+		var c struct {
+			Image string `yaml:"image"`
+		}
+		if node.Decode(&c) == nil {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+// envEntries formats environment variables as sorted KEY=value entries,
+// leaving out secrets
+func envEntries(env map[string]string) []string {
+	var entries []string
+	for _, key := range sortedKeys(env) {
+		if value := env[key]; !strings.Contains(value, "secrets.") && !strings.Contains(value, "$") {
+			entries = append(entries, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	return entries
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package ci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGitHub(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".github/workflows/ci.yml": `name: CI
+on: [push]
+env:
+  GOFLAGS: -mod=readonly
+  TOKEN: ${{ secrets.TOKEN }}
+defaults:
+  run:
+    working-directory: backend
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    services:
+      db:
+        image: postgres:16
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+        with:
+          go-version: "1.22"
+          check-latest: true
+      - run: go mod download
+      - name: Test
+        run: |
+          go vet ./...
+          go test -race ./...
+  web:
+    container: node:20
+    defaults:
+      run:
+        working-directory: frontend
+    steps:
+      - uses: pnpm/action-setup@v4
+        with:
+          version: ${{ matrix.pnpm }}
+      - run: pnpm install --frozen-lockfile
+      - run: pnpm build
+        working-directory: frontend/app
+`,
+		".github/workflows/broken.yaml": "jobs: [",
+	})
+
+	jobs := parseGitHub(root)
+	want := []Job{
+		{
+			Name: "ci.yml: test",
+			Environment: []string{
+				"actions/setup-go (go-version: 1.22)",
+				"service db (postgres:16)",
+				"GOFLAGS=-mod=readonly",
+			},
+			Commands: []Command{
+				{Kind: Setup, Run: "go mod download", Dir: "backend"},
+				{Kind: Lint, Run: "go vet ./...", Dir: "backend"},
+				{Kind: Test, Run: "go test -race ./...", Dir: "backend"},
+			},
+		},
+		{
+			Name:        "ci.yml: web",
+			Environment: []string{"pnpm/action-setup", "container node:20", "GOFLAGS=-mod=readonly"},
+			Commands: []Command{
+				{Kind: Setup, Run: "pnpm install --frozen-lockfile", Dir: "frontend"},
+				{Kind: Build, Run: "pnpm build", Dir: "frontend/app"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(jobs, want) {
+		t.Errorf("parseGitHub() =\n%+v\nwant\n%+v", jobs, want)
+	}
+}
@@ -0,0 +1,134 @@
+package ci
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// gitlabKeywords are the top-level keys of .gitlab-ci.yml that are not
+// jobs
+var gitlabKeywords = map[string]bool{
+	"stages": true, "variables": true, "image": true, "services": true,
+	"default": true, "include": true, "workflow": true, "cache": true,
+	"before_script": true, "after_script": true,
+}
+
+// gitlabJob is a job, or the default section, of .gitlab-ci.yml
+type gitlabJob struct {
+	Image        yaml.Node         `yaml:"image"`
+	Services     []yaml.Node       `yaml:"services"`
+	Variables    map[string]string `yaml:"variables"`
+	BeforeScript yaml.Node         `yaml:"before_script"`
+	Script       yaml.Node         `yaml:"script"`
+}
+
+// parseGitLab reads the jobs of .gitlab-ci.yml. Global and default
+// images, variables and before_script apply to every job.
+func parseGitLab(root string) []Job {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(readFile(root, ".gitlab-ci.yml"), &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	top := doc.Content[0]
+	if top.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	// Decoding errors keep the fields that did decode, which is enough
+	// for jobs with unusual variables
+	var global struct {
+		gitlabJob `yaml:",inline"`
+		Default   gitlabJob `yaml:"default"`
+	}
+	top.Decode(&global)
+	defaults := global.Default
+
+	var jobs []Job
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		name := top.Content[i].Value
+		// Hidden jobs are templates for extends
+		if gitlabKeywords[name] || strings.HasPrefix(name, ".") {
+			continue
+		}
+		var gj gitlabJob
+		top.Content[i+1].Decode(&gj)
+		if gj.Script.Kind == 0 {
+			continue
+		}
+
+		job := Job{Name: fmt.Sprintf(".gitlab-ci.yml: %s", name)}
+		before := gj.BeforeScript
+		if before.Kind == 0 {
+			before = first(defaults.BeforeScript, global.BeforeScript)
+		}
+		job.Commands = append(job.Commands, commands(scriptText(before), "")...)
+		job.Commands = append(job.Commands, commands(scriptText(gj.Script), "")...)
+
+		if image := gitlabImage(first(gj.Image, defaults.Image, global.Image)); image != "" {
+			job.Environment = append(job.Environment, "image "+image)
+		}
+		services := gj.Services
+		for _, fallback := range [][]yaml.Node{defaults.Services, global.Services} {
+			if services == nil {
+				services = fallback
+			}
+		}
+		for _, s := range services {
+			if image := gitlabImage(s); image != "" {
+				job.Environment = append(job.Environment, "service "+image)
+			}
+		}
+		job.Environment = append(job.Environment, envEntries(global.Variables)...)
+		job.Environment = append(job.Environment, envEntries(gj.Variables)...)
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// first returns the first node that is set
+func first(nodes ...yaml.Node) yaml.Node {
+	for _, n := range nodes {
+		if n.Kind != 0 {
+			return n
+		}
+	}
+	return yaml.Node{}
+}
+
+// scriptText joins a script given as a string or a list of lines; nested
+// lists from YAML anchors are flattened
+func scriptText(node yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.SequenceNode:
+		var lines []string
+		for _, item := range node.Content {
+			lines = append(lines, scriptText(*item))
+		}
+		return strings.Join(lines, "\n")
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			return scriptText(*node.Alias)
+		}
+	}
+	return ""
+}
+
+// gitlabImage returns an image given as a string or with a name key
+func gitlabImage(node yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.MappingNode:
+		var image struct {
+			Name string `yaml:"name"`
+		}
+		if node.Decode(&image) == nil {
+			return image.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package ci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGitLab(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{".gitlab-ci.yml": `image: python:3.12
+variables:
+  PIP_CACHE_DIR: .cache/pip
+stages: [test]
+default:
+  before_script:
+    - pip install -r requirements.txt
+.template: &lint
+  script:
+    - ruff check .
+lint:
+  <<: *lint
+test:
+  services:
+    - name: redis:7
+  variables:
+    DEPLOY_KEY: $SECRET
+  script:
+    - cd app
+    - pytest -q
+deploy:
+  script: ./deploy.sh
+`})
+
+	jobs := parseGitLab(root)
+	want := []Job{
+		{
+			Name:        ".gitlab-ci.yml: lint",
+			Environment: []string{"image python:3.12", "PIP_CACHE_DIR=.cache/pip"},
+			Commands: []Command{
+				{Kind: Setup, Run: "pip install -r requirements.txt"},
+				{Kind: Lint, Run: "ruff check ."},
+			},
+		},
+		{
+			Name:        ".gitlab-ci.yml: test",
+			Environment: []string{"image python:3.12", "service redis:7", "PIP_CACHE_DIR=.cache/pip"},
+			Commands: []Command{
+				{Kind: Setup, Run: "pip install -r requirements.txt"},
+				{Kind: Test, Run: "pytest -q", Dir: "app"},
+			},
+		},
+		{
+			Name:        ".gitlab-ci.yml: deploy",
+			Environment: []string{"image python:3.12", "PIP_CACHE_DIR=.cache/pip"},
+			Commands:    []Command{{Kind: Setup, Run: "pip install -r requirements.txt"}},
+		},
+	}
+	if !reflect.DeepEqual(jobs, want) {
+		t.Errorf("parseGitLab() =\n%+v\nwant\n%+v", jobs, want)
+	}
+}
@@ -0,0 +1,101 @@
+package ci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// jenkinsStage matches a stage header, e.g. stage('Test') {
+	jenkinsStage = regexp.MustCompile(`\bstage\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	// jenkinsShell matches sh and bat steps with a quoted script; the
+	// triple-quoted forms may span lines
+	jenkinsShell = regexp.MustCompile(`(?s)\b(?:sh|bat)\s*\(?\s*(?:script:\s*)?('''(.*?)'''|"""(.*?)"""|'([^'\n]*)'|"([^"\n]*)")`)
+	// jenkinsImage matches a docker agent image
+	jenkinsImage = regexp.MustCompile(`\bimage\s+['"]([^'"]+)['"]`)
+	// jenkinsDir matches a dir('path') block
+	jenkinsDir = regexp.MustCompile(`\bdir\s*\(\s*['"]([^'"]+)['"]\s*\)\s*\{`)
+)
+
+// parseJenkinsfile reads the shell steps of a declarative Jenkinsfile,
+// one job per stage. Steps inside dir('x') blocks run in x.
+func parseJenkinsfile(root string) []Job {
+	content := string(readFile(root, "Jenkinsfile"))
+	if content == "" {
+		return nil
+	}
+
+	var env []string
+	for _, m := range jenkinsImage.FindAllStringSubmatch(content, -1) {
+		env = append(env, "image "+m[1])
+	}
+
+	// Split the file at stage headers; steps before the first stage
+	// belong to no stage
+	stages := jenkinsStage.FindAllStringSubmatchIndex(content, -1)
+	var jobs []Job
+	for i, loc := range stages {
+		end := len(content)
+		if i+1 < len(stages) {
+			end = stages[i+1][0]
+		}
+		name := content[loc[2]:loc[3]]
+		job := Job{Name: fmt.Sprintf("Jenkinsfile: %s", name), Environment: env}
+		job.Commands = jenkinsCommands(content[loc[1]:end])
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// jenkinsCommands returns the classified shell steps of a stage body
+func jenkinsCommands(body string) []Command {
+	// Directory of each dir block, by the offset of its opening brace
+	type block struct {
+		start, end int
+		dir        string
+	}
+	var blocks []block
+	for _, loc := range jenkinsDir.FindAllStringSubmatchIndex(body, -1) {
+		if end := matchingBrace(body, loc[1]-1); end > 0 {
+			blocks = append(blocks, block{loc[1], end, body[loc[2]:loc[3]]})
+		}
+	}
+
+	var cmds []Command
+	for _, loc := range jenkinsShell.FindAllStringSubmatchIndex(body, -1) {
+		script := ""
+		for g := 2; g <= 5; g++ {
+			if start := loc[2*g]; start >= 0 {
+				script = body[start:loc[2*g+1]]
+				break
+			}
+		}
+		dir := ""
+		for _, b := range blocks {
+			if loc[0] > b.start && loc[0] < b.end {
+				dir = joinDir(dir, b.dir)
+			}
+		}
+		cmds = append(cmds, commands(strings.TrimSpace(script), dir)...)
+	}
+	return cmds
+}
+
+// matchingBrace returns the offset of the brace closing the one at open,
+// or -1
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
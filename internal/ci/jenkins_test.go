@@ -0,0 +1,49 @@
+package ci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJenkinsfile(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"Jenkinsfile": `pipeline {
+    agent { docker { image 'maven:3.9-eclipse-temurin-21' } }
+    stages {
+        stage('Build') {
+            steps {
+                sh './mvnw -B package -DskipTests'
+            }
+        }
+        stage('Test') {
+            steps {
+                dir('service') {
+                    sh """
+                        ./mvnw -B verify
+                    """
+                }
+                sh(script: "./mvnw checkstyle:check")
+            }
+        }
+        stage('Deploy') {
+            steps {
+                sh 'kubectl apply -f k8s/'
+            }
+        }
+    }
+}
+`})
+
+	env := []string{"image maven:3.9-eclipse-temurin-21"}
+	want := []Job{
+		{Name: "Jenkinsfile: Build", Environment: env, Commands: []Command{{Kind: Build, Run: "./mvnw -B package -DskipTests"}}},
+		{Name: "Jenkinsfile: Test", Environment: env, Commands: []Command{
+			{Kind: Test, Run: "./mvnw -B verify", Dir: "service"},
+			{Kind: Lint, Run: "./mvnw checkstyle:check"},
+		}},
+		{Name: "Jenkinsfile: Deploy", Environment: env},
+	}
+	if got := parseJenkinsfile(root); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseJenkinsfile() =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/affected"
+	"github.com/Shaked/agentic-repo/internal/arch"
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBase string
+	flagKind string
+)
+
+var affectedCmd = &cobra.Command{
+	Use:   "affected [directory]",
+	Short: "List the projects a change affects, with their context and test commands",
+	Long: `List the projects affected by the changes since a base ref.
+
+The changed files are read from the local git repository: everything that
+differs from the merge base of --base and HEAD, including uncommitted and
+untracked files. Each file belongs to the deepest detected project that
+contains it; projects that depend on a changed project, as declared in
+their go.mod, package.json, pom.xml, Gradle build or pyproject.toml, are
+affected too.
+
+For every affected project the AGENTS.md and .agent/ files to read and the
+commands that run its tests are printed. Use --format paths to print only
+the project directories, one per line, or --format json for other
+tooling.
+
+--format commands prints one shell line per affected project that runs
+its tests, or its linter with --kind lint, in the project directory: the
+commands its AGENTS.md lists, else the stack's defaults. The test-affected
+and lint-affected targets of the generated monorepo Makefile run them.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAffected,
+}
+
+func init() {
+	affectedCmd.Flags().StringVar(&flagBase, "base", "main", "Git ref to compare against")
+	affectedCmd.Flags().StringVarP(&flagFormat, "format", "o", "table", "Output format: table, json, paths or commands")
+	affectedCmd.Flags().StringVar(&flagKind, "kind", "test", "Commands printed by --format commands: test or lint")
+	affectedCmd.Flags().BoolVar(&flagHooks, "hooks", false, "Run the pre_detect hooks configured in .agentic-repo.yaml first")
+	addScanFlags(affectedCmd)
+}
+
+// affectedReport is the machine-readable output of affected
+type affectedReport struct {
+	Base     string            `json:"base"`
+	Projects []affectedProject `json:"projects"`
+	// Unowned lists changed files outside every project
+	Unowned []string `json:"unowned_files"`
+}
+
+// affectedProject is an affected project with what an agent needs to
+// work on it; paths are relative to the repository root
+type affectedProject struct {
+	Path         string             `json:"path"`
+	Stack        detector.StackType `json:"stack"`
+	ChangedFiles []string           `json:"changed_files"`
+	// DependsOn lists the changed projects this one depends on
+	DependsOn    []string `json:"depends_on"`
+	Context      []string `json:"context"`
+	TestCommands []string `json:"test_commands"`
+	LintCommands []string `json:"lint_commands"`
+}
+
+func runAffected(cmd *cobra.Command, args []string) error {
+	absPath, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+
+	switch flagFormat {
+	case "table", "json", "paths", "commands":
+	default:
+		return fmt.Errorf("unknown format %q (want table, json, paths or commands)", flagFormat)
+	}
+	if flagKind != "test" && flagKind != "lint" {
+		return fmt.Errorf("unknown kind %q (want test or lint)", flagKind)
+	}
+
+	cfg, err := config.Load(absPath)
+	if err != nil {
+		return err
+	}
+
+	results, err := scanRepo(cmd, cfg, absPath, flagHooks)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		results = unknownResults(absPath)
+	}
+
+	changed, err := affected.ChangedFiles(commandContext(cmd), absPath, flagBase)
+	if err != nil {
+		return err
+	}
+
+	report := buildAffectedReport(absPath, results, changed)
+	out := cmd.OutOrStdout()
+	switch flagFormat {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "paths":
+		for _, p := range report.Projects {
+			fmt.Fprintln(out, p.Path)
+		}
+		return nil
+	case "commands":
+		for _, line := range affectedCommands(report, flagKind) {
+			fmt.Fprintln(out, line)
+		}
+		return nil
+	default:
+		printAffected(out, report)
+		return nil
+	}
+}
+
+// buildAffectedReport matches the changed files to the detected projects
+func buildAffectedReport(root string, results []detector.Result, changed []string) affectedReport {
+	byDir := map[string]detector.Result{}
+	dirs := make([]string, 0, len(results))
+	for _, r := range results {
+		dir := relPath(root, r.Path)
+		byDir[dir] = r
+		dirs = append(dirs, dir)
+	}
+
+	match := affected.Match(changed, dirs, arch.LoadProjects(root, dirs))
+	report := affectedReport{Base: flagBase, Projects: []affectedProject{}, Unowned: match.Unowned}
+	if report.Unowned == nil {
+		report.Unowned = []string{}
+	}
+	projects := make([]detector.Result, 0, len(match.Projects))
+	for _, p := range match.Projects {
+		projects = append(projects, byDir[p.Dir])
+	}
+	cmds := generator.ProjectCommands(root, projects)
+	for i, p := range match.Projects {
+		report.Projects = append(report.Projects, affectedProject{
+			Path:         p.Dir,
+			Stack:        projects[i].Stack,
+			ChangedFiles: orEmpty(p.Changed),
+			DependsOn:    orEmpty(p.Via),
+			Context:      contextFiles(root, p.Dir),
+			TestCommands: nonEmpty(cmds[i].Test),
+			LintCommands: nonEmpty(cmds[i].Lint),
+		})
+	}
+	return report
+}
+
+// affectedCommands returns a shell line per affected project that runs
+// its test or lint commands in a subshell inside the project directory
+func affectedCommands(report affectedReport, kind string) []string {
+	lines := make([]string, 0, len(report.Projects))
+	for _, p := range report.Projects {
+		runs := p.TestCommands
+		if kind == "lint" {
+			runs = p.LintCommands
+		}
+		for _, run := range runs {
+			lines = append(lines, fmt.Sprintf("(cd %s && %s)", shellQuote(p.Path), run))
+		}
+	}
+	return lines
+}
+
+// shellSafe matches the words sh reads the same quoted or not
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s as a single sh word
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// contextFiles lists the AGENTS.md and .agent/ Markdown files of the
+// project at dir that exist, root-relative
+func contextFiles(root, dir string) []string {
+	files := []string{}
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), "AGENTS.md")); err == nil {
+		files = append(files, path.Join(dir, "AGENTS.md"))
+	}
+	matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(dir), ".agent", "*.md"))
+	sort.Strings(matches)
+	for _, m := range matches {
+		files = append(files, path.Join(dir, ".agent", filepath.Base(m)))
+	}
+	return files
+}
+
+// printAffected prints the report for humans
+func printAffected(w io.Writer, report affectedReport) {
+	if len(report.Projects) == 0 {
+		color.New(color.FgGreen).Fprintf(w, "✓ No projects affected since %s\n", report.Base)
+	} else {
+		color.New(color.FgMagenta).Fprintf(w, "🎯 %d %s affected since %s\n", len(report.Projects), plural(len(report.Projects), "project", "projects"), report.Base)
+	}
+
+	for _, p := range report.Projects {
+		reason := fmt.Sprintf("%d changed %s", len(p.ChangedFiles), plural(len(p.ChangedFiles), "file", "files"))
+		if len(p.ChangedFiles) == 0 {
+			reason = "depends on " + strings.Join(p.DependsOn, ", ")
+		}
+		fmt.Fprintf(w, "   • %s (%s) — %s\n", p.Path, p.Stack, reason)
+		if len(p.Context) > 0 {
+			fmt.Fprintf(w, "       read: %s\n", strings.Join(p.Context, ", "))
+		}
+		for _, run := range p.TestCommands {
+			fmt.Fprintf(w, "       test: %s\n", run)
+		}
+	}
+
+	if len(report.Unowned) > 0 {
+		color.New(color.FgYellow).Fprintf(w, "⚠️  %d changed %s outside every project: %s\n",
+			len(report.Unowned), plural(len(report.Unowned), "file", "files"), strings.Join(report.Unowned, ", "))
+	}
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// nonEmpty returns a slice holding run, or an empty one if run is empty
+func nonEmpty(run string) []string {
+	if run == "" {
+		return []string{}
+	}
+	return []string{run}
+}
+
+// orEmpty turns nil into an empty slice so JSON shows [] rather than null
+func orEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
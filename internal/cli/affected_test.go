@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAffectedCmd_Flags(t *testing.T) {
+	for _, name := range []string{"base", "format", "kind", "max-depth", "include", "exclude", "hooks"} {
+		if affectedCmd.Flags().Lookup(name) == nil {
+			t.Errorf("flag --%s not found", name)
+		}
+	}
+}
+
+// gitRepo creates a repository whose main branch holds files
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, files)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "-A"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false", "commit", "-q", "-m", "base"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+// writeRepoFiles creates files relative to dir
+func writeRepoFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunAffected(t *testing.T) {
+	dir := gitRepo(t, map[string]string{
+		"libs/core/go.mod":      "module example.com/core\n",
+		"libs/core/Makefile":    "test: ## Run tests\n\tgo test ./...\n",
+		"libs/core/AGENTS.md":   "# core\n",
+		"libs/core/.agent/x.md": "x\n",
+		"api/go.mod":            "module example.com/api\n\nrequire example.com/core v0.0.0\n",
+		"web/package.json":      `{"name": "web", "scripts": {"test": "vitest"}}`,
+	})
+	writeRepoFiles(t, dir, map[string]string{
+		"libs/core/core.go": "package core\n",
+		"README.md":         "# Repo\n",
+	})
+
+	var out bytes.Buffer
+	affectedCmd.SetOut(&out)
+	defer affectedCmd.SetOut(nil)
+	flagFormat = "json"
+	defer func() { flagFormat = "table" }()
+
+	if err := runAffected(affectedCmd, []string{dir}); err != nil {
+		t.Fatalf("runAffected() error = %v", err)
+	}
+	var report affectedReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	want := affectedReport{
+		Base: "main",
+		Projects: []affectedProject{
+			{Path: "api", Stack: "go", ChangedFiles: []string{}, DependsOn: []string{"libs/core"}, Context: []string{}, TestCommands: []string{"go test ./..."}, LintCommands: []string{"golangci-lint run"}},
+			{Path: "libs/core", Stack: "go", ChangedFiles: []string{"libs/core/core.go"}, DependsOn: []string{}, Context: []string{"libs/core/AGENTS.md", "libs/core/.agent/x.md"}, TestCommands: []string{"make test"}, LintCommands: []string{"golangci-lint run"}},
+		},
+		Unowned: []string{"README.md"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("report =\n%+v\nwant\n%+v", report, want)
+	}
+
+	out.Reset()
+	flagFormat = "paths"
+	if err := runAffected(affectedCmd, []string{dir}); err != nil {
+		t.Fatalf("runAffected() error = %v", err)
+	}
+	if got := out.String(); got != "api\nlibs/core\n" {
+		t.Errorf("paths output = %q", got)
+	}
+
+	// The commands the docs list, else the stack's defaults
+	for kind, want := range map[string]string{
+		"test": "(cd api && go test ./...)\n(cd libs/core && make test)\n",
+		"lint": "(cd api && golangci-lint run)\n(cd libs/core && golangci-lint run)\n",
+	} {
+		out.Reset()
+		flagFormat, flagKind = "commands", kind
+		if err := runAffected(affectedCmd, []string{dir}); err != nil {
+			t.Fatalf("runAffected() error = %v", err)
+		}
+		if got := out.String(); got != want {
+			t.Errorf("commands output for --kind %s = %q, want %q", kind, got, want)
+		}
+	}
+	flagKind = "test"
+
+	out.Reset()
+	flagFormat = "table"
+	if err := runAffected(affectedCmd, []string{dir}); err != nil {
+		t.Fatalf("runAffected() error = %v", err)
+	}
+	for _, want := range []string{"2 projects affected since main", "api (go) — depends on libs/core", "test: go test ./...", "test: make test", "1 changed file outside every project: README.md"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("table output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunAffected_Errors(t *testing.T) {
+	dir := gitRepo(t, map[string]string{"go.mod": "module example.com/x\n"})
+
+	flagBase = "no-such-ref"
+	defer func() { flagBase = "main" }()
+	if err := runAffected(affectedCmd, []string{dir}); err == nil {
+		t.Error("runAffected() with an unknown base should fail")
+	}
+
+	flagBase = "main"
+	flagFormat = "xml"
+	defer func() { flagFormat = "table" }()
+	if err := runAffected(affectedCmd, []string{dir}); err == nil {
+		t.Error("runAffected() with an unknown format should fail")
+	}
+
+	flagFormat, flagKind = "commands", "build"
+	defer func() { flagKind = "test" }()
+	if err := runAffected(affectedCmd, []string{dir}); err == nil {
+		t.Error("runAffected() with an unknown kind should fail")
+	}
+}
+
+func TestAffectedCommands(t *testing.T) {
+	report := affectedReport{Projects: []affectedProject{
+		{Path: "services/my api", TestCommands: []string{"go test ./..."}, LintCommands: []string{}},
+		{Path: "it's", TestCommands: []string{"make test"}, LintCommands: []string{"make lint"}},
+	}}
+
+	want := []string{`(cd 'services/my api' && go test ./...)`, `(cd 'it'\''s' && make test)`}
+	if got := affectedCommands(report, "test"); !reflect.DeepEqual(got, want) {
+		t.Errorf("affectedCommands(test) = %q, want %q", got, want)
+	}
+	// Projects without a lint command are left out
+	want = []string{`(cd 'it'\''s' && make lint)`}
+	if got := affectedCommands(report, "lint"); !reflect.DeepEqual(got, want) {
+		t.Errorf("affectedCommands(lint) = %q, want %q", got, want)
+	}
+}
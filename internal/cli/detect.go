@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	flagExplain bool
+	flagFormat  string
+)
+
+var detectCmd = &cobra.Command{
+	Use:   "detect [directory]",
+	Short: "Detect project stacks without generating files",
+	Long: `Detect the project stacks in a repository without writing anything.
+
+Each detected project is listed with its stack. With --explain, the
+confidence score, the files that led to the decision and any other
+stacks that also matched are printed as well.
+
+Use --format json or --format yaml to feed the result into other tooling
+(CI matrix generation, ownership dashboards). The document has a
+schema_version field and follows the schema documented in USAGE.md.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDetect,
+}
+
+func init() {
+	detectCmd.Flags().BoolVar(&flagExplain, "explain", false, "Show confidence and evidence for each detection")
+	detectCmd.Flags().StringVarP(&flagFormat, "format", "o", "table", "Output format: table, json or yaml")
+	detectCmd.Flags().BoolVar(&flagHooks, "hooks", false, "Run the pre_detect hooks configured in .agentic-repo.yaml first")
+	addScanFlags(detectCmd)
+}
+
+func runDetect(cmd *cobra.Command, args []string) error {
+	absPath, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(absPath)
+	if err != nil {
+		return err
+	}
+
+	results, err := scanRepo(cmd, cfg, absPath, flagHooks)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	switch flagFormat {
+	case "table":
+		printDetectTable(out, absPath, results)
+		return nil
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(detector.NewReport(absPath, results))
+	case "yaml":
+		return writeYAML(out, detector.NewReport(absPath, results))
+	default:
+		return fmt.Errorf("unknown format %q (want table, json or yaml)", flagFormat)
+	}
+}
+
+// printDetectTable prints results for humans
+func printDetectTable(w io.Writer, root string, results []detector.Result) {
+	if len(results) == 0 {
+		color.New(color.FgYellow).Fprintln(w, "⚠️  No recognized project types found")
+		return
+	}
+
+	if detector.IsMonorepo(results) {
+		color.New(color.FgMagenta).Fprintln(w, "📦 Detected: Monorepo")
+	} else {
+		color.New(color.FgMagenta).Fprintln(w, "📦 Detected: Single project")
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(w, "   • %s: %s\n", relPath(root, r.Path), r.Stack)
+		if flagExplain {
+			printEvidence(w, r)
+		}
+	}
+}
+
+// writeYAML encodes v as YAML with two-space indentation
+func writeYAML(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// relPath returns path relative to root in slash form, "." for the root
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDetectCmd_Flags(t *testing.T) {
+	for _, name := range []string{"explain", "format", "max-depth", "include", "exclude", "no-ignore", "min-confidence", "hooks"} {
+		if detectCmd.Flags().Lookup(name) == nil {
+			t.Errorf("flag --%s not found", name)
+		}
+	}
+}
+
+func TestRunDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		explain bool
+		want    []string
+	}{
+		{"single project", []string{"go.mod"}, false, []string{"Single project", "• .: go"}},
+		{"monorepo with explain", []string{"api/go.mod", "web/package.json"}, true, []string{"Monorepo", "• api: go", "confidence 1.00: go.mod"}},
+		{"no projects", []string{"README.md"}, false, []string{"No recognized project types found"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, f := range tt.files {
+				path := filepath.Join(dir, f)
+				os.MkdirAll(filepath.Dir(path), 0755)
+				os.WriteFile(path, []byte{}, 0644)
+			}
+
+			flagExplain = tt.explain
+			defer func() { flagExplain = false }()
+			buf := new(bytes.Buffer)
+			detectCmd.SetOut(buf)
+			defer detectCmd.SetOut(nil)
+
+			if err := runDetect(detectCmd, []string{dir}); err != nil {
+				t.Fatalf("runDetect() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("table output missing %q:\n%s", want, buf.String())
+				}
+			}
+
+			// Detection must never write files
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != countTopLevel(tt.files) {
+				t.Errorf("detect wrote files: found %d entries", len(entries))
+			}
+		})
+	}
+}
+
+func TestRunDetect_NonExistentDirectory(t *testing.T) {
+	if err := runDetect(detectCmd, []string{"/nonexistent/path/that/does/not/exist"}); err == nil {
+		t.Error("expected error for non-existent directory")
+	}
+}
+
+func TestRelPath(t *testing.T) {
+	tests := []struct {
+		root, path, expected string
+	}{
+		{"/repo", "/repo", "."},
+		{"/repo", "/repo/services/api", "services/api"},
+	}
+	for _, tt := range tests {
+		if got := relPath(tt.root, tt.path); got != tt.expected {
+			t.Errorf("relPath(%q, %q) = %q, want %q", tt.root, tt.path, got, tt.expected)
+		}
+	}
+}
+
+// countTopLevel counts the distinct top-level entries of relative paths
+func countTopLevel(files []string) int {
+	seen := map[string]bool{}
+	for _, f := range files {
+		top, _, _ := strings.Cut(f, "/")
+		seen[top] = true
+	}
+	return len(seen)
+}
+
+func TestRunDetect_Formats(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.MkdirAll(filepath.Join(dir, "web"), 0755)
+	os.WriteFile(filepath.Join(dir, "api", "go.mod"), []byte("module api\n\ngo 1.22\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "package.json"), []byte("{}"), 0644)
+	os.WriteFile(filepath.Join(dir, "web", "pnpm-lock.yaml"), []byte{}, 0644)
+
+	tests := []struct {
+		format    string
+		unmarshal func([]byte, any) error
+	}{
+		{"json", json.Unmarshal},
+		{"yaml", yaml.Unmarshal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			detectCmd.SetOut(buf)
+			defer detectCmd.SetOut(nil)
+
+			flagFormat = tt.format
+			defer func() { flagFormat = "table" }()
+
+			if err := runDetect(detectCmd, []string{dir}); err != nil {
+				t.Fatalf("runDetect() error = %v", err)
+			}
+
+			var report struct {
+				SchemaVersion int  `json:"schema_version" yaml:"schema_version"`
+				Monorepo      bool `json:"monorepo" yaml:"monorepo"`
+				Projects      []struct {
+					Path  string            `json:"path" yaml:"path"`
+					Stack string            `json:"stack" yaml:"stack"`
+					Tools map[string]string `json:"tools" yaml:"tools"`
+				} `json:"projects" yaml:"projects"`
+			}
+			if err := tt.unmarshal(buf.Bytes(), &report); err != nil {
+				t.Fatalf("failed to parse %s output: %v\n%s", tt.format, err, buf.String())
+			}
+
+			if report.SchemaVersion != 1 {
+				t.Errorf("schema_version = %d, want 1", report.SchemaVersion)
+			}
+			if !report.Monorepo {
+				t.Error("expected monorepo = true")
+			}
+			if len(report.Projects) != 2 {
+				t.Fatalf("got %d projects, want 2", len(report.Projects))
+			}
+			if report.Projects[0].Path != "api" || report.Projects[0].Stack != "go" {
+				t.Errorf("projects[0] = %+v, want api/go", report.Projects[0])
+			}
+			if report.Projects[1].Tools["package_manager"] != "pnpm" {
+				t.Errorf("projects[1].tools = %v, want package_manager pnpm", report.Projects[1].Tools)
+			}
+		})
+	}
+}
+
+func TestRunDetect_UnknownFormat(t *testing.T) {
+	dir := t.TempDir()
+
+	flagFormat = "xml"
+	defer func() { flagFormat = "table" }()
+
+	if err := runDetect(detectCmd, []string{dir}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestRunDetect_RepoConfig(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "api"), 0755)
+	os.WriteFile(filepath.Join(dir, "api", "WORKSPACE"), []byte{}, 0644)
+	os.WriteFile(filepath.Join(dir, ".agentic-repo.yaml"), []byte("detectors:\n  - stack: bazel\n    files: [WORKSPACE]\n"), 0644)
+
+	buf := new(bytes.Buffer)
+	detectCmd.SetOut(buf)
+	defer detectCmd.SetOut(nil)
+
+	flagFormat = "json"
+	defer func() { flagFormat = "table" }()
+
+	if err := runDetect(detectCmd, []string{dir}); err != nil {
+		t.Fatalf("runDetect() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"stack": "bazel"`) {
+		t.Errorf("expected declared bazel stack in output:\n%s", buf.String())
+	}
+}
+
+func TestRunDetect_InvalidRepoConfig(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".agentic-repo.yaml"), []byte("detectorz: []\n"), 0644)
+
+	if err := runDetect(detectCmd, []string{dir}); err == nil {
+		t.Error("expected error for invalid config")
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"path/filepath"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/hooks"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// flagNoHooks skips the hooks of init and sync; flagHooks runs the
+// pre_detect hooks of read-only commands, which never run them otherwise
+// so that inspecting an untrusted checkout runs none of its commands
+var (
+	flagNoHooks bool
+	flagHooks   bool
+)
+
+// runHooks runs the configured hooks for a stage unless --no-hooks is set.
+// written holds absolute paths; hooks see them relative to root. Hook
+// output goes to stderr so it never mixes with machine-readable output.
+func runHooks(cmd *cobra.Command, cfg *config.Config, stage hooks.Stage, root string, written []string) error {
+	list := cfg.StageHooks(stage)
+	if flagNoHooks || len(list) == 0 {
+		return nil
+	}
+
+	if flagDryRun {
+		for _, h := range list {
+			color.New(color.FgYellow).Printf("   ⏭  Would run %s hook: %s\n", stage, h.Run)
+		}
+		return nil
+	}
+
+	files := make([]string, 0, len(written))
+	for _, path := range written {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		files = append(files, rel)
+	}
+
+	return hooks.Run(commandContext(cmd), stage, list, hooks.Options{
+		Root:   root,
+		Files:  files,
+		Stdout: cmd.ErrOrStderr(),
+		Stderr: cmd.ErrOrStderr(),
+	})
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeHookConfig writes a .agentic-repo.yaml with the given hooks section
+func writeHookConfig(t *testing.T, dir, hooks string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell syntax")
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".agentic-repo.yaml"), []byte("hooks:\n"+hooks), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunInit_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	writeHookConfig(t, dir, `  pre_detect:
+    - run: touch pre.txt
+  post_generate:
+    - run: cat > written.txt
+`)
+
+	if err := runInit(initCmd, []string{dir}); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "pre.txt")); err != nil {
+		t.Error("pre_detect hook did not run")
+	}
+	written, err := os.ReadFile(filepath.Join(dir, "written.txt"))
+	if err != nil {
+		t.Fatalf("post_generate hook did not run: %v", err)
+	}
+	for _, want := range []string{"AGENTS.md\n", filepath.Join(".agent", "stack.md") + "\n"} {
+		if !strings.Contains(string(written), want) {
+			t.Errorf("written files = %q, want to contain %q", written, want)
+		}
+	}
+}
+
+func TestRunInit_NoHooksAndDryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		noHooks bool
+		dryRun  bool
+	}{
+		{"no-hooks", true, false},
+		{"dry-run", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeHookConfig(t, dir, "  pre_detect:\n    - run: touch pre.txt\n  post_generate:\n    - run: touch post.txt\n")
+
+			flagNoHooks, flagDryRun = tt.noHooks, tt.dryRun
+			defer func() { flagNoHooks, flagDryRun = false, false }()
+
+			if err := runInit(initCmd, []string{dir}); err != nil {
+				t.Fatalf("runInit() error = %v", err)
+			}
+			for _, name := range []string{"pre.txt", "post.txt"} {
+				if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+					t.Errorf("%s exists, hooks should not have run", name)
+				}
+			}
+		})
+	}
+}
+
+func TestRunInit_FailingHook(t *testing.T) {
+	tests := []struct {
+		policy  string
+		wantErr bool
+	}{
+		{"abort", true},
+		{"warn", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			dir := t.TempDir()
+			writeHookConfig(t, dir, "  pre_detect:\n    - run: exit 1\n      on_failure: "+tt.policy+"\n")
+
+			err := runInit(initCmd, []string{dir})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runInit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			_, statErr := os.Stat(filepath.Join(dir, "AGENTS.md"))
+			if generated := statErr == nil; generated == tt.wantErr {
+				t.Errorf("AGENTS.md generated = %v, want %v", generated, !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadOnlyCommands_HooksOptIn(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	writeHookConfig(t, dir, "  pre_detect:\n    - run: touch pre.txt\n")
+	detectCmd.SetOut(new(strings.Builder))
+	defer detectCmd.SetOut(nil)
+
+	// Inspecting a checkout runs none of its commands by default
+	if err := runDetect(detectCmd, []string{dir}); err != nil {
+		t.Fatalf("runDetect() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pre.txt")); err == nil {
+		t.Fatal("detect ran a pre_detect hook without --hooks")
+	}
+
+	flagHooks = true
+	defer func() { flagHooks = false }()
+	if err := runDetect(detectCmd, []string{dir}); err != nil {
+		t.Fatalf("runDetect() --hooks error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pre.txt")); err != nil {
+		t.Error("detect --hooks did not run the pre_detect hook")
+	}
+}
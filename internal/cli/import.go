@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/importer"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var flagImport bool
+
+var importCmd = &cobra.Command{
+	Use:   "import [directory]",
+	Short: "Import existing AI rule files into .agent/",
+	Long: `Import hand-written AI rule files into the .agent/ context.
+
+.cursorrules, CLAUDE.md, .github/copilot-instructions.md and CONVENTIONS.md
+are split at their headings and each section is classified by its heading:
+
+  commands      → .agent/commands.md
+  testing       → .agent/testing.md
+  style         → .agent/stack.md
+  architecture  → .agent/architecture.md
+
+Subsections without a telling heading follow their parent. Anything else
+is kept in .agent/AGENTS_LEGACY.md. Sections the target already contains
+are not imported twice, and files agentic-repo generated are skipped.
+
+Run it after init, or use "init --import", which reads the files before
+they can be overwritten. The source files are left alone; once their
+content is in .agent/, "sync --force" regenerates them from it.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().BoolVarP(&flagDryRun, "dry-run", "n", false, "Show where sections would go without writing")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	absPath, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+
+	docs, err := importer.Read(absPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := importDocs(absPath, docs); err != nil {
+		return err
+	}
+	if !flagDryRun {
+		color.New(color.FgGreen, color.Bold).Println("\n✓ Import complete")
+	}
+	return nil
+}
+
+// importDocs merges parsed rule files into .agent/ and returns the paths
+// it wrote
+func importDocs(root string, docs []importer.Document) ([]string, error) {
+	changes, err := importer.Plan(root, docs)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		color.New(color.FgYellow).Println("   ⏭  Nothing to import")
+		return nil, nil
+	}
+
+	verb := "Imported"
+	if flagDryRun {
+		verb = "Would import"
+	}
+	for _, change := range changes {
+		color.New(color.FgCyan).Printf("   ⬅  %s %d sections into %s from %s\n",
+			verb, len(change.Sections), change.Target, strings.Join(change.Sources, ", "))
+	}
+	if flagDryRun {
+		return nil, nil
+	}
+
+	if err := importer.Apply(root, changes); err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+
+	written := make([]string, 0, len(changes))
+	for _, change := range changes {
+		written = append(written, filepath.Join(root, filepath.FromSlash(change.Target)))
+	}
+	return written, nil
+}
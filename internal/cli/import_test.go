@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunInit_Import(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("## Testing\n\nRun make test-integration too.\n\n## Security\n\nNever log tokens.\n"), 0644)
+
+	// --force would overwrite CLAUDE.md, so it must be read first
+	flagImport, flagForce = true, true
+	defer func() { flagImport, flagForce = false, false }()
+
+	if err := runInit(initCmd, []string{dir}); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+
+	for file, want := range map[string]string{
+		".agent/testing.md":       "Run make test-integration too.",
+		".agent/AGENTS_LEGACY.md": "Never log tokens.",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			t.Fatalf("reading %s: %v", file, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("%s missing %q:\n%s", file, want, content)
+		}
+	}
+}
+
+func TestRunImport(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "CONVENTIONS.md"), []byte("## Naming\n\nUse short names.\n"), 0644)
+
+	flagDryRun = true
+	if err := runImport(importCmd, []string{dir}); err != nil {
+		t.Fatalf("runImport() --dry-run error = %v", err)
+	}
+	flagDryRun = false
+	if _, err := os.Stat(filepath.Join(dir, ".agent", "stack.md")); err == nil {
+		t.Fatal("dry run should not write files")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := runImport(importCmd, []string{dir}); err != nil {
+			t.Fatalf("runImport() error = %v", err)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(dir, ".agent", "stack.md"))
+	if err != nil {
+		t.Fatalf("reading stack.md: %v", err)
+	}
+	if n := strings.Count(string(content), "Use short names."); n != 1 {
+		t.Errorf("stack.md contains the section %d times, want once:\n%s", n, content)
+	}
+}
+
+func TestRunImport_ThenInitForce(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# Team notes\n\nDeploys need two approvals.\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("## Security\n\nNever log tokens.\n"), 0644)
+
+	if err := runImport(importCmd, []string{dir}); err != nil {
+		t.Fatalf("runImport() error = %v", err)
+	}
+	flagForce = true
+	defer func() { flagForce = false }()
+	if err := runInit(initCmd, []string{dir}); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".agent", "AGENTS_LEGACY.md"))
+	if err != nil {
+		t.Fatalf("reading AGENTS_LEGACY.md: %v", err)
+	}
+	for _, want := range []string{"Never log tokens.", "Deploys need two approvals."} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("AGENTS_LEGACY.md missing %q:\n%s", want, content)
+		}
+	}
+
+	// A second run must not migrate the generated router
+	if err := runInit(initCmd, []string{dir}); err != nil {
+		t.Fatalf("second runInit() error = %v", err)
+	}
+	again, _ := os.ReadFile(filepath.Join(dir, ".agent", "AGENTS_LEGACY.md"))
+	if string(again) != string(content) {
+		t.Errorf("second init changed AGENTS_LEGACY.md:\n%s", again)
+	}
+}
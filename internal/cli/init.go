@@ -2,19 +2,30 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/Shaked/agentic-repo/internal/config"
 	"github.com/Shaked/agentic-repo/internal/detector"
 	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/hooks"
+	"github.com/Shaked/agentic-repo/internal/importer"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var (
-	flagForce   bool
-	flagDryRun  bool
-	flagVerbose bool
+	flagForce         bool
+	flagDryRun        bool
+	flagVerbose       bool
+	flagMaxDepth      int
+	flagInclude       []string
+	flagExclude       []string
+	flagNoIgnore      bool
+	flagMinConfidence float64
+	flagIntegrations  []string
 )
 
 var initCmd = &cobra.Command{
@@ -26,7 +37,11 @@ This command will:
 1. Detect your project type (Go, Python, Node/TS, Java)
 2. Detect if it's a monorepo with multiple project types
 3. Generate appropriate context files (AGENTS.md, .agent/, etc.)
-4. Create integration stubs for AI tools (.cursorrules, .claude/)`,
+4. Create integration files for AI tools (.cursor/rules/, CLAUDE.md, .claude/,
+   .github/copilot-instructions.md)
+
+With --import, existing AI rule files are merged into .agent/ first; see
+"agentic-repo import --help".`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runInit,
 }
@@ -35,28 +50,25 @@ func init() {
 	initCmd.Flags().BoolVarP(&flagForce, "force", "f", false, "Overwrite existing files")
 	initCmd.Flags().BoolVarP(&flagDryRun, "dry-run", "n", false, "Preview changes without writing")
 	initCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Show detailed output")
+	initCmd.Flags().StringSliceVar(&flagIntegrations, "integrations", nil, "AI tools to generate files for, replacing the configured set (e.g. claude,copilot,aider)")
+	initCmd.Flags().BoolVar(&flagNoHooks, "no-hooks", false, "Do not run the hooks configured in .agentic-repo.yaml")
+	initCmd.Flags().BoolVar(&flagImport, "import", false, "Import existing AI rule files (CLAUDE.md, .cursorrules, ...) into .agent/")
+	addScanFlags(initCmd)
 }
 
-func runInit(cmd *cobra.Command, args []string) error {
-	// Determine target directory
-	targetDir := "."
-	if len(args) > 0 {
-		targetDir = args[0]
-	}
-
-	// Resolve to absolute path
-	absPath, err := filepath.Abs(targetDir)
-	if err != nil {
-		return fmt.Errorf("failed to resolve path: %w", err)
-	}
+// addScanFlags registers the flags that control project detection
+func addScanFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&flagMaxDepth, "max-depth", detector.DefaultMaxDepth, "Directory levels below the root to scan for projects")
+	cmd.Flags().StringSliceVar(&flagInclude, "include", nil, "Only detect projects in directories matching these globs")
+	cmd.Flags().StringSliceVar(&flagExclude, "exclude", nil, "Skip directories matching these globs")
+	cmd.Flags().BoolVar(&flagNoIgnore, "no-ignore", false, "Do not honour .gitignore, .agentignore or the default excludes (node_modules, vendor, build, ...) while scanning")
+	cmd.Flags().Float64Var(&flagMinConfidence, "min-confidence", detector.DefaultMinConfidence, "Ignore detections below this confidence (0-1)")
+}
 
-	// Check if directory exists
-	info, err := os.Stat(absPath)
+func runInit(cmd *cobra.Command, args []string) error {
+	absPath, err := resolveDir(args)
 	if err != nil {
-		return fmt.Errorf("directory does not exist: %s", absPath)
-	}
-	if !info.IsDir() {
-		return fmt.Errorf("path is not a directory: %s", absPath)
+		return err
 	}
 
 	// Print header
@@ -64,19 +76,21 @@ func runInit(cmd *cobra.Command, args []string) error {
 	cyan.Printf("🔍 Scanning %s\n", absPath)
 
 	// Detect project stacks
-	results, err := detector.Scan(absPath)
+	cfg, err := config.Load(absPath)
 	if err != nil {
-		return fmt.Errorf("detection failed: %w", err)
+		return err
+	}
+
+	results, err := scanRepo(cmd, cfg, absPath, true)
+	if err != nil {
+		return err
 	}
 
 	if len(results) == 0 {
 		yellow := color.New(color.FgYellow)
 		yellow.Println("⚠️  No recognized project types found")
 		yellow.Println("   Generating generic context files...")
-		results = []detector.Result{{
-			Path:  absPath,
-			Stack: detector.StackUnknown,
-		}}
+		results = unknownResults(absPath)
 	}
 
 	// Determine if monorepo
@@ -86,17 +100,63 @@ func runInit(cmd *cobra.Command, args []string) error {
 		printDetectionResults(results, isMonorepo)
 	}
 
+	integrations, err := selectedIntegrations(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Read the rule files to import before --force can overwrite them
+	var imports []importer.Document
+	if flagImport {
+		if imports, err = importer.Read(absPath); err != nil {
+			return err
+		}
+	}
+
 	// Generate files
 	gen := generator.New(generator.Options{
-		Force:   flagForce,
-		DryRun:  flagDryRun,
-		Verbose: flagVerbose,
+		Force:        flagForce,
+		DryRun:       flagDryRun,
+		Verbose:      flagVerbose,
+		TemplateDirs: cfg.TemplateDirs(absPath),
+		Integrations: integrations,
 	})
 
-	if err := gen.Generate(absPath, results, isMonorepo); err != nil {
+	written, err := gen.GenerateFiles(absPath, results, isMonorepo)
+	if err != nil {
 		return fmt.Errorf("generation failed: %w", err)
 	}
 
+	pluginWritten, err := generateWithPlugins(cmd, cfg, gen, absPath, results)
+	if err != nil {
+		return fmt.Errorf("generation failed: %w", err)
+	}
+	written = append(written, pluginWritten...)
+
+	if flagImport {
+		importWritten, err := importDocs(absPath, imports)
+		if err != nil {
+			return err
+		}
+		written = append(written, importWritten...)
+	}
+
+	if !flagDryRun {
+		if err := recordDerived(gen, absPath, results, isMonorepo, written); err != nil {
+			return err
+		}
+	}
+
+	if err := runHooks(cmd, cfg, hooks.PostGenerate, absPath, written); err != nil {
+		return err
+	}
+
+	if !flagDryRun {
+		if err := checkBudgets(cfg, absPath, results); err != nil {
+			return err
+		}
+	}
+
 	// Print success
 	green := color.New(color.FgGreen, color.Bold)
 	if flagDryRun {
@@ -108,6 +168,97 @@ func runInit(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// resolveDir returns the absolute target directory from the optional
+// [directory] argument, defaulting to the current directory
+func resolveDir(args []string) (string, error) {
+	// Determine target directory
+	targetDir := "."
+	if len(args) > 0 {
+		targetDir = args[0]
+	}
+
+	// Resolve to absolute path
+	absPath, err := filepath.Abs(targetDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	// Check if directory exists
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("directory does not exist: %s", absPath)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("path is not a directory: %s", absPath)
+	}
+
+	return absPath, nil
+}
+
+// scanRepo runs the pre_detect hooks, scans the repository and adds the
+// projects found by detector plugins
+func scanRepo(cmd *cobra.Command, cfg *config.Config, root string, withHooks bool) ([]detector.Result, error) {
+	opts, err := scanOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if withHooks {
+		if err := runHooks(cmd, cfg, hooks.PreDetect, root, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	results, err := detector.ScanWithOptions(root, opts)
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+
+	return detectWithPlugins(cmd, cfg, root, results)
+}
+
+// unknownResults is the single generic project used when nothing is
+// detected
+func unknownResults(root string) []detector.Result {
+	return []detector.Result{{Path: root, Stack: detector.StackUnknown}}
+}
+
+// scanOptions builds detector options from the scan flags and the
+// detectors declared in the repository config
+func scanOptions(cfg *config.Config) (detector.ScanOptions, error) {
+	registry, err := cfg.Registry()
+	if err != nil {
+		return detector.ScanOptions{}, err
+	}
+
+	return detector.ScanOptions{
+		MaxDepth:      flagMaxDepth,
+		Include:       flagInclude,
+		Exclude:       flagExclude,
+		NoIgnoreFiles: flagNoIgnore,
+		MinConfidence: flagMinConfidence,
+		Registry:      registry,
+	}, nil
+}
+
+// selectedIntegrations returns the integrations from --integrations, or
+// the configured ones when the flag is not set
+func selectedIntegrations(cfg *config.Config) ([]generator.Integration, error) {
+	if len(flagIntegrations) == 0 {
+		return cfg.EnabledIntegrations(), nil
+	}
+
+	integrations := make([]generator.Integration, 0, len(flagIntegrations))
+	for _, name := range flagIntegrations {
+		i, err := generator.ParseIntegration(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, i)
+	}
+	return integrations, nil
+}
+
 func printDetectionResults(results []detector.Result, isMonorepo bool) {
 	fmt.Println()
 	if isMonorepo {
@@ -118,6 +269,18 @@ func printDetectionResults(results []detector.Result, isMonorepo bool) {
 
 	for _, r := range results {
 		fmt.Printf("   • %s: %s\n", r.Path, r.Stack)
+		printEvidence(os.Stdout, r)
 	}
 	fmt.Println()
 }
+
+// printEvidence prints why a result got its stack
+func printEvidence(w io.Writer, r detector.Result) {
+	if r.Stack == detector.StackUnknown {
+		return
+	}
+	fmt.Fprintf(w, "     confidence %.2f: %s\n", r.Confidence, strings.Join(r.Evidence, ", "))
+	for _, alt := range r.Alternatives {
+		fmt.Fprintf(w, "     also matched %s\n", alt)
+	}
+}
@@ -20,6 +20,13 @@ func TestInitCmd_Flags(t *testing.T) {
 		{"force", "f"},
 		{"dry-run", "n"},
 		{"verbose", "v"},
+		{"max-depth", ""},
+		{"include", ""},
+		{"exclude", ""},
+		{"no-ignore", ""},
+		{"min-confidence", ""},
+		{"no-hooks", ""},
+		{"integrations", ""},
 	}
 
 	for _, tt := range tests {
@@ -233,3 +240,35 @@ func TestRunInit_EmptyDirectory(t *testing.T) {
 		t.Error("expected AGENTS.md to be created for empty directory")
 	}
 }
+
+func TestRunInit_Integrations(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+
+	flagIntegrations = []string{"aider", "gemini"}
+	defer func() { flagIntegrations = nil }()
+
+	if err := runInit(initCmd, []string{dir}); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+
+	for _, f := range []string{"CONVENTIONS.md", ".aider.conf.yml", "GEMINI.md"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("expected %s: %v", f, err)
+		}
+	}
+	for _, f := range []string{".cursorrules", "CLAUDE.md"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+			t.Errorf("%s should not be generated when --integrations excludes it", f)
+		}
+	}
+}
+
+func TestRunInit_UnknownIntegration(t *testing.T) {
+	flagIntegrations = []string{"vim"}
+	defer func() { flagIntegrations = nil }()
+
+	if err := runInit(initCmd, []string{t.TempDir()}); err == nil {
+		t.Error("expected error for unknown integration")
+	}
+}
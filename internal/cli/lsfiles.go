@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Shaked/agentic-repo/internal/ignore"
+	"github.com/Shaked/agentic-repo/internal/tokens"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var flagSummary bool
+
+var lsFilesCmd = &cobra.Command{
+	Use:   "ls-files [path]",
+	Short: "List the files an agent should see",
+	Long: `List the files below path, the current directory by default, that are
+not excluded by .gitignore or .agentignore, followed by their count, size
+and estimated tokens per top-level directory.
+
+Ignore files are read from the repository root down to path and in every
+directory below it, with gitignore semantics: later rules win, ! negates,
+a leading or inner / anchors a pattern and ** spans directories. Use it to
+check what .agentignore hides before handing a repository to an agent.
+
+Binary files and files over 256 KiB are listed with their size but count
+no tokens, as pack leaves them out.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLsFiles,
+}
+
+func init() {
+	lsFilesCmd.Flags().BoolVarP(&flagSummary, "summary", "s", false, "Print only the totals per top-level directory")
+	lsFilesCmd.Flags().StringVarP(&flagFormat, "format", "o", "table", "Output format: table or json")
+}
+
+// listing is the machine-readable output of ls-files; paths are relative
+// to the listed directory
+type listing struct {
+	Files       []listedFile `json:"files,omitempty"`
+	Directories []dirTotal   `json:"directories"`
+	Total       dirTotal     `json:"total"`
+}
+
+// listedFile is a file an agent can see
+type listedFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Tokens int    `json:"tokens"`
+}
+
+// dirTotal adds up the files below a top-level directory, "." for files
+// directly in the listed directory
+type dirTotal struct {
+	Path   string `json:"path"`
+	Files  int    `json:"files"`
+	Size   int64  `json:"size"`
+	Tokens int    `json:"tokens"`
+}
+
+func runLsFiles(cmd *cobra.Command, args []string) error {
+	dir, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+	if flagFormat != "table" && flagFormat != "json" {
+		return fmt.Errorf("unknown format %q (want table or json)", flagFormat)
+	}
+
+	files, err := listFiles(repoRoot(dir), dir)
+	if err != nil {
+		return err
+	}
+	l := summarize(files)
+	if !flagSummary {
+		l.Files = files
+	}
+
+	out := cmd.OutOrStdout()
+	if flagFormat == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(l)
+	}
+	printListing(out, l)
+	return nil
+}
+
+// listFiles returns the files below dir that the ignore files of root
+// and its subdirectories do not exclude, sorted by path
+func listFiles(root, dir string) ([]listedFile, error) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	base := filepath.ToSlash(rel) + "/"
+	if rel == "." {
+		base = ""
+	}
+
+	files := []listedFile{}
+	err = ignore.Walk(root, rel, func(rel string, d fs.DirEntry) error {
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		f := listedFile{Path: strings.TrimPrefix(rel, base), Size: info.Size()}
+		if content, err := ignore.ReadText(filepath.Join(root, filepath.FromSlash(rel))); err == nil {
+			f.Tokens = tokens.Count(string(content))
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// summarize totals files per top-level directory
+func summarize(files []listedFile) listing {
+	byDir := map[string]*dirTotal{}
+	var l listing
+	for _, f := range files {
+		top := "."
+		if i := strings.IndexByte(f.Path, '/'); i >= 0 {
+			top = f.Path[:i]
+		}
+		t, ok := byDir[top]
+		if !ok {
+			t = &dirTotal{Path: top}
+			byDir[top] = t
+		}
+		for _, t := range []*dirTotal{t, &l.Total} {
+			t.Files++
+			t.Size += f.Size
+			t.Tokens += f.Tokens
+		}
+	}
+	l.Directories = []dirTotal{}
+	for _, t := range byDir {
+		l.Directories = append(l.Directories, *t)
+	}
+	sort.Slice(l.Directories, func(i, j int) bool { return l.Directories[i].Path < l.Directories[j].Path })
+	l.Total.Path = "."
+	return l
+}
+
+// printListing prints the files and totals for humans
+func printListing(w io.Writer, l listing) {
+	for _, f := range l.Files {
+		fmt.Fprintln(w, f.Path)
+	}
+	if len(l.Files) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	color.New(color.FgMagenta).Fprintf(w, "📂 %d %s, %s, ~%d tokens\n",
+		l.Total.Files, plural(l.Total.Files, "file", "files"), formatSize(l.Total.Size), l.Total.Tokens)
+	if len(l.Directories) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   DIRECTORY\tFILES\tSIZE\tTOKENS")
+	for _, d := range l.Directories {
+		fmt.Fprintf(tw, "   %s\t%d\t%s\t%d\n", d.Path, d.Files, formatSize(d.Size), d.Tokens)
+	}
+	tw.Flush()
+}
+
+// formatSize renders a byte count with a binary unit
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/ignore"
+)
+
+func TestLsFilesCmd_Flags(t *testing.T) {
+	for _, name := range []string{"summary", "format"} {
+		if lsFilesCmd.Flags().Lookup(name) == nil {
+			t.Errorf("flag --%s not found", name)
+		}
+	}
+}
+
+func TestRunLsFiles(t *testing.T) {
+	dir := gitRepo(t, map[string]string{
+		".agentignore":        "*.lock\nfixtures/\n",
+		"README.md":           "12345678",
+		"yarn.lock":           "ignored",
+		"api/main.go":         "1234",
+		"api/fixtures/a.json": "ignored",
+		"api/logo.png":        "\x89PNG\x00\x00",
+		"api/dump.sql":        strings.Repeat("x", ignore.MaxTextSize+1),
+	})
+
+	var out bytes.Buffer
+	lsFilesCmd.SetOut(&out)
+	defer lsFilesCmd.SetOut(nil)
+	flagFormat = "json"
+	defer func() { flagFormat = "table" }()
+
+	if err := runLsFiles(lsFilesCmd, []string{dir}); err != nil {
+		t.Fatalf("runLsFiles() error = %v", err)
+	}
+	var got listing
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	want := listing{
+		Files: []listedFile{
+			{Path: ".agentignore", Size: 17, Tokens: 8},
+			{Path: "README.md", Size: 8, Tokens: 3},
+			// Too large to count, like pack leaves it out
+			{Path: "api/dump.sql", Size: ignore.MaxTextSize + 1},
+			{Path: "api/logo.png", Size: 6},
+			{Path: "api/main.go", Size: 4, Tokens: 2},
+		},
+		Directories: []dirTotal{
+			{Path: ".", Files: 2, Size: 25, Tokens: 11},
+			{Path: "api", Files: 3, Size: ignore.MaxTextSize + 11, Tokens: 2},
+		},
+		Total: dirTotal{Path: ".", Files: 5, Size: ignore.MaxTextSize + 36, Tokens: 13},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listing =\n%+v\nwant\n%+v", got, want)
+	}
+
+	out.Reset()
+	flagFormat = "table"
+	flagSummary = true
+	defer func() { flagSummary = false }()
+	if err := runLsFiles(lsFilesCmd, []string{filepath.Join(dir, "api")}); err != nil {
+		t.Fatalf("runLsFiles() error = %v", err)
+	}
+	for _, want := range []string{"3 files, 256.0 KiB, ~2 tokens", "DIRECTORY", "."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("table output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "main.go") {
+		t.Errorf("--summary should not list files:\n%s", out.String())
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.n); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
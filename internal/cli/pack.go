@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/manifest"
+	"github.com/Shaked/agentic-repo/internal/pack"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagMaxTokens  int
+	flagPackFormat string
+	flagOutput     string
+)
+
+var packCmd = &cobra.Command{
+	Use:   "pack [path]",
+	Short: "Bundle a project's context and source into one document",
+	Long: `Bundle a project's context and source files into a single Markdown or
+XML document, to paste or pipe into assistants that do not read the
+repository themselves.
+
+path is a project directory, the current directory by default. The bundle
+starts with the project's AGENTS.md and .agent/ files (plus the root
+rules and overview for a monorepo project), then its README and build
+manifests, entry points, the source most other code depends on, tests
+and any other text files. Files matched by .gitignore or .agentignore,
+hidden, binary and derived files are left out.
+
+Files are added in that order until --max-tokens is reached. Context
+files that do not fit are truncated; source files are skipped whole and
+listed at the end of the bundle.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPack,
+}
+
+func init() {
+	packCmd.Flags().IntVar(&flagMaxTokens, "max-tokens", 100000, "Token budget for the bundle, 0 for no limit")
+	packCmd.Flags().StringVarP(&flagPackFormat, "format", "o", "markdown", "Output format: markdown or xml")
+	packCmd.Flags().StringVarP(&flagOutput, "output", "O", "", "Write the bundle to this file instead of stdout")
+	packCmd.Flags().BoolVar(&flagHooks, "hooks", false, "Run the pre_detect hooks configured in .agentic-repo.yaml first")
+	addScanFlags(packCmd)
+}
+
+func runPack(cmd *cobra.Command, args []string) error {
+	dir, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+	if flagPackFormat != "markdown" && flagPackFormat != "xml" {
+		return fmt.Errorf("unknown format %q (want markdown or xml)", flagPackFormat)
+	}
+	if flagMaxTokens < 0 {
+		return fmt.Errorf("--max-tokens must not be negative")
+	}
+
+	root := repoRoot(dir)
+	cfg, err := config.Load(root)
+	if err != nil {
+		return err
+	}
+	results, err := scanRepo(cmd, cfg, root, flagHooks)
+	if err != nil {
+		return err
+	}
+	m, err := manifest.Load(root)
+	if err != nil {
+		return err
+	}
+
+	opts := pack.Options{Root: root, Dir: dir, MaxTokens: flagMaxTokens, Exclude: m.Paths()}
+	if r, ok := owningResult(dir, results); ok {
+		opts.Graph = generator.LoadArchitecture(r.Path, r.Stack)
+	}
+	bundle, err := pack.Build(opts)
+	if err != nil {
+		return err
+	}
+
+	out := bundle.Markdown()
+	if flagPackFormat == "xml" {
+		out = bundle.XML()
+	}
+	if flagOutput == "" {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), out)
+		return err
+	}
+	if err := os.WriteFile(flagOutput, []byte(out), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", flagOutput, err)
+	}
+	color.New(color.FgGreen).Fprintf(cmd.OutOrStdout(), "✓ Packed %d files (~%d tokens) into %s\n", len(bundle.Files), bundle.Tokens(), flagOutput)
+	if len(bundle.Omitted) > 0 {
+		color.New(color.FgYellow).Fprintf(cmd.OutOrStdout(), "⚠️  %d files omitted to fit --max-tokens %d\n", len(bundle.Omitted), flagMaxTokens)
+	}
+	return nil
+}
+
+// repoRoot returns the nearest directory at or above dir holding a .git
+// entry, or dir itself outside a repository
+func repoRoot(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// owningResult returns the deepest detected project containing dir
+func owningResult(dir string, results []detector.Result) (detector.Result, bool) {
+	var best detector.Result
+	found := false
+	for _, r := range results {
+		if r.Path != dir && !strings.HasPrefix(dir, r.Path+string(filepath.Separator)) {
+			continue
+		}
+		if !found || len(r.Path) > len(best.Path) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+func TestPackCmd_Flags(t *testing.T) {
+	for _, name := range []string{"max-tokens", "format", "output", "max-depth", "include", "exclude", "hooks"} {
+		if packCmd.Flags().Lookup(name) == nil {
+			t.Errorf("flag --%s not found", name)
+		}
+	}
+}
+
+func TestRunPack(t *testing.T) {
+	dir := gitRepo(t, map[string]string{
+		".agentignore":         "secrets/\n",
+		".agent/rules.md":      "# Rules\n",
+		".agent/overview.md":   "# Overview\n",
+		"CLAUDE.md":            "# derived\n",
+		".agent/manifest.json": `{"version": 1, "files": {"CLAUDE.md": "x"}}`,
+		"api/go.mod":           "module example.com/api\n",
+		"api/AGENTS.md":        "# api\n",
+		"api/main.go":          "package main\n\nfunc main() {}\n",
+		"api/secrets/key.txt":  "hunter2\n",
+		"api/handler_test.go":  "package main\n",
+		"web/package.json":     `{"name": "web"}`,
+		"web/src/index.ts":     "export {}\n",
+	})
+
+	var out bytes.Buffer
+	packCmd.SetOut(&out)
+	defer packCmd.SetOut(nil)
+
+	if err := runPack(packCmd, []string{filepath.Join(dir, "api")}); err != nil {
+		t.Fatalf("runPack() error = %v", err)
+	}
+	got := out.String()
+	order := []string{"# Context: api", "## api/AGENTS.md", "## .agent/rules.md", "## .agent/overview.md", "## api/go.mod", "## api/main.go", "## api/handler_test.go"}
+	last := -1
+	for _, want := range order {
+		i := strings.Index(got, want)
+		if i < 0 {
+			t.Fatalf("output missing %q:\n%s", want, got)
+		}
+		if i < last {
+			t.Errorf("%q out of order:\n%s", want, got)
+		}
+		last = i
+	}
+	for _, unwanted := range []string{"hunter2", "web/", "CLAUDE.md"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("output contains %q:\n%s", unwanted, got)
+		}
+	}
+
+	out.Reset()
+	flagPackFormat = "xml"
+	flagOutput = filepath.Join(t.TempDir(), "context.xml")
+	defer func() { flagPackFormat, flagOutput = "markdown", "" }()
+	if err := runPack(packCmd, []string{filepath.Join(dir, "api")}); err != nil {
+		t.Fatalf("runPack() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Packed 6 files") {
+		t.Errorf("summary = %q", out.String())
+	}
+	data, err := os.ReadFile(flagOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), `<context project="api"`) {
+		t.Errorf("xml output = %q", data)
+	}
+}
+
+func TestRunPack_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	flagPackFormat = "html"
+	if err := runPack(packCmd, []string{dir}); err == nil {
+		t.Error("runPack() with an unknown format should fail")
+	}
+	flagPackFormat = "markdown"
+
+	flagMaxTokens = -1
+	defer func() { flagMaxTokens = 100000 }()
+	if err := runPack(packCmd, []string{dir}); err == nil {
+		t.Error("runPack() with a negative budget should fail")
+	}
+}
+
+func TestOwningResult(t *testing.T) {
+	var results []detector.Result
+	for _, p := range []string{"/repo", "/repo/api", "/repo/api/v2"} {
+		results = append(results, detector.Result{Path: p})
+	}
+	tests := []struct {
+		dir  string
+		want string
+		ok   bool
+	}{
+		{"/repo/api/v2/x", "/repo/api/v2", true},
+		{"/repo/api", "/repo/api", true},
+		{"/repo/apiary", "/repo", true},
+		{"/elsewhere", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := owningResult(tt.dir, results)
+		if ok != tt.ok || got.Path != tt.want {
+			t.Errorf("owningResult(%q) = %q, %v; want %q, %v", tt.dir, got.Path, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/plugin"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins [directory]",
+	Short: "List available plugins",
+	Long: `List the plugins agentic-repo can use.
+
+Executables named agentic-repo-<name> on PATH run as "agentic-repo <name>".
+Detector and generator plugins are declared under "plugins" in
+.agentic-repo.yaml; they receive the scan as JSON on stdin and answer with
+extra projects or files as JSON on stdout. See USAGE.md for the protocol.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPlugins,
+}
+
+func runPlugins(cmd *cobra.Command, args []string) error {
+	absPath, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(absPath)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	color.New(color.FgMagenta).Fprintln(out, "🔌 Subcommands on PATH")
+	exes := plugin.Discover(os.Getenv("PATH"))
+	if len(exes) == 0 {
+		fmt.Fprintln(out, "   (none)")
+	}
+	for _, e := range exes {
+		fmt.Fprintf(out, "   • %s: %s\n", e.Name, e.Path)
+	}
+
+	color.New(color.FgMagenta).Fprintf(out, "🔌 Configured in %s\n", config.FileName)
+	plugins := cfg.ResolvedPlugins(absPath)
+	if len(plugins) == 0 {
+		fmt.Fprintln(out, "   (none)")
+	}
+	for _, p := range plugins {
+		fmt.Fprintf(out, "   • %s (%s): %s\n", p.Name, p.Kind, p.Command)
+	}
+	return nil
+}
+
+// addPluginCommands exposes plugin executables as subcommands. Built-in
+// commands take precedence over plugins with the same name.
+func addPluginCommands(root *cobra.Command, exes []plugin.Executable) {
+	for _, e := range exes {
+		if cmd, _, err := root.Find([]string{e.Name}); err == nil && cmd != root {
+			continue
+		}
+		root.AddCommand(pluginCommand(e))
+	}
+}
+
+// pluginCommand runs a plugin executable with the remaining arguments
+func pluginCommand(e plugin.Executable) *cobra.Command {
+	return &cobra.Command{
+		Use:                e.Name,
+		Short:              fmt.Sprintf("Plugin %s", e.Path),
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		SilenceErrors:      true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return e.Exec(commandContext(cmd), args, cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	}
+}
+
+// detectWithPlugins merges the projects reported by configured detector
+// plugins into the scan results
+func detectWithPlugins(cmd *cobra.Command, cfg *config.Config, root string, results []detector.Result) ([]detector.Result, error) {
+	extra, err := plugin.Detect(commandContext(cmd), cfg.ResolvedPlugins(root), root, results)
+	if err != nil {
+		return nil, err
+	}
+	if len(extra) == 0 {
+		return results, nil
+	}
+	return detector.Merge(results, extra...), nil
+}
+
+// generateWithPlugins writes the files returned by configured generator
+// plugins and returns the paths it wrote
+func generateWithPlugins(cmd *cobra.Command, cfg *config.Config, gen *generator.Generator, root string, results []detector.Result) ([]string, error) {
+	files, err := plugin.Generate(commandContext(cmd), cfg.ResolvedPlugins(root), root, results)
+	if err != nil {
+		return nil, err
+	}
+	return gen.WriteFiles(files)
+}
+
+// commandContext returns the command's context, which is unset when a
+// RunE function is called directly
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
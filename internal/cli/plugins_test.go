@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/plugin"
+	"github.com/spf13/cobra"
+)
+
+// writePluginScript creates an executable shell script in dir
+func writePluginScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestAddPluginCommands(t *testing.T) {
+	root := &cobra.Command{Use: "agentic-repo"}
+	root.AddCommand(&cobra.Command{Use: "init", Run: func(*cobra.Command, []string) {}})
+
+	addPluginCommands(root, []plugin.Executable{
+		{Name: "init", Path: "/bin/agentic-repo-init"},
+		{Name: "docs", Path: "/bin/agentic-repo-docs"},
+	})
+
+	names := map[string]string{}
+	for _, c := range root.Commands() {
+		names[c.Name()] = c.Short
+	}
+	if _, ok := names["docs"]; !ok {
+		t.Error("expected docs plugin subcommand")
+	}
+	if strings.Contains(names["init"], "Plugin") {
+		t.Error("plugin must not shadow the built-in init command")
+	}
+}
+
+func TestPluginCommand_PassesArgs(t *testing.T) {
+	dir := t.TempDir()
+	path := writePluginScript(t, dir, "agentic-repo-echo", "echo \"$@\"\n")
+
+	cmd := pluginCommand(plugin.Executable{Name: "echo", Path: path})
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+
+	if err := cmd.RunE(cmd, []string{"--dry-run", "x"}); err != nil {
+		t.Fatalf("RunE() error = %v", err)
+	}
+	if got := buf.String(); got != "--dry-run x\n" {
+		t.Errorf("output = %q, want %q", got, "--dry-run x\n")
+	}
+}
+
+func TestRunPlugins(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".agentic-repo.yaml"), []byte("plugins:\n  - name: docs\n    kind: generator\n"), 0644)
+
+	buf := new(bytes.Buffer)
+	pluginsCmd.SetOut(buf)
+	defer pluginsCmd.SetOut(nil)
+
+	if err := runPlugins(pluginsCmd, []string{dir}); err != nil {
+		t.Fatalf("runPlugins() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "docs (generator): agentic-repo-docs") {
+		t.Errorf("expected configured plugin in output:\n%s", buf.String())
+	}
+}
+
+func TestRunDetectAndInit_Plugins(t *testing.T) {
+	dir := t.TempDir()
+	writePluginScript(t, dir, "detect.sh", `cat > /dev/null
+echo '{"projects":[{"path":".","stack":"bazel","evidence":["WORKSPACE"]}]}'
+`)
+	writePluginScript(t, dir, "generate.sh", `cat > /dev/null
+cat <<'JSON'
+{"files":[{"path":".agent/acme.md","content":"# ACME conventions\n"}]}
+JSON
+`)
+	os.WriteFile(filepath.Join(dir, ".agentic-repo.yaml"), []byte(`plugins:
+  - name: acme-detect
+    kind: detector
+    command: ./detect.sh
+  - name: acme-docs
+    kind: generator
+    command: ./generate.sh
+`), 0644)
+
+	buf := new(bytes.Buffer)
+	detectCmd.SetOut(buf)
+	defer detectCmd.SetOut(nil)
+	flagFormat = "json"
+	defer func() { flagFormat = "table" }()
+
+	if err := runDetect(detectCmd, []string{dir}); err != nil {
+		t.Fatalf("runDetect() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"stack": "bazel"`) {
+		t.Errorf("expected the plugin-detected stack:\n%s", buf.String())
+	}
+
+	if err := runInit(initCmd, []string{dir}); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".agent", "acme.md"))
+	if err != nil {
+		t.Fatalf("expected generator plugin output: %v", err)
+	}
+	if string(content) != "# ACME conventions\n" {
+		t.Errorf("acme.md = %q", content)
+	}
+}
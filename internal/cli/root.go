@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"os"
+
+	"github.com/Shaked/agentic-repo/internal/plugin"
 	"github.com/spf13/cobra"
 )
 
+// version is reported by the version command and the MCP server
+const version = "v0.1.0"
+
 var rootCmd = &cobra.Command{
 	Use:   "agentic-repo",
 	Short: "Initialize repositories with the Agent-Native Repository Standard",
@@ -17,11 +23,21 @@ your codebase efficiently without wasting tokens.`,
 
 // Execute runs the root command
 func Execute() error {
+	addPluginCommands(rootCmd, plugin.Discover(os.Getenv("PATH")))
 	return rootCmd.Execute()
 }
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(detectCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(affectedCmd)
+	rootCmd.AddCommand(packCmd)
+	rootCmd.AddCommand(lsFilesCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(pluginsCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
@@ -29,6 +45,6 @@ var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number",
 	Run: func(cmd *cobra.Command, args []string) {
-		cmd.Println("agentic-repo v0.1.0")
+		cmd.Println("agentic-repo " + version)
 	},
 }
@@ -42,7 +42,7 @@ func TestRootCmd_HasSubcommands(t *testing.T) {
 	// Verify subcommands are registered
 	subcommands := rootCmd.Commands()
 
-	expectedCommands := []string{"init", "version"}
+	expectedCommands := []string{"init", "detect", "sync", "import", "serve", "plugins", "version"}
 	for _, expected := range expectedCommands {
 		found := false
 		for _, cmd := range subcommands {
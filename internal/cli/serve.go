@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+var flagMCP bool
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [directory]",
+	Short: "Serve repository context to AI tools",
+	Long: `Serve the repository's agent context to AI tools.
+
+With --mcp, a Model Context Protocol server runs over stdio. It exposes
+AGENTS.md and the .agent/*.md files of the root and of every detected
+project as resources, and these tools:
+
+  project_for_path  which project owns a file or directory
+  list_commands     a project's test and lint commands and commands.md
+  run_tests         run a project's test command and return the output
+
+The repository is scanned once at startup, and tool calls run
+concurrently. With the opt-in mcp integration enabled, init writes
+.mcp.json (Claude Code) and .cursor/mcp.json (Cursor) so those tools start
+the server.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().BoolVar(&flagMCP, "mcp", false, "Run a Model Context Protocol server over stdio")
+	serveCmd.Flags().BoolVar(&flagHooks, "hooks", false, "Run the pre_detect hooks configured in .agentic-repo.yaml first")
+	addScanFlags(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if !flagMCP {
+		return errors.New("no protocol selected; run agentic-repo serve --mcp")
+	}
+
+	absPath, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(absPath)
+	if err != nil {
+		return err
+	}
+
+	// Hook output goes to stderr, so stdout carries only MCP messages
+	results, err := scanRepo(cmd, cfg, absPath, flagHooks)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		results = unknownResults(absPath)
+	}
+
+	server := mcp.NewServer(absPath, results, mcp.Options{Version: version})
+	return server.Serve(commandContext(cmd), cmd.InOrStdin(), cmd.OutOrStdout())
+}
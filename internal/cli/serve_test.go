@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunServe(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+
+	if err := runServe(serveCmd, []string{dir}); err == nil {
+		t.Error("serve without --mcp should fail")
+	}
+
+	flagMCP = true
+	defer func() { flagMCP = false }()
+
+	var out bytes.Buffer
+	serveCmd.SetIn(strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"project_for_path","arguments":{"path":"main.go"}}}` + "\n"))
+	serveCmd.SetOut(&out)
+	defer func() {
+		serveCmd.SetIn(nil)
+		serveCmd.SetOut(nil)
+	}()
+
+	if err := runServe(serveCmd, []string{dir}); err != nil {
+		t.Fatalf("runServe() error = %v", err)
+	}
+	// The scan result is served: the root Go module owns main.go
+	if !strings.Contains(out.String(), `\"stack\": \"go\"`) {
+		t.Errorf("response = %s, want the root go project", out.String())
+	}
+}
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/manifest"
+	"github.com/Shaked/agentic-repo/internal/tokens"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [directory]",
+	Short: "Report the tokens of the generated context per file and project",
+	Long: `Report how many tokens the generated context costs an agent: every
+AGENTS.md router, every .agent/ file and every AI tool file derived from
+them, with totals per project.
+
+Tokens are estimated offline with a byte-pair encoding approximation, so
+counts can differ slightly from any one model's tokenizer.
+
+Files over their budget are flagged. The budgets default to 500 tokens
+for AGENTS.md and 2000 for each .agent/*.md file and are set under
+token_budgets in .agentic-repo.yaml, where on_exceed: fail also makes
+init and sync fail on them.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&flagFormat, "format", "o", "table", "Output format: table or json")
+	statsCmd.Flags().BoolVar(&flagHooks, "hooks", false, "Run the pre_detect hooks configured in .agentic-repo.yaml first")
+	addScanFlags(statsCmd)
+}
+
+// statsReport is the machine-readable output of stats
+type statsReport struct {
+	Projects []projectStats `json:"projects"`
+	Tokens   int            `json:"tokens"`
+}
+
+// projectStats lists the context files of a project
+type projectStats struct {
+	// Path is root-relative, "." for the root
+	Path   string     `json:"path"`
+	Files  []fileStat `json:"files"`
+	Tokens int        `json:"tokens"`
+}
+
+// fileStat is the token count of a context file
+type fileStat struct {
+	// Path is root-relative
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+	// Budget is the file's limit, 0 for none
+	Budget int `json:"budget"`
+}
+
+// over reports whether the file exceeds its budget
+func (f fileStat) over() bool {
+	return f.Budget > 0 && f.Tokens > f.Budget
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	absPath, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+	if flagFormat != "table" && flagFormat != "json" {
+		return fmt.Errorf("unknown format %q (want table or json)", flagFormat)
+	}
+
+	cfg, err := config.Load(absPath)
+	if err != nil {
+		return err
+	}
+	results, err := scanRepo(cmd, cfg, absPath, flagHooks)
+	if err != nil {
+		return err
+	}
+	report, err := buildStats(cfg, absPath, results)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if flagFormat == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printStats(out, report)
+	return nil
+}
+
+// buildStats counts the tokens of the routers, the .agent/ files and the
+// derived files recorded in the manifest, grouped by the deepest project
+// containing them
+func buildStats(cfg *config.Config, root string, results []detector.Result) (statsReport, error) {
+	dirs := []string{"."}
+	for _, r := range results {
+		if dir := relPath(root, r.Path); dir != "." {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	m, err := manifest.Load(root)
+	if err != nil {
+		return statsReport{}, err
+	}
+	derived := map[string][]string{}
+	for _, rel := range m.Paths() {
+		dir := ownerDir(rel, dirs)
+		derived[dir] = append(derived[dir], rel)
+	}
+
+	report := statsReport{Projects: []projectStats{}}
+	for _, dir := range dirs {
+		project := projectStats{Path: dir, Files: []fileStat{}}
+		seen := map[string]bool{}
+		for _, rel := range append(contextFiles(root, dir), derived[dir]...) {
+			if seen[rel] {
+				continue
+			}
+			seen[rel] = true
+			content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+			if err != nil {
+				continue
+			}
+			f := fileStat{
+				Path:   rel,
+				Tokens: tokens.Count(string(content)),
+				Budget: cfg.TokenBudget(strings.TrimPrefix(rel, dir+"/")),
+			}
+			project.Files = append(project.Files, f)
+			project.Tokens += f.Tokens
+		}
+		if len(project.Files) > 0 {
+			report.Projects = append(report.Projects, project)
+			report.Tokens += project.Tokens
+		}
+	}
+	return report, nil
+}
+
+// ownerDir returns the deepest of dirs containing the root-relative file
+func ownerDir(rel string, dirs []string) string {
+	best := "."
+	for _, dir := range dirs {
+		if strings.HasPrefix(rel, dir+"/") && len(dir) > len(best) {
+			best = dir
+		}
+	}
+	return best
+}
+
+// printStats prints the report for humans
+func printStats(w io.Writer, report statsReport) {
+	if len(report.Projects) == 0 {
+		color.New(color.FgYellow).Fprintln(w, "⚠️  No context files found; run agentic-repo init first")
+		return
+	}
+	color.New(color.FgMagenta).Fprintf(w, "📊 ~%d context tokens in %d %s\n",
+		report.Tokens, len(report.Projects), plural(len(report.Projects), "project", "projects"))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   FILE\tTOKENS\tBUDGET")
+	for _, p := range report.Projects {
+		for _, f := range p.Files {
+			budget := "-"
+			if f.Budget > 0 {
+				budget = fmt.Sprint(f.Budget)
+			}
+			if f.over() {
+				budget += "  ⚠️  over"
+			}
+			fmt.Fprintf(tw, "   %s\t%d\t%s\n", f.Path, f.Tokens, budget)
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "   PROJECT\tFILES\tTOKENS")
+	for _, p := range report.Projects {
+		fmt.Fprintf(tw, "   %s\t%d\t%d\n", p.Path, len(p.Files), p.Tokens)
+	}
+	tw.Flush()
+}
+
+// checkBudgets warns about every context file over its token budget and
+// fails if the configuration says so
+func checkBudgets(cfg *config.Config, root string, results []detector.Result) error {
+	report, err := buildStats(cfg, root, results)
+	if err != nil {
+		return err
+	}
+	over := 0
+	for _, p := range report.Projects {
+		for _, f := range p.Files {
+			if f.over() {
+				over++
+				color.New(color.FgYellow).Printf("   ⚠️  %s: ~%d tokens, over its budget of %d\n", f.Path, f.Tokens, f.Budget)
+			}
+		}
+	}
+	if over > 0 && cfg.FailOverBudget() {
+		return fmt.Errorf("%d context %s over the token budget; shorten them or raise token_budgets in %s",
+			over, plural(over, "file is", "files are"), config.FileName)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+func TestStatsCmd_Flags(t *testing.T) {
+	for _, name := range []string{"format", "max-depth", "include", "exclude", "hooks"} {
+		if statsCmd.Flags().Lookup(name) == nil {
+			t.Errorf("flag --%s not found", name)
+		}
+	}
+}
+
+// statsRepo writes a monorepo with context files; api's testing.md is
+// long enough to exceed a small budget
+func statsRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeRepoFiles(t, dir, map[string]string{
+		"AGENTS.md":              "# Router\n",
+		".agent/overview.md":     "# Overview\n",
+		"CLAUDE.md":              "Read AGENTS.md\n",
+		"api/go.mod":             "module example.com/api\n",
+		"api/AGENTS.md":          "# api\n",
+		"api/CLAUDE.md":          "Read AGENTS.md\n",
+		"api/.agent/testing.md":  strings.Repeat("Run the table-driven tests with go test.\n", 20),
+		"web/package.json":       `{"name": "web"}`,
+		".agent/manifest.json":   `{"version": 1, "files": {"AGENTS.md": "x", "CLAUDE.md": "x", "api/CLAUDE.md": "x"}}`,
+		".agentic-repo.yaml":     "token_budgets:\n  files:\n    .agent/testing.md: 50\n",
+		"docs/not-context.md":    "ignored\n",
+		"api/.agent/notes.txt":   "ignored\n",
+		"api/.agent/nested/x.md": "ignored\n",
+	})
+	return dir
+}
+
+func TestRunStats(t *testing.T) {
+	dir := statsRepo(t)
+
+	var out bytes.Buffer
+	statsCmd.SetOut(&out)
+	defer statsCmd.SetOut(nil)
+	flagFormat = "json"
+	defer func() { flagFormat = "table" }()
+
+	if err := runStats(statsCmd, []string{dir}); err != nil {
+		t.Fatalf("runStats() error = %v", err)
+	}
+	var report statsReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	var got []string
+	sum := 0
+	for _, p := range report.Projects {
+		for _, f := range p.Files {
+			got = append(got, p.Path+" "+f.Path)
+			sum += f.Tokens
+		}
+	}
+	want := []string{
+		". AGENTS.md", ". .agent/overview.md", ". CLAUDE.md",
+		"api api/AGENTS.md", "api api/.agent/testing.md", "api api/CLAUDE.md",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("files =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if sum != report.Tokens || sum == 0 {
+		t.Errorf("Tokens = %d, files add up to %d", report.Tokens, sum)
+	}
+	testingMD := report.Projects[1].Files[1]
+	if testingMD.Budget != 50 || !testingMD.over() {
+		t.Errorf("testing.md = %+v, want over a budget of 50", testingMD)
+	}
+	if agents := report.Projects[0].Files[0]; agents.Budget != config.DefaultTokenBudgets["AGENTS.md"] || agents.over() {
+		t.Errorf("AGENTS.md = %+v", agents)
+	}
+
+	out.Reset()
+	flagFormat = "table"
+	if err := runStats(statsCmd, []string{dir}); err != nil {
+		t.Fatalf("runStats() error = %v", err)
+	}
+	for _, want := range []string{"context tokens in 2 projects", "api/.agent/testing.md", "⚠️  over", "PROJECT"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("table output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	dir := statsRepo(t)
+	results := []detector.Result{{Path: filepath.Join(dir, "api"), Stack: detector.StackGo}}
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkBudgets(cfg, dir, results); err != nil {
+		t.Errorf("checkBudgets() with on_exceed: warn error = %v", err)
+	}
+
+	cfg.TokenBudgets.OnExceed = "fail"
+	if err := checkBudgets(cfg, dir, results); err == nil || !strings.Contains(err.Error(), "1 context file is over") {
+		t.Errorf("checkBudgets() with on_exceed: fail error = %v", err)
+	}
+
+	cfg.TokenBudgets.Files[".agent/testing.md"] = 0
+	if err := checkBudgets(cfg, dir, results); err != nil {
+		t.Errorf("checkBudgets() with the limit removed error = %v", err)
+	}
+}
+
+func TestOwnerDir(t *testing.T) {
+	dirs := []string{".", "api", "api/v2"}
+	tests := map[string]string{
+		"CLAUDE.md":           ".",
+		"api/CLAUDE.md":       "api",
+		"api/v2/.agent/x.md":  "api/v2",
+		"apiary/AGENTS.md":    ".",
+		".cursor/rules/x.mdc": ".",
+	}
+	for rel, want := range tests {
+		if got := ownerDir(rel, dirs); got != want {
+			t.Errorf("ownerDir(%q) = %q, want %q", rel, got, want)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/config"
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/hooks"
+	"github.com/Shaked/agentic-repo/internal/manifest"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagPull  bool
+	flagCheck bool
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [directory]",
+	Short: "Regenerate every AI tool file from the .agent/ context",
+	Long: `Regenerate the files derived from the .agent/ context and the config.
+
+The AGENTS.md routers and the files of every enabled AI tool integration
+are derived files: edit .agent/ (rules shared by all tools go in
+.agent/rules.md) or .agentic-repo.yaml, then run sync to update them all.
+
+sync records what it writes in .agent/manifest.json. A derived file that
+changed since then was edited by hand and is skipped with a warning.
+Use --pull to move the lines added by hand into .agent/rules.md, so they
+reach every tool, or --force to overwrite the edits.
+
+With --check nothing is written and sync fails if any derived file is
+missing, out of date or edited, which suits CI. It also checks the token
+budgets, failing on files over budget when on_exceed is fail.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSync,
+	// A failed --check is not a usage error
+	SilenceUsage: true,
+}
+
+func init() {
+	syncCmd.Flags().BoolVarP(&flagForce, "force", "f", false, "Overwrite derived files that were edited by hand")
+	syncCmd.Flags().BoolVar(&flagPull, "pull", false, "Pull lines added by hand to derived files into "+generator.RulesFile)
+	syncCmd.Flags().BoolVar(&flagCheck, "check", false, "Fail if any derived file is out of sync, without writing")
+	syncCmd.Flags().BoolVarP(&flagDryRun, "dry-run", "n", false, "Preview changes without writing")
+	syncCmd.Flags().StringSliceVar(&flagIntegrations, "integrations", nil, "AI tools to sync files for, replacing the configured set (e.g. claude,copilot,aider)")
+	syncCmd.Flags().BoolVar(&flagNoHooks, "no-hooks", false, "Do not run the hooks configured in .agentic-repo.yaml")
+	addScanFlags(syncCmd)
+}
+
+// syncEntry is a derived file and how its copy on disk compares
+type syncEntry struct {
+	file   generator.File
+	rel    string
+	onDisk []byte
+	state  manifest.State
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	absPath, err := resolveDir(args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(absPath)
+	if err != nil {
+		return err
+	}
+
+	results, err := scanRepo(cmd, cfg, absPath, true)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		results = unknownResults(absPath)
+	}
+	isMonorepo := detector.IsMonorepo(results)
+
+	integrations, err := selectedIntegrations(cfg)
+	if err != nil {
+		return err
+	}
+
+	gen := generator.New(generator.Options{
+		Force:        true,
+		Quiet:        true,
+		TemplateDirs: cfg.TemplateDirs(absPath),
+		Integrations: integrations,
+	})
+
+	m, err := manifest.Load(absPath)
+	if err != nil {
+		return err
+	}
+
+	entries, err := planSync(gen, m, absPath, results, isMonorepo)
+	if err != nil {
+		return err
+	}
+
+	if flagCheck {
+		budgetErr := checkBudgets(cfg, absPath, results)
+		if err := checkSync(entries); err != nil {
+			return err
+		}
+		return budgetErr
+	}
+
+	pulled := map[string]bool{}
+	if flagPull {
+		pulled, err = pullEdits(absPath, entries)
+		if err != nil {
+			return err
+		}
+		if len(pulled) > 0 && !flagDryRun {
+			// The rules changed, so every derived file renders anew
+			if entries, err = planSync(gen, m, absPath, results, isMonorepo); err != nil {
+				return err
+			}
+		}
+	}
+
+	written, err := applySync(gen, m, entries, pulled)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, e := range entries {
+		current[filepath.ToSlash(e.rel)] = true
+	}
+	for _, rel := range m.Paths() {
+		if !current[rel] {
+			color.New(color.FgYellow).Printf("   ⚠️  %s is no longer generated; delete it if it is unused\n", rel)
+			m.Forget(rel)
+		}
+	}
+
+	if flagDryRun {
+		color.New(color.FgGreen, color.Bold).Println("\n✓ Dry run complete (no files written)")
+		return nil
+	}
+
+	if err := m.Save(absPath); err != nil {
+		return err
+	}
+
+	if err := runHooks(cmd, cfg, hooks.PostGenerate, absPath, written); err != nil {
+		return err
+	}
+
+	if err := checkBudgets(cfg, absPath, results); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen, color.Bold).Printf("\n✓ Synced %d derived files (%d written)\n", len(entries), len(written))
+	return nil
+}
+
+// planSync renders the derived files and compares them with the disk
+func planSync(gen *generator.Generator, m *manifest.Manifest, root string, results []detector.Result, isMonorepo bool) ([]syncEntry, error) {
+	files, err := gen.RenderDerived(root, results, isMonorepo)
+	if err != nil {
+		return nil, fmt.Errorf("generation failed: %w", err)
+	}
+
+	entries := make([]syncEntry, 0, len(files))
+	for _, f := range files {
+		rel, err := filepath.Rel(root, f.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", f.Path, err)
+		}
+		onDisk, err := os.ReadFile(f.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		entries = append(entries, syncEntry{file: f, rel: rel, onDisk: onDisk, state: m.State(rel, onDisk, f.Content)})
+	}
+	return entries, nil
+}
+
+// checkSync reports every derived file that is not up to date
+func checkSync(entries []syncEntry) error {
+	outOfSync := 0
+	for _, e := range entries {
+		if e.state == manifest.UpToDate {
+			continue
+		}
+		outOfSync++
+		color.New(color.FgYellow).Printf("   ⚠️  %s: %s\n", e.rel, e.state)
+	}
+	if outOfSync > 0 {
+		return fmt.Errorf("%d derived files are out of sync; run agentic-repo sync", outOfSync)
+	}
+	color.New(color.FgGreen, color.Bold).Printf("✓ %d derived files are in sync\n", len(entries))
+	return nil
+}
+
+// pullEdits appends the lines added by hand to edited derived files to
+// the rules file and returns the files whose edits were pulled
+func pullEdits(root string, entries []syncEntry) (map[string]bool, error) {
+	path := filepath.Join(root, filepath.FromSlash(generator.RulesFile))
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", generator.RulesFile, err)
+	}
+
+	known := map[string]bool{}
+	for _, line := range strings.Split(string(existing), "\n") {
+		known[strings.TrimSpace(line)] = true
+	}
+
+	pulled := map[string]bool{}
+	var lines []string
+	for _, e := range entries {
+		if e.state != manifest.Edited {
+			continue
+		}
+		added := manifest.AddedLines(e.onDisk, e.file.Content)
+		if len(added) == 0 {
+			continue
+		}
+		pulled[e.rel] = true
+		for _, line := range added {
+			if !known[strings.TrimSpace(line)] {
+				known[strings.TrimSpace(line)] = true
+				lines = append(lines, line)
+			}
+		}
+		color.New(color.FgCyan).Printf("   ⬅  Pulled %d lines from %s\n", len(added), e.rel)
+	}
+
+	if len(lines) == 0 || flagDryRun {
+		return pulled, nil
+	}
+
+	content := strings.TrimRight(string(existing), "\n")
+	if content != "" {
+		content += "\n\n"
+	}
+	content += strings.Join(lines, "\n") + "\n"
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", generator.RulesFile, err)
+	}
+	return pulled, nil
+}
+
+// applySync writes the derived files that are missing or stale, and the
+// edited ones that were pulled or are forced, recording each in the
+// manifest. It returns the paths written.
+func applySync(gen *generator.Generator, m *manifest.Manifest, entries []syncEntry, pulled map[string]bool) ([]string, error) {
+	var written []string
+	for _, e := range entries {
+		switch {
+		case e.state == manifest.UpToDate:
+			m.Record(e.rel, e.file.Content)
+			continue
+		case e.state == manifest.Edited && !flagForce && !pulled[e.rel]:
+			color.New(color.FgYellow).Printf("   ⚠️  Skipping %s (edited by hand; use --pull or --force)\n", e.rel)
+			continue
+		}
+
+		if flagDryRun {
+			color.New(color.FgCyan).Printf("   📄 Would update: %s (%s)\n", e.rel, e.state)
+			continue
+		}
+
+		if _, err := gen.WriteFiles([]generator.File{e.file}); err != nil {
+			return written, err
+		}
+		m.Record(e.rel, e.file.Content)
+		written = append(written, e.file.Path)
+		color.New(color.FgGreen).Printf("   ✓ Updated: %s\n", e.rel)
+	}
+	return written, nil
+}
+
+// recordDerived records the derived files init wrote in the manifest, so
+// that a later sync can tell them from hand-edited ones. The content on
+// disk is recorded, as it may already differ from a fresh rendering.
+func recordDerived(gen *generator.Generator, root string, results []detector.Result, isMonorepo bool, written []string) error {
+	files, err := gen.RenderDerived(root, results, isMonorepo)
+	if err != nil {
+		return fmt.Errorf("generation failed: %w", err)
+	}
+
+	wrote := map[string]bool{}
+	for _, path := range written {
+		wrote[path] = true
+	}
+
+	m, err := manifest.Load(root)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !wrote[f.Path] {
+			continue
+		}
+		rel, err := filepath.Rel(root, f.Path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", f.Path, err)
+		}
+		content, err := os.ReadFile(f.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		m.Record(rel, content)
+	}
+	return m.Save(root)
+}
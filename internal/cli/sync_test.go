@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/manifest"
+)
+
+// initSyncRepo initializes a Go repository and returns its directory
+func initSyncRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	if err := runInit(initCmd, []string{dir}); err != nil {
+		t.Fatalf("runInit() error = %v", err)
+	}
+	return dir
+}
+
+// resetSyncFlags restores the sync flags after a test
+func resetSyncFlags(t *testing.T) {
+	t.Cleanup(func() {
+		flagForce, flagPull, flagCheck, flagDryRun = false, false, false, false
+	})
+}
+
+func TestRunInit_RecordsManifest(t *testing.T) {
+	dir := initSyncRepo(t)
+
+	m, err := manifest.Load(dir)
+	if err != nil {
+		t.Fatalf("manifest.Load() error = %v", err)
+	}
+	for _, rel := range []string{"AGENTS.md", "CLAUDE.md", ".cursor/rules/project.mdc"} {
+		if _, ok := m.Files[rel]; !ok {
+			t.Errorf("manifest should record %s, got %v", rel, m.Paths())
+		}
+	}
+	if _, ok := m.Files[".agent/stack.md"]; ok {
+		t.Error("manifest should only record derived files")
+	}
+
+	resetSyncFlags(t)
+	flagCheck = true
+	if err := runSync(syncCmd, []string{dir}); err != nil {
+		t.Errorf("sync --check right after init error = %v", err)
+	}
+}
+
+func TestRunSync_RegeneratesStaleFiles(t *testing.T) {
+	dir := initSyncRepo(t)
+	resetSyncFlags(t)
+
+	// New shared rules make every derived file stale
+	rules := filepath.Join(dir, filepath.FromSlash(generator.RulesFile))
+	os.WriteFile(rules, []byte("- Never push to main\n"), 0644)
+	os.Remove(filepath.Join(dir, "GEMINI.md"))
+
+	flagCheck = true
+	if err := runSync(syncCmd, []string{dir}); err == nil {
+		t.Error("sync --check should fail while files are stale")
+	}
+
+	flagCheck = false
+	if err := runSync(syncCmd, []string{dir}); err != nil {
+		t.Fatalf("runSync() error = %v", err)
+	}
+	for _, f := range []string{"CLAUDE.md", ".cursorrules", ".github/copilot-instructions.md"} {
+		content, _ := os.ReadFile(filepath.Join(dir, f))
+		if !strings.Contains(string(content), "Never push to main") {
+			t.Errorf("%s should embed the new rules", f)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "GEMINI.md")); err == nil {
+		t.Error("sync should only write enabled integrations")
+	}
+
+	flagCheck = true
+	if err := runSync(syncCmd, []string{dir}); err != nil {
+		t.Errorf("sync --check after sync error = %v", err)
+	}
+}
+
+func TestRunSync_HandEdits(t *testing.T) {
+	tests := []struct {
+		name      string
+		pull      bool
+		force     bool
+		wantKept  bool
+		wantRules bool
+	}{
+		{"warns and skips", false, false, true, false},
+		{"force overwrites", false, true, false, false},
+		{"pull moves edits into rules", true, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initSyncRepo(t)
+			resetSyncFlags(t)
+
+			claude := filepath.Join(dir, "CLAUDE.md")
+			original, _ := os.ReadFile(claude)
+			edited := string(original) + "\n- Use table-driven tests\n"
+			os.WriteFile(claude, []byte(edited), 0644)
+
+			flagPull, flagForce = tt.pull, tt.force
+			if err := runSync(syncCmd, []string{dir}); err != nil {
+				t.Fatalf("runSync() error = %v", err)
+			}
+
+			content, _ := os.ReadFile(claude)
+			if kept := string(content) == edited; kept != tt.wantKept {
+				t.Errorf("edit kept = %v, want %v:\n%s", kept, tt.wantKept, content)
+			}
+
+			rules, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(generator.RulesFile)))
+			if pulled := strings.Contains(string(rules), "- Use table-driven tests"); pulled != tt.wantRules {
+				t.Errorf("rules = %q, want pulled %v", rules, tt.wantRules)
+			}
+			if tt.wantRules {
+				// Pulled lines reach every tool, including the edited file
+				for _, f := range []string{"CLAUDE.md", ".github/copilot-instructions.md"} {
+					content, _ := os.ReadFile(filepath.Join(dir, f))
+					if !strings.Contains(string(content), "## Project Rules\n\n- Use table-driven tests\n") {
+						t.Errorf("%s should embed the pulled rules:\n%s", f, content)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRunSync_DryRun(t *testing.T) {
+	dir := initSyncRepo(t)
+	resetSyncFlags(t)
+
+	claude := filepath.Join(dir, "CLAUDE.md")
+	os.Remove(claude)
+
+	flagDryRun = true
+	if err := runSync(syncCmd, []string{dir}); err != nil {
+		t.Fatalf("runSync() error = %v", err)
+	}
+	if _, err := os.Stat(claude); err == nil {
+		t.Error("dry run should not write files")
+	}
+}
+
+func TestRunSync_CheckBudgets(t *testing.T) {
+	dir := initSyncRepo(t)
+	resetSyncFlags(t)
+
+	// The generated router fits the default budget the README promises
+	flagCheck = true
+	os.WriteFile(filepath.Join(dir, ".agentic-repo.yaml"), []byte("token_budgets:\n  on_exceed: fail\n"), 0644)
+	if err := runSync(syncCmd, []string{dir}); err != nil {
+		t.Fatalf("sync --check within the default budgets error = %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".agentic-repo.yaml"), []byte("token_budgets:\n  on_exceed: fail\n  files:\n    AGENTS.md: 50\n"), 0644)
+	if err := runSync(syncCmd, []string{dir}); err == nil || !strings.Contains(err.Error(), "over the token budget") {
+		t.Errorf("sync --check with AGENTS.md over budget error = %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+// Package commands extracts the commands a repository actually provides
+// from its task files: Makefile targets, package.json scripts, justfile
+// recipes, Taskfile tasks, tox environments, nox sessions and Gradle
+// tasks.
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Command is a runnable command found in a task file
+type Command struct {
+	// Name is the target, script, recipe, task or session name
+	Name string
+	// Run is the shell command that runs it, e.g. "make test"
+	Run string
+	// Description is the help text of the command, if any
+	Description string
+	// Source is the file the command was found in, e.g. "Makefile"
+	Source string
+}
+
+// source is a task file format
+type source struct {
+	// names are the file names of the format, in lookup order; only the
+	// first existing one is read
+	names []string
+	parse func(dir string, content []byte) []Command
+}
+
+// sources are the supported task files, in the order their commands are
+// listed
+var sources = []source{
+	{makefileNames, parseMakefile},
+	{[]string{"justfile", "Justfile", ".justfile"}, parseJustfile},
+	{[]string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"}, parseTaskfile},
+	{[]string{"package.json"}, parsePackageJSON},
+	{[]string{"tox.ini"}, parseTox},
+	{[]string{"noxfile.py"}, parseNoxfile},
+	{[]string{"build.gradle.kts", "build.gradle"}, parseGradle},
+}
+
+// Extract returns the commands defined by the task files in dir. Files
+// that are missing or cannot be parsed contribute no commands.
+func Extract(dir string) []Command {
+	var cmds []Command
+	for _, src := range sources {
+		for _, name := range src.names {
+			content, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			for _, cmd := range src.parse(dir, content) {
+				cmd.Source = name
+				cmds = append(cmds, cmd)
+			}
+			break
+		}
+	}
+	return cmds
+}
+
+// Group is the commands of one task file
+type Group struct {
+	Source   string
+	Commands []Command
+}
+
+// Groups splits commands by source, keeping their order
+func Groups(cmds []Command) []Group {
+	var groups []Group
+	for _, cmd := range cmds {
+		if n := len(groups); n > 0 && groups[n-1].Source == cmd.Source {
+			groups[n-1].Commands = append(groups[n-1].Commands, cmd)
+			continue
+		}
+		groups = append(groups, Group{Source: cmd.Source, Commands: []Command{cmd}})
+	}
+	return groups
+}
+
+// Lines formats the group as a shell cheat sheet, one command per line
+// with its description aligned as a comment
+func (g Group) Lines() []string {
+	width := 0
+	for _, cmd := range g.Commands {
+		width = max(width, len(cmd.Run))
+	}
+
+	lines := make([]string, 0, len(g.Commands))
+	for _, cmd := range g.Commands {
+		if cmd.Description == "" {
+			lines = append(lines, cmd.Run)
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  # %s", width, cmd.Run, cmd.Description))
+	}
+	return lines
+}
+
+// Shortcut is an everyday command shown in the AGENTS.md quick reference
+type Shortcut struct {
+	Label string
+	Run   string
+}
+
+// shortcutNames maps quick reference labels to the command names that
+// fill them, in order of preference
+var shortcutNames = []struct {
+	label string
+	names []string
+}{
+	{"Install", []string{"install", "setup", "deps", "bootstrap"}},
+	{"Build", []string{"build"}},
+	{"Test", []string{"test", "tests"}},
+	{"Lint", []string{"lint", "check"}},
+}
+
+// Shortcuts picks the install, build, test and lint commands from cmds.
+// Earlier sources win, so a Makefile target beats a package.json script.
+func Shortcuts(cmds []Command) []Shortcut {
+	var shortcuts []Shortcut
+	for _, s := range shortcutNames {
+	names:
+		for _, name := range s.names {
+			for _, cmd := range cmds {
+				if cmd.Name == name {
+					shortcuts = append(shortcuts, Shortcut{Label: s.label, Run: cmd.Run})
+					break names
+				}
+			}
+		}
+	}
+	return shortcuts
+}
+
+// firstLine returns the trimmed first non-empty line of s
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
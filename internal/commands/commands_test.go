@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// runs returns the Run field of each command
+func runs(cmds []Command) []string {
+	var out []string
+	for _, cmd := range cmds {
+		out = append(out, cmd.Run)
+	}
+	return out
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"GNUmakefile":    "test: ## Run tests\n\tgo test ./...\n",
+		"Makefile":       "ignored:\n",
+		"package.json":   `{"scripts": {"lint": "eslint ."}}`,
+		"yarn.lock":      "",
+		"noxfile.py":     "import nox\n\n@nox.session\ndef docs(session):\n    pass\n",
+		"build.gradle":   "tasks.register('dist')\n",
+		"unrelated.json": `{"scripts": {"x": "y"}}`,
+	})
+
+	cmds := Extract(dir)
+	want := []Command{
+		{Name: "test", Run: "make test", Description: "Run tests", Source: "GNUmakefile"},
+		{Name: "lint", Run: "yarn run lint", Description: "eslint .", Source: "package.json"},
+		{Name: "docs", Run: "nox -s docs", Source: "noxfile.py"},
+		{Name: "dist", Run: "gradle dist", Source: "build.gradle"},
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Errorf("Extract() = %+v, want %+v", cmds, want)
+	}
+
+	if cmds := Extract(t.TempDir()); cmds != nil {
+		t.Errorf("Extract() of an empty directory = %+v, want nil", cmds)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	cmds := []Command{
+		{Name: "build", Run: "make build", Description: "Build binary", Source: "Makefile"},
+		{Name: "clean", Run: "make clean", Source: "Makefile"},
+		{Name: "test:unit", Run: "npm run test:unit", Description: "vitest", Source: "package.json"},
+	}
+
+	groups := Groups(cmds)
+	if len(groups) != 2 || groups[0].Source != "Makefile" || groups[1].Source != "package.json" {
+		t.Fatalf("Groups() = %+v", groups)
+	}
+
+	want := []string{"make build  # Build binary", "make clean"}
+	if got := groups[0].Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestShortcuts(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmds     []Command
+		expected []Shortcut
+	}{
+		{"none", []Command{{Name: "release", Run: "make release"}}, nil},
+		{
+			"earlier source wins",
+			[]Command{
+				{Name: "test", Run: "make test"},
+				{Name: "build", Run: "make build"},
+				{Name: "test", Run: "pnpm run test"},
+				{Name: "lint", Run: "pnpm run lint"},
+			},
+			[]Shortcut{{"Build", "make build"}, {"Test", "make test"}, {"Lint", "pnpm run lint"}},
+		},
+		{
+			"preferred name wins",
+			[]Command{{Name: "deps", Run: "just deps"}, {Name: "install", Run: "just install"}},
+			[]Shortcut{{"Install", "just install"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Shortcuts(tt.cmds); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Shortcuts() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
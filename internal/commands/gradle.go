@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// gradleTask matches task declarations in Groovy and Kotlin scripts:
+	// tasks.register("x"), tasks.register<T>("x"), task x, task("x")
+	gradleTask = regexp.MustCompile(`(?:tasks\.(?:register|create)\s*(?:<[^>]*>)?\s*\(\s*["']([\w:-]+)["']|^\s*task(?:\s+|\s*\(\s*["']?)([\w-]+))`)
+	// gradleDescription matches a description assignment
+	gradleDescription = regexp.MustCompile(`\bdescription\s*=\s*["']([^"']*)["']`)
+)
+
+// parseGradle lists the tasks a Gradle build script registers, run with
+// the wrapper when the project has one. The description is taken from a
+// description assignment in the task's block.
+func parseGradle(dir string, content []byte) []Command {
+	gradle := "gradle"
+	if _, err := os.Stat(filepath.Join(dir, "gradlew")); err == nil {
+		gradle = "./gradlew"
+	}
+
+	var cmds []Command
+	seen := map[string]bool{}
+	depth, owner := 0, -1
+	for _, line := range strings.Split(string(content), "\n") {
+		if m := gradleTask.FindStringSubmatch(line); m != nil {
+			name := m[1] + m[2]
+			if !seen[name] {
+				seen[name] = true
+				cmds = append(cmds, Command{Name: name, Run: gradle + " " + name})
+				if strings.Contains(line, "{") {
+					owner = depth
+				}
+			}
+		}
+		if d := gradleDescription.FindStringSubmatch(line); d != nil && owner >= 0 && len(cmds) > 0 {
+			cmds[len(cmds)-1].Description = d[1]
+		}
+
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if owner >= 0 && depth <= owner {
+			owner = -1
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGradle(t *testing.T) {
+	content := `plugins {
+    id("java")
+}
+
+tasks.register<Copy>("dist") {
+    description = "Copy the distribution"
+    from("build/libs")
+}
+
+tasks.register("smoke") {
+    group = "verification"
+}
+
+task integrationTest(type: Test) {
+    description = 'Run integration tests'
+}
+
+task('hello')
+
+tasks.named("test") {
+    description = "Not a new task"
+}
+`
+
+	tests := []struct {
+		name    string
+		wrapper bool
+		prefix  string
+	}{
+		{"gradle on PATH", false, "gradle "},
+		{"wrapper", true, "./gradlew "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.wrapper {
+				writeFiles(t, dir, map[string]string{"gradlew": "#!/bin/sh\n"})
+			}
+			want := []Command{
+				{Name: "dist", Run: tt.prefix + "dist", Description: "Copy the distribution"},
+				{Name: "smoke", Run: tt.prefix + "smoke"},
+				{Name: "integrationTest", Run: tt.prefix + "integrationTest", Description: "Run integration tests"},
+				{Name: "hello", Run: tt.prefix + "hello"},
+			}
+			if got := parseGradle(dir, []byte(content)); !reflect.DeepEqual(got, want) {
+				t.Errorf("parseGradle() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+)
+
+// justRecipe matches a recipe header: name, optional parameters, then a
+// colon that does not start an assignment
+var justRecipe = regexp.MustCompile(`^@?([A-Za-z_][A-Za-z0-9_-]*)(?:\s+[^:]*)?:(?:[^=]|$)`)
+
+// parseJustfile lists the public recipes of a justfile. The description
+// is the comment right above the recipe, as shown by "just --list".
+func parseJustfile(_ string, content []byte) []Command {
+	var cmds []Command
+	comment := ""
+	private := false
+	for _, line := range strings.Split(string(content), "\n") {
+		switch {
+		case strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#!"):
+			comment = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			continue
+		case strings.HasPrefix(line, "["):
+			// Attributes keep the comment above them
+			private = private || strings.Contains(line, "private")
+			continue
+		}
+
+		if m := justRecipe.FindStringSubmatch(line); m != nil && !private && !strings.HasPrefix(m[1], "_") {
+			cmds = append(cmds, Command{Name: m[1], Run: "just " + m[1], Description: comment})
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			comment, private = "", false
+		}
+	}
+	return cmds
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJustfile(t *testing.T) {
+	content := `#!/usr/bin/env just --justfile
+set shell := ["bash", "-c"]
+alias b := build
+version := "1.0"
+
+default: build
+
+# Build the binary
+build:
+    go build ./...
+
+# Run tests for a package
+[group('dev')]
+test pkg="./...": build
+    # not a description
+    go test {{pkg}}
+
+# Internal helper
+[private]
+release:
+    ./release.sh
+
+_cleanup:
+    rm -rf dist
+
+@fmt:
+    gofmt -w .
+`
+	want := []Command{
+		{Name: "default", Run: "just default"},
+		{Name: "build", Run: "just build", Description: "Build the binary"},
+		{Name: "test", Run: "just test", Description: "Run tests for a package"},
+		{Name: "fmt", Run: "just fmt"},
+	}
+	if got := parseJustfile("", []byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseJustfile() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// makefileNames are the file names GNU make looks for, in its order
+var makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// makeRule matches a rule line: one or more targets, then a colon that
+// does not start an assignment
+var makeRule = regexp.MustCompile(`^([^\s:=#][^:=#]*?)\s*::?(?:[^=:]|$)`)
+
+// parseMakefile lists the targets of a Makefile. The description is a
+// "## help" comment after the prerequisites or on the line above.
+func parseMakefile(_ string, content []byte) []Command {
+	var cmds []Command
+	seen := map[string]bool{}
+	help := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "##") {
+			help = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+
+		m := makeRule.FindStringSubmatch(line)
+		if m == nil {
+			help = ""
+			continue
+		}
+		description := help
+		help = ""
+		if _, after, ok := strings.Cut(line, "##"); ok {
+			description = strings.TrimSpace(after)
+		}
+
+		for _, target := range strings.Fields(m[1]) {
+			// Special targets, pattern rules, variables and files
+			if strings.HasPrefix(target, ".") || strings.ContainsAny(target, "%$/") || seen[target] {
+				continue
+			}
+			seen[target] = true
+			cmds = append(cmds, Command{Name: target, Run: "make " + target, Description: description})
+		}
+	}
+	return cmds
+}
+
+// HasMakefile reports whether dir has a Makefile
+func HasMakefile(dir string) bool {
+	for _, name := range makefileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMakefile lists the targets of a Makefile's content, for Makefiles
+// that are not on disk yet
+func ParseMakefile(content []byte) []Command {
+	cmds := parseMakefile("", content)
+	for i := range cmds {
+		cmds[i].Source = "Makefile"
+	}
+	return cmds
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMakefile(t *testing.T) {
+	content := `.PHONY: build test
+BINARY := app
+GOFLAGS ?= -v
+export PATH := $(PWD)/bin:$(PATH)
+
+## Build the binary
+build: $(SRC)
+	go build -o $(BINARY) ./cmd/app
+
+test: build ## Run all tests
+	go test ./...
+
+lint fmt:
+	golangci-lint run
+
+%.o: %.c
+	cc -c $<
+
+bin/app: build
+
+install:: ## Install the binary
+	go install ./cmd/app
+
+test:
+	@echo duplicate
+`
+	want := []Command{
+		{Name: "build", Run: "make build", Description: "Build the binary"},
+		{Name: "test", Run: "make test", Description: "Run all tests"},
+		{Name: "lint", Run: "make lint"},
+		{Name: "fmt", Run: "make fmt"},
+		{Name: "install", Run: "make install", Description: "Install the binary"},
+	}
+	if got := parseMakefile("", []byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseMakefile() = %+v, want %+v", got, want)
+	}
+}
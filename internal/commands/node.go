@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// parsePackageJSON lists the scripts of a package.json in file order,
+// run with the package manager whose lockfile is present. Lifecycle
+// hooks such as "pretest" are folded into the scripts they wrap.
+func parsePackageJSON(dir string, content []byte) []Command {
+	var pkg struct {
+		Scripts json.RawMessage `json:"scripts"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil || len(pkg.Scripts) == 0 {
+		return nil
+	}
+	names, bodies := orderedScripts(pkg.Scripts)
+
+	pm := packageManager(dir)
+	var cmds []Command
+	for _, name := range names {
+		if isHook(name, bodies) {
+			continue
+		}
+		cmds = append(cmds, Command{Name: name, Run: pm + " run " + name, Description: bodies[name]})
+	}
+	return cmds
+}
+
+// orderedScripts decodes the scripts object keeping its key order
+func orderedScripts(raw json.RawMessage) ([]string, map[string]string) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, nil
+	}
+
+	var names []string
+	bodies := map[string]string{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return names, bodies
+		}
+		name, _ := tok.(string)
+		var body any
+		if err := dec.Decode(&body); err != nil {
+			return names, bodies
+		}
+		if s, ok := body.(string); ok && name != "" {
+			if _, dup := bodies[name]; !dup {
+				names = append(names, name)
+			}
+			bodies[name] = s
+		}
+	}
+	return names, bodies
+}
+
+// isHook reports whether a script is a pre/post hook of another script
+func isHook(name string, scripts map[string]string) bool {
+	for _, prefix := range []string{"pre", "post"} {
+		if base, ok := strings.CutPrefix(name, prefix); ok {
+			if _, exists := scripts[base]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// packageManager picks the package manager from the lockfile in dir
+func packageManager(dir string) string {
+	lockfiles := []struct{ name, manager string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+	}
+	for _, l := range lockfiles {
+		if _, err := os.Stat(filepath.Join(dir, l.name)); err == nil {
+			return l.manager
+		}
+	}
+	return "npm"
+}
@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePackageJSON(t *testing.T) {
+	content := `{
+  "name": "web",
+  "scripts": {
+    "pretest": "tsc",
+    "test": "vitest run",
+    "dev": "vite",
+    "prepare": "husky",
+    "build": "vite build"
+  }
+}`
+
+	tests := []struct {
+		name     string
+		lockfile string
+		expected []string
+	}{
+		{"npm by default", "", []string{"npm run test", "npm run dev", "npm run prepare", "npm run build"}},
+		{"pnpm lockfile", "pnpm-lock.yaml", []string{"pnpm run test", "pnpm run dev", "pnpm run prepare", "pnpm run build"}},
+		{"bun lockfile", "bun.lockb", []string{"bun run test", "bun run dev", "bun run prepare", "bun run build"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.lockfile != "" {
+				writeFiles(t, dir, map[string]string{tt.lockfile: ""})
+			}
+			cmds := parsePackageJSON(dir, []byte(content))
+			if got := runs(cmds); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parsePackageJSON() = %v, want %v", got, tt.expected)
+			}
+			if cmds[0].Description != "vitest run" {
+				t.Errorf("Description = %q, want the script body", cmds[0].Description)
+			}
+		})
+	}
+
+	for _, content := range []string{`{}`, `{"scripts": []}`, `not json`} {
+		if cmds := parsePackageJSON(t.TempDir(), []byte(content)); len(cmds) != 0 {
+			t.Errorf("parsePackageJSON(%s) = %+v, want none", content, cmds)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+)
+
+// parseTox lists the tox environments: the envlist of [tox] followed by
+// the named [testenv:x] sections, with their description settings
+func parseTox(_ string, content []byte) []Command {
+	var names []string
+	descriptions := map[string]string{}
+	add := func(name string) {
+		if _, ok := descriptions[name]; !ok && name != "" && !strings.ContainsAny(name, "{}") {
+			names = append(names, name)
+			descriptions[name] = ""
+		}
+	}
+
+	section, key := "", ""
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section, key = strings.TrimSpace(trimmed[1:len(trimmed)-1]), ""
+			if env, ok := strings.CutPrefix(section, "testenv:"); ok {
+				add(env)
+			}
+			continue
+		}
+
+		// Continuation lines of a multi-line value are indented
+		value := trimmed
+		if line[0] != ' ' && line[0] != '\t' {
+			k, v, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				continue
+			}
+			key, value = strings.TrimSpace(k), strings.TrimSpace(v)
+		}
+
+		switch {
+		case section == "tox" && key == "envlist":
+			for _, env := range strings.Split(value, ",") {
+				add(strings.TrimSpace(env))
+			}
+		case strings.HasPrefix(section, "testenv:") && key == "description" && value != "":
+			env := strings.TrimPrefix(section, "testenv:")
+			if descriptions[env] == "" {
+				descriptions[env] = value
+			}
+		}
+	}
+
+	cmds := make([]Command, 0, len(names))
+	for _, name := range names {
+		cmds = append(cmds, Command{Name: name, Run: "tox -e " + name, Description: descriptions[name]})
+	}
+	return cmds
+}
+
+var (
+	// noxSession matches a @nox.session decorator and its arguments
+	noxSession = regexp.MustCompile(`^\s*@nox\.session\b(?:\((.*)\))?`)
+	// noxName matches the name= argument of a session decorator
+	noxName = regexp.MustCompile(`\bname\s*=\s*["']([^"']+)["']`)
+	// pythonDef matches a function definition
+	pythonDef = regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)\s*\(`)
+	// docstring matches a one-line or opening docstring
+	docstring = regexp.MustCompile(`^\s*(?:"""|''')(.*?)(?:"""|''')?\s*$`)
+)
+
+// parseNoxfile lists the sessions of a noxfile.py, with the first line of
+// their docstring as the description
+func parseNoxfile(_ string, content []byte) []Command {
+	var cmds []Command
+	lines := strings.Split(string(content), "\n")
+	pending, name := false, ""
+	for i, line := range lines {
+		if m := noxSession.FindStringSubmatch(line); m != nil {
+			pending, name = true, ""
+			if n := noxName.FindStringSubmatch(m[1]); n != nil {
+				name = n[1]
+			}
+			continue
+		}
+		if !pending {
+			continue
+		}
+		m := pythonDef.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		pending = false
+		if name == "" {
+			name = m[1]
+		}
+
+		description := ""
+		if i+1 < len(lines) {
+			if d := docstring.FindStringSubmatch(lines[i+1]); d != nil {
+				description = strings.TrimSpace(d[1])
+			}
+		}
+		cmds = append(cmds, Command{Name: name, Run: "nox -s " + name, Description: description})
+	}
+	return cmds
+}
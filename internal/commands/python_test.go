@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTox(t *testing.T) {
+	content := `[tox]
+envlist = py311, py312,
+    lint
+    py{38,39}-django
+
+[testenv]
+deps = pytest
+commands = pytest
+
+[testenv:lint]
+description = run linters
+commands = ruff check .
+
+[testenv:docs]
+; build the docs
+commands = sphinx-build docs docs/_build
+`
+	want := []Command{
+		{Name: "py311", Run: "tox -e py311"},
+		{Name: "py312", Run: "tox -e py312"},
+		{Name: "lint", Run: "tox -e lint", Description: "run linters"},
+		{Name: "docs", Run: "tox -e docs"},
+	}
+	if got := parseTox("", []byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTox() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseNoxfile(t *testing.T) {
+	content := `import nox
+
+
+@nox.session(python=["3.11", "3.12"])
+def tests(session):
+    """Run the test suite."""
+    session.run("pytest")
+
+
+@nox.session(name="type-check", reuse_venv=True)
+def mypy(session):
+    """
+    Multi-line docstring.
+    """
+    session.run("mypy", ".")
+
+
+def helper():
+    pass
+
+
+@nox.session
+def lint(session):
+    session.run("ruff", "check", ".")
+`
+	want := []Command{
+		{Name: "tests", Run: "nox -s tests", Description: "Run the test suite."},
+		{Name: "type-check", Run: "nox -s type-check"},
+		{Name: "lint", Run: "nox -s lint"},
+	}
+	if got := parseNoxfile("", []byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNoxfile() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// parseTaskfile lists the public tasks of a Taskfile in file order, with
+// their desc as the description
+func parseTaskfile(_ string, content []byte) []Command {
+	var file struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil || file.Tasks.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var cmds []Command
+	nodes := file.Tasks.Content
+	for i := 0; i+1 < len(nodes); i += 2 {
+		name := nodes[i].Value
+		var task struct {
+			Desc     string `yaml:"desc"`
+			Summary  string `yaml:"summary"`
+			Internal bool   `yaml:"internal"`
+		}
+		// Tasks may also be a bare command list or string
+		if nodes[i+1].Kind == yaml.MappingNode {
+			if err := nodes[i+1].Decode(&task); err != nil {
+				continue
+			}
+		}
+		if task.Internal {
+			continue
+		}
+		description := task.Desc
+		if description == "" {
+			description = firstLine(task.Summary)
+		}
+		cmds = append(cmds, Command{Name: name, Run: "task " + name, Description: description})
+	}
+	return cmds
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTaskfile(t *testing.T) {
+	content := `version: '3'
+
+tasks:
+  build:
+    desc: Build the binary
+    cmds:
+      - go build ./...
+  test:
+    summary: |
+      Run the test suite
+
+      Uses the race detector.
+    cmds:
+      - go test -race ./...
+  generate:
+    internal: true
+    cmds:
+      - go generate ./...
+  fmt: gofmt -w .
+`
+	want := []Command{
+		{Name: "build", Run: "task build", Description: "Build the binary"},
+		{Name: "test", Run: "task test", Description: "Run the test suite"},
+		{Name: "fmt", Run: "task fmt"},
+	}
+	if got := parseTaskfile("", []byte(content)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTaskfile() = %+v, want %+v", got, want)
+	}
+
+	if got := parseTaskfile("", []byte("version: '3'\n")); got != nil {
+		t.Errorf("parseTaskfile() without tasks = %+v, want nil", got)
+	}
+}
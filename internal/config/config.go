@@ -0,0 +1,365 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/hooks"
+	"github.com/Shaked/agentic-repo/internal/plugin"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the repository configuration file, read from the root
+const FileName = ".agentic-repo.yaml"
+
+// Config is the repository configuration. Every section is optional; a
+// missing file is the same as an empty one.
+type Config struct {
+	// Detectors declares detectors for in-house stacks
+	Detectors []DetectorConfig `yaml:"detectors"`
+	// DetectorOrder moves these stacks to the front of the priority order
+	DetectorOrder []string `yaml:"detector_order"`
+	// DisableDetectors removes built-in or declared detectors by stack
+	DisableDetectors []string `yaml:"disable_detectors"`
+	// Plugins are external detector and generator executables
+	Plugins []PluginConfig `yaml:"plugins"`
+	// Hooks are shell commands run around detection and generation
+	Hooks HooksConfig `yaml:"hooks"`
+	// Integrations turns the files for individual AI tools on or off by
+	// name; unlisted integrations keep their default
+	Integrations map[string]bool `yaml:"integrations"`
+	// TokenBudgets caps the tokens of the AGENTS.md routers and the
+	// .agent/ files
+	TokenBudgets TokenBudgetsConfig `yaml:"token_budgets"`
+}
+
+// TokenBudgetsConfig sets token limits on context files
+type TokenBudgetsConfig struct {
+	// OnExceed is warn (default) or fail
+	OnExceed string `yaml:"on_exceed"`
+	// Files maps globs, matched against paths relative to each project
+	// such as AGENTS.md or .agent/*.md, to token limits. They are merged
+	// over DefaultTokenBudgets; 0 removes a limit.
+	Files map[string]int `yaml:"files"`
+}
+
+// DefaultTokenBudgets are the limits applied without configuration
+var DefaultTokenBudgets = map[string]int{
+	"AGENTS.md":   500,
+	".agent/*.md": 2000,
+}
+
+// DetectorConfig declares a pattern-based detector
+type DetectorConfig struct {
+	// Stack is the stack name reported on a match
+	Stack string `yaml:"stack"`
+	// Files are globs matched against directory entry names
+	Files []string `yaml:"files"`
+	// Contains are content rules; each match adds evidence
+	Contains []ContentConfig `yaml:"contains"`
+	// Weight is the confidence each piece of evidence adds; defaults to 1
+	Weight float64 `yaml:"weight"`
+	// Templates is a directory, relative to the repository root, holding
+	// this stack's templates (stack.md.tmpl, testing.md.tmpl, ...).
+	// Missing templates fall back to the generic ones.
+	Templates string `yaml:"templates"`
+}
+
+// ContentConfig matches files by name glob and content regexp
+type ContentConfig struct {
+	File  string `yaml:"file"`
+	Regex string `yaml:"regex"`
+}
+
+// PluginConfig declares a JSON-over-stdio plugin
+type PluginConfig struct {
+	// Name identifies the plugin in messages
+	Name string `yaml:"name"`
+	// Kind is "detector" or "generator"
+	Kind string `yaml:"kind"`
+	// Command is the executable; defaults to agentic-repo-<name> on PATH.
+	// Paths containing a separator are relative to the repository root.
+	Command string `yaml:"command"`
+	// Args are passed to the executable
+	Args []string `yaml:"args"`
+}
+
+// HooksConfig lists the hooks for each stage
+type HooksConfig struct {
+	PreDetect    []HookConfig `yaml:"pre_detect"`
+	PostGenerate []HookConfig `yaml:"post_generate"`
+}
+
+// HookConfig declares a shell command hook
+type HookConfig struct {
+	// Run is the shell command
+	Run string `yaml:"run"`
+	// OnFailure is abort (default), warn or ignore
+	OnFailure string `yaml:"on_failure"`
+	// Timeout is a duration such as "30s"; defaults to five minutes
+	Timeout string `yaml:"timeout"`
+}
+
+// Load reads the configuration from the repository root
+func Load(root string) (*Config, error) {
+	content, err := os.ReadFile(filepath.Join(root, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	return Parse(content)
+}
+
+// Parse decodes a configuration document. Unknown keys are rejected so
+// typos do not silently disable settings.
+func Parse(content []byte) (*Config, error) {
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FileName, err)
+	}
+	return cfg, nil
+}
+
+// validate checks settings that decoding alone cannot
+func (c *Config) validate() error {
+	seen := map[string]bool{}
+	for i, d := range c.Detectors {
+		if d.Stack == "" {
+			return fmt.Errorf("detectors[%d]: stack is required", i)
+		}
+		if seen[d.Stack] {
+			return fmt.Errorf("detectors[%d]: stack %q declared twice", i, d.Stack)
+		}
+		seen[d.Stack] = true
+	}
+
+	names := map[string]bool{}
+	for i, p := range c.Plugins {
+		if p.Name == "" {
+			return fmt.Errorf("plugins[%d]: name is required", i)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("plugins[%d]: plugin %q declared twice", i, p.Name)
+		}
+		names[p.Name] = true
+		if kind := plugin.Kind(p.Kind); kind != plugin.KindDetector && kind != plugin.KindGenerator {
+			return fmt.Errorf("plugins[%d]: kind must be %q or %q", i, plugin.KindDetector, plugin.KindGenerator)
+		}
+	}
+
+	for name := range c.Integrations {
+		if _, err := generator.ParseIntegration(name); err != nil {
+			return fmt.Errorf("integrations: %w", err)
+		}
+	}
+
+	for _, stage := range []hooks.Stage{hooks.PreDetect, hooks.PostGenerate} {
+		if _, err := c.hooks(stage); err != nil {
+			return err
+		}
+	}
+
+	switch c.TokenBudgets.OnExceed {
+	case "", "warn", "fail":
+	default:
+		return fmt.Errorf("token_budgets: on_exceed must be warn or fail, not %q", c.TokenBudgets.OnExceed)
+	}
+	for pattern, limit := range c.TokenBudgets.Files {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("token_budgets: invalid pattern %q", pattern)
+		}
+		if limit < 0 {
+			return fmt.Errorf("token_budgets: limit for %q must not be negative", pattern)
+		}
+	}
+	return nil
+}
+
+// Registry builds the detector registry for this repository: the default
+// detectors plus the declared ones, minus the disabled ones, in the
+// configured order
+func (c *Config) Registry() (*detector.Registry, error) {
+	reg := detector.DefaultRegistry().Clone()
+
+	for _, d := range c.Detectors {
+		var contains [][2]string
+		for _, rule := range d.Contains {
+			contains = append(contains, [2]string{rule.File, rule.Regex})
+		}
+		weight := d.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		pd, err := detector.NewPatternDetector(detector.StackType(d.Stack), d.Files, contains, weight)
+		if err != nil {
+			return nil, err
+		}
+		reg.Register(pd)
+	}
+
+	for _, stack := range c.DisableDetectors {
+		reg.Unregister(detector.StackType(stack))
+	}
+
+	var order []detector.StackType
+	for _, stack := range c.DetectorOrder {
+		order = append(order, detector.StackType(stack))
+	}
+	if err := reg.SetOrder(order...); err != nil {
+		return nil, fmt.Errorf("detector_order: %w", err)
+	}
+
+	return reg, nil
+}
+
+// TemplateDirs maps declared stacks to their absolute template directory
+func (c *Config) TemplateDirs(root string) map[detector.StackType]string {
+	dirs := map[detector.StackType]string{}
+	for _, d := range c.Detectors {
+		if d.Templates == "" {
+			continue
+		}
+		dir := d.Templates
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		dirs[detector.StackType(d.Stack)] = dir
+	}
+	return dirs
+}
+
+// ResolvedPlugins returns the configured plugins with their commands
+// resolved against root
+func (c *Config) ResolvedPlugins(root string) []plugin.Plugin {
+	plugins := make([]plugin.Plugin, 0, len(c.Plugins))
+	for _, p := range c.Plugins {
+		command := p.Command
+		if command == "" {
+			command = plugin.Prefix + p.Name
+		} else if strings.ContainsRune(command, '/') || strings.ContainsRune(command, filepath.Separator) {
+			if !filepath.IsAbs(command) {
+				command = filepath.Join(root, command)
+			}
+		}
+		plugins = append(plugins, plugin.Plugin{
+			Name:    p.Name,
+			Kind:    plugin.Kind(p.Kind),
+			Command: command,
+			Args:    p.Args,
+		})
+	}
+	return plugins
+}
+
+// StageHooks returns the hooks configured for a stage. The configuration
+// was validated by Parse, so conversion cannot fail here.
+func (c *Config) StageHooks(stage hooks.Stage) []hooks.Hook {
+	list, _ := c.hooks(stage)
+	return list
+}
+
+// hooks converts the configured hooks for a stage
+func (c *Config) hooks(stage hooks.Stage) ([]hooks.Hook, error) {
+	var configured []HookConfig
+	switch stage {
+	case hooks.PreDetect:
+		configured = c.Hooks.PreDetect
+	case hooks.PostGenerate:
+		configured = c.Hooks.PostGenerate
+	}
+
+	list := make([]hooks.Hook, 0, len(configured))
+	for i, h := range configured {
+		if strings.TrimSpace(h.Run) == "" {
+			return nil, fmt.Errorf("hooks.%s[%d]: run is required", stage, i)
+		}
+		policy, err := hooks.ParsePolicy(h.OnFailure)
+		if err != nil {
+			return nil, fmt.Errorf("hooks.%s[%d]: %w", stage, i, err)
+		}
+		var timeout time.Duration
+		if h.Timeout != "" {
+			timeout, err = time.ParseDuration(h.Timeout)
+			if err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("hooks.%s[%d]: invalid timeout %q", stage, i, h.Timeout)
+			}
+		}
+		list = append(list, hooks.Hook{Run: h.Run, OnFailure: policy, Timeout: timeout})
+	}
+	return list, nil
+}
+
+// EnabledIntegrations returns the AI tool integrations to generate: the
+// defaults with the configured toggles applied
+func (c *Config) EnabledIntegrations() []generator.Integration {
+	defaults := map[generator.Integration]bool{}
+	for _, i := range generator.DefaultIntegrations() {
+		defaults[i] = true
+	}
+
+	enabled := []generator.Integration{}
+	for _, i := range generator.KnownIntegrations() {
+		on, ok := c.Integrations[string(i)]
+		if !ok {
+			on = defaults[i]
+		}
+		if on {
+			enabled = append(enabled, i)
+		}
+	}
+	return enabled
+}
+
+// TokenBudget returns the token limit of a context file, given by its
+// path relative to its project, or 0 if it has none. An exact path beats
+// a glob, and a longer glob beats a shorter one;
+// between globs of the same length the first in sort order wins.
+func (c *Config) TokenBudget(rel string) int {
+	budgets := map[string]int{}
+	for pattern, limit := range DefaultTokenBudgets {
+		budgets[pattern] = limit
+	}
+	for pattern, limit := range c.TokenBudgets.Files {
+		budgets[pattern] = limit
+	}
+
+	if limit, ok := budgets[rel]; ok {
+		return limit
+	}
+	patterns := make([]string, 0, len(budgets))
+	for pattern := range budgets {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	best := ""
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok && len(pattern) > len(best) {
+			best = pattern
+		}
+	}
+	return budgets[best]
+}
+
+// FailOverBudget reports whether exceeding a token budget is an error
+// rather than a warning
+func (c *Config) FailOverBudget() bool {
+	return c.TokenBudgets.OnExceed == "fail"
+}
@@ -0,0 +1,357 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/hooks"
+	"github.com/Shaked/agentic-repo/internal/plugin"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "empty document",
+			content: "",
+		},
+		{
+			name: "declared detector",
+			content: `detectors:
+  - stack: bazel
+    files: [WORKSPACE, "*.bzl"]
+    contains:
+      - file: BUILD*
+        regex: go_library
+    weight: 0.7
+    templates: .agentic/bazel
+detector_order: [bazel]
+disable_detectors: [java]
+`,
+		},
+		{
+			name:    "unknown key",
+			content: "detectorz: []\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing stack",
+			content: "detectors:\n  - files: [WORKSPACE]\n",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate stack",
+			content: "detectors:\n  - stack: bazel\n    files: [WORKSPACE]\n  - stack: bazel\n    files: [BUILD]\n",
+			wantErr: true,
+		},
+		{
+			name:    "token budgets",
+			content: "token_budgets:\n  on_exceed: fail\n  files:\n    AGENTS.md: 100\n",
+		},
+		{
+			name:    "unknown budget policy",
+			content: "token_budgets:\n  on_exceed: abort\n",
+			wantErr: true,
+		},
+		{
+			name:    "negative budget",
+			content: "token_budgets:\n  files:\n    AGENTS.md: -1\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid budget pattern",
+			content: "token_budgets:\n  files:\n    \"[\": 10\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(t.TempDir())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, &Config{}) {
+		t.Errorf("Load() = %+v, want empty config", cfg)
+	}
+}
+
+func TestLoad_ReadsFile(t *testing.T) {
+	root := t.TempDir()
+	content := "detectors:\n  - stack: bazel\n    files: [WORKSPACE]\n"
+	if err := os.WriteFile(filepath.Join(root, FileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Detectors) != 1 || cfg.Detectors[0].Stack != "bazel" {
+		t.Errorf("Detectors = %+v, want one bazel detector", cfg.Detectors)
+	}
+}
+
+func TestConfig_Registry(t *testing.T) {
+	cfg, err := Parse([]byte(`detectors:
+  - stack: bazel
+    files: [WORKSPACE]
+detector_order: [bazel, node]
+disable_detectors: [java]
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	reg, err := cfg.Registry()
+	if err != nil {
+		t.Fatalf("Registry() error = %v", err)
+	}
+
+	want := []detector.StackType{"bazel", detector.StackNode, detector.StackGo, detector.StackPython}
+	if got := reg.Stacks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stacks() = %v, want %v", got, want)
+	}
+
+	// The default registry is left untouched
+	if len(detector.DefaultRegistry().Stacks()) != 4 {
+		t.Error("Registry() modified the default registry")
+	}
+}
+
+func TestConfig_RegistryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"detector without rules", "detectors:\n  - stack: bazel\n"},
+		{"invalid regex", "detectors:\n  - stack: bazel\n    contains:\n      - file: BUILD\n        regex: \"(\"\n"},
+		{"unknown stack in order", "detector_order: [rust]\n"},
+		{"disabled stack in order", "disable_detectors: [go]\ndetector_order: [go]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if _, err := cfg.Registry(); err == nil {
+				t.Error("Registry() expected error")
+			}
+		})
+	}
+}
+
+func TestConfig_TemplateDirs(t *testing.T) {
+	cfg := &Config{Detectors: []DetectorConfig{
+		{Stack: "bazel", Templates: ".agentic/bazel"},
+		{Stack: "nix", Templates: "/opt/templates/nix"},
+		{Stack: "make"},
+	}}
+
+	got := cfg.TemplateDirs("/repo")
+	want := map[detector.StackType]string{
+		"bazel": filepath.Join("/repo", ".agentic/bazel"),
+		"nix":   "/opt/templates/nix",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TemplateDirs() = %v, want %v", got, want)
+	}
+}
+
+func TestParse_Plugins(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", "plugins:\n  - name: docs\n    kind: generator\n", false},
+		{"missing name", "plugins:\n  - kind: generator\n", true},
+		{"unknown kind", "plugins:\n  - name: docs\n    kind: linter\n", true},
+		{"duplicate name", "plugins:\n  - name: docs\n    kind: generator\n  - name: docs\n    kind: detector\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_ResolvedPlugins(t *testing.T) {
+	cfg := &Config{Plugins: []PluginConfig{
+		{Name: "docs", Kind: "generator"},
+		{Name: "local", Kind: "detector", Command: "tools/detect.sh", Args: []string{"-v"}},
+		{Name: "abs", Kind: "detector", Command: "/usr/local/bin/detect"},
+		{Name: "path", Kind: "generator", Command: "acme-gen"},
+	}}
+
+	got := cfg.ResolvedPlugins("/repo")
+	want := []plugin.Plugin{
+		{Name: "docs", Kind: plugin.KindGenerator, Command: "agentic-repo-docs"},
+		{Name: "local", Kind: plugin.KindDetector, Command: filepath.Join("/repo", "tools/detect.sh"), Args: []string{"-v"}},
+		{Name: "abs", Kind: plugin.KindDetector, Command: "/usr/local/bin/detect"},
+		{Name: "path", Kind: plugin.KindGenerator, Command: "acme-gen"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolvedPlugins() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParse_Hooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", "hooks:\n  post_generate:\n    - run: npx prettier --write .\n      on_failure: warn\n      timeout: 30s\n", false},
+		{"missing run", "hooks:\n  pre_detect:\n    - on_failure: warn\n", true},
+		{"unknown policy", "hooks:\n  pre_detect:\n    - run: make\n      on_failure: retry\n", true},
+		{"invalid timeout", "hooks:\n  pre_detect:\n    - run: make\n      timeout: soon\n", true},
+		{"unknown stage", "hooks:\n  post_detect:\n    - run: make\n", true},
+		{"unknown integration", "integrations:\n  vim: true\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_StageHooks(t *testing.T) {
+	cfg, err := Parse([]byte(`hooks:
+  pre_detect:
+    - run: make generate
+  post_generate:
+    - run: pre-commit install
+      on_failure: ignore
+      timeout: 1m
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := cfg.StageHooks(hooks.PreDetect); !reflect.DeepEqual(got, []hooks.Hook{{Run: "make generate", OnFailure: hooks.Abort}}) {
+		t.Errorf("StageHooks(pre_detect) = %+v", got)
+	}
+	want := []hooks.Hook{{Run: "pre-commit install", OnFailure: hooks.Ignore, Timeout: time.Minute}}
+	if got := cfg.StageHooks(hooks.PostGenerate); !reflect.DeepEqual(got, want) {
+		t.Errorf("StageHooks(post_generate) = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfig_EnabledIntegrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []generator.Integration
+	}{
+		{
+			name:     "all enabled by default",
+			content:  "",
+			expected: []generator.Integration{generator.IntegrationCursor, generator.IntegrationCursorLegacy, generator.IntegrationClaude, generator.IntegrationCopilot},
+		},
+		{
+			name:     "copilot disabled",
+			content:  "integrations:\n  copilot: false\n",
+			expected: []generator.Integration{generator.IntegrationCursor, generator.IntegrationCursorLegacy, generator.IntegrationClaude},
+		},
+		{
+			name:     "legacy cursorrules disabled",
+			content:  "integrations:\n  cursor_legacy: false\n",
+			expected: []generator.Integration{generator.IntegrationCursor, generator.IntegrationClaude, generator.IntegrationCopilot},
+		},
+		{
+			name:     "opt-in integrations",
+			content:  "integrations:\n  aider: true\n  gemini: true\n  mcp: true\n",
+			expected: []generator.Integration{generator.IntegrationCursor, generator.IntegrationCursorLegacy, generator.IntegrationClaude, generator.IntegrationCopilot, generator.IntegrationAider, generator.IntegrationGemini, generator.IntegrationMCP},
+		},
+		{
+			name:     "all disabled",
+			content:  "integrations:\n  cursor: false\n  cursor_legacy: false\n  claude: false\n  copilot: false\n  mcp: false\n",
+			expected: []generator.Integration{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := cfg.EnabledIntegrations(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("EnabledIntegrations() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConfig_TokenBudget(t *testing.T) {
+	cfg, err := Parse([]byte("token_budgets:\n  files:\n    AGENTS.md: 100\n    .agent/architecture.md: 4000\n    .agent/*.md: 1500\n    .agent/notes*.md: 0\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		rel  string
+		want int
+	}{
+		{"AGENTS.md", 100},
+		{".agent/architecture.md", 4000},
+		{".agent/stack.md", 1500},
+		{".agent/notes-2024.md", 0},
+		{"CLAUDE.md", 0},
+	}
+	for _, tt := range tests {
+		if got := cfg.TokenBudget(tt.rel); got != tt.want {
+			t.Errorf("TokenBudget(%q) = %d, want %d", tt.rel, got, tt.want)
+		}
+	}
+
+	// Globs of the same length resolve in sort order, on every call
+	tied, err := Parse([]byte("token_budgets:\n  files:\n    .agent/a*.md: 100\n    .agent/*a.md: 200\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if got := tied.TokenBudget(".agent/aa.md"); got != 200 {
+			t.Fatalf("TokenBudget(.agent/aa.md) = %d, want 200 from .agent/*a.md", got)
+		}
+	}
+
+	empty := &Config{}
+	if got := empty.TokenBudget("AGENTS.md"); got != DefaultTokenBudgets["AGENTS.md"] {
+		t.Errorf("default TokenBudget(AGENTS.md) = %d", got)
+	}
+	if empty.FailOverBudget() || cfg.FailOverBudget() {
+		t.Error("FailOverBudget() should be false unless on_exceed is fail")
+	}
+	cfg.TokenBudgets.OnExceed = "fail"
+	if !cfg.FailOverBudget() {
+		t.Error("FailOverBudget() with on_exceed: fail should be true")
+	}
+}
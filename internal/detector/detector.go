@@ -1,8 +1,8 @@
 package detector
 
 import (
-	"os"
 	"path/filepath"
+	"sort"
 )
 
 // StackType represents a detected project stack
@@ -24,83 +24,120 @@ func (s StackType) String() string {
 type Result struct {
 	Path  string
 	Stack StackType
+	// Confidence of the winning detection, between 0 and 1
+	Confidence float64
+	// Evidence lists the files that led to Stack
+	Evidence []string
+	// Alternatives are other stacks that also passed the threshold,
+	// strongest first
+	Alternatives []Detection
+	// Tools describes the project's tooling, e.g. "package_manager": "pnpm"
+	Tools map[string]string
+}
+
+// Explain returns the winning detection for the result
+func (r Result) Explain() Detection {
+	return Detection{Stack: r.Stack, Confidence: r.Confidence, Evidence: r.Evidence}
 }
 
 // Detector interface for stack-specific detection
 type Detector interface {
 	// Detect checks if the given directory contains this stack type
 	Detect(path string) bool
+	// Match scores an already-read directory listing for this stack type
+	Match(dir *Dir) Detection
 	// Type returns the stack type this detector identifies
 	Type() StackType
 }
 
-// All registered detectors in priority order
-var detectors = []Detector{
-	&GoDetector{},
-	&PythonDetector{},
-	&NodeDetector{},
-	&JavaDetector{},
+// ToolDetector is implemented by detectors that can describe the tooling
+// of a project they detected
+type ToolDetector interface {
+	// Tools returns metadata such as "package_manager" or "build_tool"
+	Tools(dir *Dir) map[string]string
 }
 
-// Scan recursively scans a directory for project types
-func Scan(root string) ([]Result, error) {
-	var results []Result
-
-	// Check root directory first
-	if stack := detectStack(root); stack != StackUnknown {
-		results = append(results, Result{Path: root, Stack: stack})
-	}
-
-	// Scan subdirectories (depth 1-2)
-	entries, err := os.ReadDir(root)
+// detectStack checks a single directory for any known stack
+func detectStack(path string) StackType {
+	dir, err := ReadDir(path)
 	if err != nil {
-		return nil, err
+		return StackUnknown
+	}
+	if r, ok := detectDir(dir, defaultRegistry, 0); ok {
+		return r.Stack
 	}
+	return StackUnknown
+}
 
-	for _, entry := range entries {
-		if !entry.IsDir() || isIgnoredDir(entry.Name()) {
-			continue
+// detectDir scores a directory listing with every detector in the registry
+// and returns the strongest detection at or above minConfidence. Ties go
+// to the detector registered first.
+func detectDir(dir *Dir, registry *Registry, minConfidence float64) (Result, bool) {
+	var matches []Detection
+	byStack := map[StackType]Detector{}
+	for _, d := range registry.Detectors() {
+		det := d.Match(dir)
+		if det.Matched() && det.Confidence >= minConfidence {
+			matches = append(matches, det)
+			byStack[det.Stack] = d
 		}
+	}
+	if len(matches) == 0 {
+		return Result{}, false
+	}
 
-		subPath := filepath.Join(root, entry.Name())
-
-		// Check this subdirectory
-		if stack := detectStack(subPath); stack != StackUnknown {
-			results = append(results, Result{Path: subPath, Stack: stack})
-		}
+	rank(matches)
+	best := matches[0]
+	r := Result{
+		Path:         dir.Path,
+		Stack:        best.Stack,
+		Confidence:   best.Confidence,
+		Evidence:     best.Evidence,
+		Alternatives: matches[1:],
+	}
+	if td, ok := byStack[best.Stack].(ToolDetector); ok {
+		r.Tools = td.Tools(dir)
+	}
+	return r, true
+}
 
-		// Check one level deeper for monorepo structures like services/api/
-		subEntries, err := os.ReadDir(subPath)
-		if err != nil {
+// Merge adds extra results, e.g. from detector plugins, to scan results.
+// An extra result for a path that is already detected wins if it is at
+// least as confident; the weaker one becomes an alternative. The merged
+// results are in depth-first path order.
+func Merge(results []Result, extra ...Result) []Result {
+	merged := append([]Result(nil), results...)
+	for _, e := range extra {
+		i := indexOfPath(merged, e.Path)
+		if i < 0 {
+			merged = append(merged, e)
 			continue
 		}
-
-		for _, subEntry := range subEntries {
-			if !subEntry.IsDir() || isIgnoredDir(subEntry.Name()) {
-				continue
-			}
-
-			deepPath := filepath.Join(subPath, subEntry.Name())
-			if stack := detectStack(deepPath); stack != StackUnknown {
-				results = append(results, Result{Path: deepPath, Stack: stack})
-			}
+		existing := merged[i]
+		if e.Confidence < existing.Confidence {
+			existing.Alternatives = append(existing.Alternatives, e.Explain())
+			rank(existing.Alternatives)
+			merged[i] = existing
+			continue
 		}
+		e.Alternatives = append([]Detection{existing.Explain()}, existing.Alternatives...)
+		merged[i] = e
 	}
 
-	// Deduplicate - if root is detected, remove subdirectory matches of same type
-	results = deduplicateResults(results, root)
-
-	return results, nil
+	sort.SliceStable(merged, func(i, j int) bool {
+		return lessPath(filepath.ToSlash(merged[i].Path), filepath.ToSlash(merged[j].Path))
+	})
+	return merged
 }
 
-// detectStack checks a single directory for any known stack
-func detectStack(path string) StackType {
-	for _, d := range detectors {
-		if d.Detect(path) {
-			return d.Type()
+// indexOfPath returns the index of the result for path, or -1
+func indexOfPath(results []Result, path string) int {
+	for i, r := range results {
+		if r.Path == path {
+			return i
 		}
 	}
-	return StackUnknown
+	return -1
 }
 
 // IsMonorepo determines if the results indicate a monorepo structure
@@ -114,25 +151,6 @@ func IsMonorepo(results []Result) bool {
 	return true
 }
 
-// isIgnoredDir returns true for directories that should be skipped
-func isIgnoredDir(name string) bool {
-	ignored := map[string]bool{
-		".git":         true,
-		".agent":       true,
-		".claude":      true,
-		"node_modules": true,
-		"vendor":       true,
-		"venv":         true,
-		".venv":        true,
-		"__pycache__":  true,
-		"target":       true, // Java/Rust
-		"bin":          true,
-		"dist":         true,
-		"build":        true,
-	}
-	return ignored[name]
-}
-
 // deduplicateResults removes redundant detections
 func deduplicateResults(results []Result, root string) []Result {
 	if len(results) <= 1 {
@@ -3,6 +3,7 @@ package detector
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -168,35 +169,35 @@ func TestIsMonorepo(t *testing.T) {
 	}
 }
 
-func TestIsIgnoredDir(t *testing.T) {
+func TestScan_DefaultExcludes(t *testing.T) {
+	files := []string{"go.mod", "web/package.json", "vendor/sdk/package.json", "build/tool/pom.xml"}
 	tests := []struct {
-		name     string
-		dirName  string
-		expected bool
+		name string
+		opts func(*ScanOptions)
+		want []string
 	}{
-		{"git directory", ".git", true},
-		{"agent directory", ".agent", true},
-		{"claude directory", ".claude", true},
-		{"node_modules", "node_modules", true},
-		{"vendor", "vendor", true},
-		{"venv", "venv", true},
-		{"dot venv", ".venv", true},
-		{"pycache", "__pycache__", true},
-		{"target", "target", true},
-		{"bin", "bin", true},
-		{"dist", "dist", true},
-		{"build", "build", true},
-		{"src directory", "src", false},
-		{"cmd directory", "cmd", false},
-		{"internal directory", "internal", false},
-		{"services directory", "services", false},
+		{"skipped by default", func(*ScanOptions) {}, []string{".", "web"}},
+		{"--no-ignore scans them", func(o *ScanOptions) { o.NoIgnoreFiles = true }, []string{".", "build/tool", "vendor/sdk", "web"}},
+		{"--include reaches into one", func(o *ScanOptions) { o.Include = []string{"vendor/sdk"} }, []string{".", "vendor/sdk"}},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isIgnoredDir(tt.dirName)
-			if result != tt.expected {
-				t.Errorf("isIgnoredDir(%q) = %v, want %v", tt.dirName, result, tt.expected)
+			dir := createTempProject(t, files)
+			opts := DefaultScanOptions()
+			tt.opts(&opts)
+
+			results, err := ScanWithOptions(dir, opts)
+			if err != nil {
+				t.Fatalf("ScanWithOptions() error = %v", err)
+			}
+			var got []string
+			for _, r := range results {
+				rel, _ := filepath.Rel(dir, r.Path)
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("projects = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -350,3 +351,68 @@ func TestScan_IgnoresSpecialDirectories(t *testing.T) {
 		t.Errorf("expected Go stack, got %v", results[0].Stack)
 	}
 }
+
+// markerDetector detects a custom stack by a single marker file
+type markerDetector struct {
+	stack  StackType
+	marker string
+}
+
+func (d *markerDetector) Detect(path string) bool {
+	dir, err := ReadDir(path)
+	return err == nil && d.Match(dir).Matched()
+}
+
+func (d *markerDetector) Match(dir *Dir) Detection {
+	return score(dir, d.stack, []indicator{{name: d.marker, weight: 1}})
+}
+
+func (d *markerDetector) Type() StackType {
+	return d.stack
+}
+
+func TestRegister(t *testing.T) {
+	saved := defaultRegistry
+	defaultRegistry = saved.Clone()
+	t.Cleanup(func() { defaultRegistry = saved })
+
+	Register(&markerDetector{stack: "bazel", marker: "WORKSPACE.test-register"})
+
+	dir := createTempProject(t, []string{"WORKSPACE.test-register"})
+	if got := detectStack(dir); got != "bazel" {
+		t.Errorf("detectStack() = %v, want bazel", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	results := []Result{
+		{Path: "/repo/api", Stack: StackGo, Confidence: 0.9},
+		{Path: "/repo/web", Stack: StackNode, Confidence: 0.9},
+	}
+
+	merged := Merge(results,
+		Result{Path: "/repo/api", Stack: "bazel", Confidence: 1},
+		Result{Path: "/repo/web", Stack: "bazel", Confidence: 0.5},
+		Result{Path: "/repo/tools", Stack: "bazel", Confidence: 1},
+		Result{Path: "/repo", Stack: "bazel", Confidence: 1},
+	)
+
+	var got []string
+	for _, r := range merged {
+		got = append(got, r.Path+":"+string(r.Stack))
+	}
+	want := []string{"/repo:bazel", "/repo/api:bazel", "/repo/tools:bazel", "/repo/web:node"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+
+	if alts := merged[1].Alternatives; len(alts) != 1 || alts[0].Stack != StackGo {
+		t.Errorf("api alternatives = %v, want the replaced go detection", alts)
+	}
+	if alts := merged[3].Alternatives; len(alts) != 1 || alts[0].Stack != "bazel" {
+		t.Errorf("web alternatives = %v, want the weaker bazel detection", alts)
+	}
+	if results[0].Stack != StackGo {
+		t.Error("Merge() modified its input")
+	}
+}
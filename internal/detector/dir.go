@@ -0,0 +1,83 @@
+package detector
+
+import (
+	"os"
+	"sort"
+)
+
+// Dir is a single directory listing. It is read once per directory and
+// shared by every detector, so detection costs one ReadDir instead of one
+// Stat per indicator file.
+type Dir struct {
+	Path    string
+	entries map[string]bool // name -> isDir
+}
+
+// ReadDir lists the directory at path
+func ReadDir(path string) (*Dir, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	return newDir(path, entries), nil
+}
+
+// newDir builds a Dir from an existing listing
+func newDir(path string, entries []os.DirEntry) *Dir {
+	d := &Dir{Path: path, entries: make(map[string]bool, len(entries))}
+	for _, e := range entries {
+		d.entries[e.Name()] = e.IsDir()
+	}
+	return d
+}
+
+// Has reports whether the directory contains an entry with this name
+func (d *Dir) Has(name string) bool {
+	_, ok := d.entries[name]
+	return ok
+}
+
+// HasFile reports whether the directory contains a non-directory entry
+func (d *Dir) HasFile(name string) bool {
+	isDir, ok := d.entries[name]
+	return ok && !isDir
+}
+
+// HasDir reports whether the directory contains a subdirectory
+func (d *Dir) HasDir(name string) bool {
+	return d.entries[name]
+}
+
+// Subdirs returns the names of subdirectories in lexical order
+func (d *Dir) Subdirs() []string {
+	var names []string
+	for name, isDir := range d.entries {
+		if isDir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Files returns the names of non-directory entries in lexical order
+func (d *Dir) Files() []string {
+	var names []string
+	for name, isDir := range d.entries {
+		if !isDir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// names returns all entry names in lexical order
+func (d *Dir) names() []string {
+	names := make([]string, 0, len(d.entries))
+	for name := range d.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
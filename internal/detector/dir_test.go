@@ -0,0 +1,52 @@
+package detector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadDir(t *testing.T) {
+	dir := createTempProject(t, []string{"go.mod", "main.go", "cmd/app/main.go", "internal/x.go"})
+
+	d, err := ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		check   func(string) bool
+		entry   string
+		expects bool
+	}{
+		{"Has file", d.Has, "go.mod", true},
+		{"Has dir", d.Has, "cmd", true},
+		{"Has missing", d.Has, "package.json", false},
+		{"HasFile on file", d.HasFile, "main.go", true},
+		{"HasFile on dir", d.HasFile, "cmd", false},
+		{"HasDir on dir", d.HasDir, "internal", true},
+		{"HasDir on file", d.HasDir, "go.mod", false},
+		{"HasDir on nested path", d.HasDir, "cmd/app", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check(tt.entry); got != tt.expects {
+				t.Errorf("%s(%q) = %v, want %v", tt.name, tt.entry, got, tt.expects)
+			}
+		})
+	}
+
+	if got, want := d.Subdirs(), []string{"cmd", "internal"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Subdirs() = %v, want %v", got, want)
+	}
+	if got, want := d.Files(), []string{"go.mod", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Files() = %v, want %v", got, want)
+	}
+}
+
+func TestReadDir_Missing(t *testing.T) {
+	if _, err := ReadDir("/nonexistent/path/that/does/not/exist"); err == nil {
+		t.Error("expected error for non-existent directory")
+	}
+}
@@ -0,0 +1,107 @@
+package detector
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultMinConfidence is the confidence a detection needs to count as a
+// project. A single weak indicator, such as a stray requirements.txt,
+// stays below it.
+const DefaultMinConfidence = 0.5
+
+// Detection is how strongly a directory matched one detector, and why
+type Detection struct {
+	Stack StackType
+	// Confidence is between 0 (no evidence) and 1 (certain)
+	Confidence float64
+	// Evidence lists the files that contributed to the score
+	Evidence []string
+}
+
+// Matched reports whether the detector found any evidence at all
+func (d Detection) Matched() bool {
+	return d.Confidence > 0
+}
+
+// String formats the detection for humans, e.g. "go (0.84: go.mod, go.sum)"
+func (d Detection) String() string {
+	return fmt.Sprintf("%s (%.2f: %s)", d.Stack, d.Confidence, strings.Join(d.Evidence, ", "))
+}
+
+// indicator is a file or directory whose presence suggests a stack
+type indicator struct {
+	name   string
+	weight float64
+	dir    bool // must be a directory rather than a file
+}
+
+// strongWeight is the weight from which an indicator is evidence of a
+// project on its own
+const strongWeight = DefaultMinConfidence
+
+// sourceHint boosts a detection when files with this extension are present
+type sourceHint struct {
+	ext    string
+	weight float64
+}
+
+// score combines the weights of the indicators found in dir. Independent
+// pieces of evidence are combined as 1 - Π(1 - w), so several weak hints
+// add up without ever exceeding 1. Source files only reinforce a
+// detection backed by a strong indicator; on their own, or next to a weak
+// one such as the requirements.txt of a Sphinx docs/ folder, they are not
+// evidence of a project.
+func score(dir *Dir, stack StackType, indicators []indicator, hints ...sourceHint) Detection {
+	det := Detection{Stack: stack}
+	miss := 1.0
+	strong := false
+
+	for _, ind := range indicators {
+		found := dir.Has(ind.name)
+		if ind.dir {
+			found = dir.HasDir(ind.name)
+		}
+		if found {
+			miss *= 1 - ind.weight
+			det.Evidence = append(det.Evidence, ind.name)
+			strong = strong || ind.weight >= strongWeight
+		}
+	}
+
+	if !strong {
+		det.Confidence = 1 - miss
+		return det
+	}
+
+	for _, hint := range hints {
+		if n := countExt(dir, hint.ext); n > 0 {
+			miss *= 1 - hint.weight
+			det.Evidence = append(det.Evidence, fmt.Sprintf("*%s (%d files)", hint.ext, n))
+		}
+	}
+
+	det.Confidence = 1 - miss
+	return det
+}
+
+// countExt counts files in dir with the given extension
+func countExt(dir *Dir, ext string) int {
+	n := 0
+	for _, name := range dir.Files() {
+		if filepath.Ext(name) == ext {
+			n++
+		}
+	}
+	return n
+}
+
+// rank sorts detections by confidence, keeping detector priority order
+// for ties
+func rank(detections []Detection) {
+	sort.SliceStable(detections, func(i, j int) bool {
+		return detections[i].Confidence > detections[j].Confidence
+	})
+}
@@ -0,0 +1,199 @@
+package detector
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	indicators := []indicator{
+		{name: "strong.txt", weight: 0.9},
+		{name: "weak.txt", weight: 0.4},
+		{name: "tooldir", weight: 0.5, dir: true},
+	}
+
+	tests := []struct {
+		name               string
+		files              []string
+		dirs               []string
+		expectedConfidence float64
+		expectedEvidence   []string
+	}{
+		{
+			name:               "no evidence",
+			files:              []string{"README.md"},
+			expectedConfidence: 0,
+		},
+		{
+			name:               "single strong indicator",
+			files:              []string{"strong.txt"},
+			expectedConfidence: 0.9,
+			expectedEvidence:   []string{"strong.txt"},
+		},
+		{
+			name:               "indicators combine",
+			files:              []string{"strong.txt", "weak.txt"},
+			expectedConfidence: 1 - 0.1*0.6,
+			expectedEvidence:   []string{"strong.txt", "weak.txt"},
+		},
+		{
+			name:               "source files reinforce a strong match",
+			files:              []string{"strong.txt", "a.src", "b.src"},
+			expectedConfidence: 1 - 0.1*0.5,
+			expectedEvidence:   []string{"strong.txt", "*.src (2 files)"},
+		},
+		{
+			name:               "source files do not reinforce a weak match",
+			files:              []string{"weak.txt", "a.src", "b.src"},
+			expectedConfidence: 0.4,
+			expectedEvidence:   []string{"weak.txt"},
+		},
+		{
+			name:               "source files alone are not evidence",
+			files:              []string{"a.src"},
+			expectedConfidence: 0,
+		},
+		{
+			name:               "directory indicator requires a directory",
+			files:              []string{"tooldir"},
+			expectedConfidence: 0,
+		},
+		{
+			name:               "directory indicator matches directory",
+			dirs:               []string{"tooldir"},
+			expectedConfidence: 0.5,
+			expectedEvidence:   []string{"tooldir"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := createTempProject(t, tt.files)
+			for _, d := range tt.dirs {
+				mkdir(t, path, d)
+			}
+			dir, err := ReadDir(path)
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+
+			det := score(dir, StackGo, indicators, sourceHint{".src", 0.5})
+			if math.Abs(det.Confidence-tt.expectedConfidence) > 1e-9 {
+				t.Errorf("Confidence = %v, want %v", det.Confidence, tt.expectedConfidence)
+			}
+			if !reflect.DeepEqual(det.Evidence, tt.expectedEvidence) {
+				t.Errorf("Evidence = %v, want %v", det.Evidence, tt.expectedEvidence)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	detections := []Detection{
+		{Stack: StackGo, Confidence: 0.6},
+		{Stack: StackPython, Confidence: 0.9},
+		{Stack: StackNode, Confidence: 0.6},
+	}
+	rank(detections)
+
+	want := []StackType{StackPython, StackGo, StackNode}
+	for i, d := range detections {
+		if d.Stack != want[i] {
+			t.Errorf("rank[%d] = %s, want %s", i, d.Stack, want[i])
+		}
+	}
+}
+
+func TestDetection_String(t *testing.T) {
+	d := Detection{Stack: StackGo, Confidence: 0.84, Evidence: []string{"go.mod", "go.sum"}}
+	if got, want := d.String(), "go (0.84: go.mod, go.sum)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDetectDir_Threshold(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         []string
+		minConfidence float64
+		expectOK      bool
+		expectedStack StackType
+		expectedAlts  int
+	}{
+		{
+			name:          "stray requirements.txt is below default threshold",
+			files:         []string{"requirements.txt", "index.md"},
+			minConfidence: DefaultMinConfidence,
+			expectOK:      false,
+		},
+		{
+			name:          "requirements.txt with Python sources stays below threshold",
+			files:         []string{"requirements.txt", "app.py"},
+			minConfidence: DefaultMinConfidence,
+			expectOK:      false,
+		},
+		{
+			name:          "Sphinx docs folder is not a Python project",
+			files:         []string{"requirements.txt", "conf.py", "index.rst"},
+			minConfidence: DefaultMinConfidence,
+			expectOK:      false,
+		},
+		{
+			name:          "setup.cfg with Python sources passes",
+			files:         []string{"setup.cfg", "app.py"},
+			minConfidence: DefaultMinConfidence,
+			expectOK:      true,
+			expectedStack: StackPython,
+		},
+		{
+			name:          "zero threshold accepts any evidence",
+			files:         []string{"requirements.txt"},
+			minConfidence: 0,
+			expectOK:      true,
+			expectedStack: StackPython,
+		},
+		{
+			name:          "stronger evidence beats priority order",
+			files:         []string{"requirements.txt", "package.json"},
+			minConfidence: 0,
+			expectOK:      true,
+			expectedStack: StackNode,
+			expectedAlts:  1,
+		},
+		{
+			name:          "alternatives below threshold are dropped",
+			files:         []string{"go.mod", "requirements.txt"},
+			minConfidence: DefaultMinConfidence,
+			expectOK:      true,
+			expectedStack: StackGo,
+			expectedAlts:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ReadDir(createTempProject(t, tt.files))
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+
+			r, ok := detectDir(dir, defaultRegistry, tt.minConfidence)
+			if ok != tt.expectOK {
+				t.Fatalf("detectDir() ok = %v, want %v", ok, tt.expectOK)
+			}
+			if !ok {
+				return
+			}
+			if r.Stack != tt.expectedStack {
+				t.Errorf("Stack = %s, want %s", r.Stack, tt.expectedStack)
+			}
+			if len(r.Alternatives) != tt.expectedAlts {
+				t.Errorf("got %d alternatives, want %d", len(r.Alternatives), tt.expectedAlts)
+			}
+			if len(r.Evidence) == 0 {
+				t.Error("expected evidence for a detection")
+			}
+		})
+	}
+}
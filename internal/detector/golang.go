@@ -3,6 +3,7 @@ package detector
 import (
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // GoDetector detects Go projects
@@ -10,21 +11,48 @@ type GoDetector struct{}
 
 // Detect checks for Go project indicators
 func (d *GoDetector) Detect(path string) bool {
-	indicators := []string{
-		"go.mod",
-		"go.sum",
-	}
-
-	for _, indicator := range indicators {
-		if _, err := os.Stat(filepath.Join(path, indicator)); err == nil {
-			return true
-		}
+	dir, err := ReadDir(path)
+	if err != nil {
+		return false
 	}
+	return d.Match(dir).Matched()
+}
 
-	return false
+// Match scores a directory listing for Go project indicators
+func (d *GoDetector) Match(dir *Dir) Detection {
+	return score(dir, StackGo, []indicator{
+		{name: "go.mod", weight: 1.0},
+		{name: "go.sum", weight: 0.6},
+	}, sourceHint{".go", 0.3})
 }
 
 // Type returns the stack type
 func (d *GoDetector) Type() StackType {
 	return StackGo
 }
+
+// Tools reports the Go toolchain metadata for a detected project
+func (d *GoDetector) Tools(dir *Dir) map[string]string {
+	tools := map[string]string{"package_manager": "go modules"}
+	if dir.HasFile("go.work") {
+		tools["workspace"] = "go.work"
+	}
+	if version := goVersion(filepath.Join(dir.Path, "go.mod")); version != "" {
+		tools["language_version"] = version
+	}
+	return tools
+}
+
+// goVersion reads the go directive from a go.mod file
+func goVersion(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -61,3 +62,27 @@ func TestGoDetector_Type(t *testing.T) {
 		t.Errorf("GoDetector.Type() = %v, want %v", detector.Type(), StackGo)
 	}
 }
+
+func TestGoDetector_Tools(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string
+	}{
+		{"go modules", map[string]string{"go.mod": "module x\n\ngo 1.22\n"}, map[string]string{"package_manager": "go modules", "language_version": "1.22"}},
+		{"workspace", map[string]string{"go.mod": "module x\n", "go.work": "go 1.22\n"}, map[string]string{"package_manager": "go modules", "workspace": "go.work"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ReadDir(createTempFiles(t, tt.files))
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			got := (&GoDetector{}).Tools(dir)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("GoDetector.Tools() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,38 +1,44 @@
 package detector
 
-import (
-	"os"
-	"path/filepath"
-)
-
 // JavaDetector detects Java projects
 type JavaDetector struct{}
 
 // Detect checks for Java project indicators
 func (d *JavaDetector) Detect(path string) bool {
-	indicators := []string{
-		"pom.xml",
-		"build.gradle",
-		"build.gradle.kts",
-		"settings.gradle",
-		"settings.gradle.kts",
-	}
-
-	for _, indicator := range indicators {
-		if _, err := os.Stat(filepath.Join(path, indicator)); err == nil {
-			return true
-		}
-	}
-
-	// Also check for .mvn directory (Maven Wrapper)
-	if _, err := os.Stat(filepath.Join(path, ".mvn")); err == nil {
-		return true
+	dir, err := ReadDir(path)
+	if err != nil {
+		return false
 	}
+	return d.Match(dir).Matched()
+}
 
-	return false
+// Match scores a directory listing for Java project indicators
+func (d *JavaDetector) Match(dir *Dir) Detection {
+	return score(dir, StackJava, []indicator{
+		{name: "pom.xml", weight: 1.0},
+		{name: "build.gradle", weight: 0.9},
+		{name: "build.gradle.kts", weight: 0.9},
+		{name: "settings.gradle", weight: 0.7},
+		{name: "settings.gradle.kts", weight: 0.7},
+		// Maven Wrapper
+		{name: ".mvn", weight: 0.6, dir: true},
+	}, sourceHint{".java", 0.3})
 }
 
 // Type returns the stack type
 func (d *JavaDetector) Type() StackType {
 	return StackJava
 }
+
+// Tools reports the build tool for a detected project
+func (d *JavaDetector) Tools(dir *Dir) map[string]string {
+	tools := map[string]string{"build_tool": "maven"}
+	if !dir.Has("pom.xml") && (dir.Has("build.gradle") || dir.Has("build.gradle.kts") ||
+		dir.Has("settings.gradle") || dir.Has("settings.gradle.kts")) {
+		tools["build_tool"] = "gradle"
+	}
+	if dir.HasFile("mvnw") || dir.HasFile("gradlew") {
+		tools["wrapper"] = "true"
+	}
+	return tools
+}
@@ -3,6 +3,7 @@ package detector
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -105,3 +106,27 @@ func TestJavaDetector_Type(t *testing.T) {
 		t.Errorf("JavaDetector.Type() = %v, want %v", detector.Type(), StackJava)
 	}
 }
+
+func TestJavaDetector_Tools(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string
+	}{
+		{"maven wrapper", map[string]string{"pom.xml": "", "mvnw": ""}, map[string]string{"build_tool": "maven", "wrapper": "true"}},
+		{"gradle", map[string]string{"build.gradle.kts": ""}, map[string]string{"build_tool": "gradle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ReadDir(createTempFiles(t, tt.files))
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			got := (&JavaDetector{}).Tools(dir)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("JavaDetector.Tools() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,33 +1,45 @@
 package detector
 
-import (
-	"os"
-	"path/filepath"
-)
-
 // NodeDetector detects Node.js/TypeScript projects
 type NodeDetector struct{}
 
 // Detect checks for Node.js/TypeScript project indicators
 func (d *NodeDetector) Detect(path string) bool {
-	indicators := []string{
-		"package.json",
-		"pnpm-lock.yaml",
-		"package-lock.json",
-		"yarn.lock",
-		"tsconfig.json",
-	}
-
-	for _, indicator := range indicators {
-		if _, err := os.Stat(filepath.Join(path, indicator)); err == nil {
-			return true
-		}
+	dir, err := ReadDir(path)
+	if err != nil {
+		return false
 	}
+	return d.Match(dir).Matched()
+}
 
-	return false
+// Match scores a directory listing for Node.js/TypeScript project indicators
+func (d *NodeDetector) Match(dir *Dir) Detection {
+	return score(dir, StackNode, []indicator{
+		{name: "package.json", weight: 0.9},
+		{name: "pnpm-lock.yaml", weight: 0.8},
+		{name: "package-lock.json", weight: 0.8},
+		{name: "yarn.lock", weight: 0.8},
+		{name: "tsconfig.json", weight: 0.6},
+	}, sourceHint{".ts", 0.2}, sourceHint{".js", 0.2})
 }
 
 // Type returns the stack type
 func (d *NodeDetector) Type() StackType {
 	return StackNode
 }
+
+// Tools reports the package manager and language for a detected project
+func (d *NodeDetector) Tools(dir *Dir) map[string]string {
+	manager := "npm"
+	switch {
+	case dir.Has("pnpm-lock.yaml"):
+		manager = "pnpm"
+	case dir.Has("yarn.lock"):
+		manager = "yarn"
+	}
+	language := "javascript"
+	if dir.Has("tsconfig.json") {
+		language = "typescript"
+	}
+	return map[string]string{"package_manager": manager, "language": language}
+}
@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -76,3 +77,28 @@ func TestNodeDetector_Type(t *testing.T) {
 		t.Errorf("NodeDetector.Type() = %v, want %v", detector.Type(), StackNode)
 	}
 }
+
+func TestNodeDetector_Tools(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string
+	}{
+		{"pnpm typescript", map[string]string{"package.json": "", "pnpm-lock.yaml": "", "tsconfig.json": ""}, map[string]string{"package_manager": "pnpm", "language": "typescript"}},
+		{"yarn", map[string]string{"package.json": "", "yarn.lock": ""}, map[string]string{"package_manager": "yarn", "language": "javascript"}},
+		{"npm", map[string]string{"package.json": ""}, map[string]string{"package_manager": "npm", "language": "javascript"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ReadDir(createTempFiles(t, tt.files))
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			got := (&NodeDetector{}).Tools(dir)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("NodeDetector.Tools() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
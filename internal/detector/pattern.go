@@ -0,0 +1,129 @@
+package detector
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+)
+
+// maxContentBytes bounds how much of a file content rules read
+const maxContentBytes = 1 << 20
+
+// ContentRule matches files in a directory whose content matches a regexp
+type ContentRule struct {
+	// File is a glob matched against entry names, e.g. "BUILD*"
+	File string
+	// Regex is matched against the first megabyte of each matching file
+	Regex *regexp.Regexp
+}
+
+// PatternDetector is a declarative detector: it matches file name globs
+// and content regexps instead of running code. It lets repositories teach
+// the scanner about in-house stacks through configuration.
+type PatternDetector struct {
+	// Stack is the stack reported on a match
+	Stack StackType
+	// Files are globs matched against the names of directory entries
+	Files []string
+	// Contains are content rules; each one that matches adds evidence
+	Contains []ContentRule
+	// Weight is the confidence contributed by each piece of evidence
+	Weight float64
+}
+
+// NewPatternDetector builds a PatternDetector, compiling content regexps
+// given as file glob -> expression pairs in order
+func NewPatternDetector(stack StackType, files []string, contains [][2]string, weight float64) (*PatternDetector, error) {
+	if stack == "" {
+		return nil, fmt.Errorf("pattern detector needs a stack name")
+	}
+	if len(files) == 0 && len(contains) == 0 {
+		return nil, fmt.Errorf("pattern detector %q needs files or contains rules", stack)
+	}
+	if weight <= 0 || weight > 1 {
+		return nil, fmt.Errorf("pattern detector %q: weight must be in (0, 1], got %v", stack, weight)
+	}
+
+	for _, glob := range files {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("pattern detector %q: bad file glob %q: %w", stack, glob, err)
+		}
+	}
+
+	d := &PatternDetector{Stack: stack, Files: files, Weight: weight}
+	for _, rule := range contains {
+		if _, err := path.Match(rule[0], ""); err != nil {
+			return nil, fmt.Errorf("pattern detector %q: bad file glob %q: %w", stack, rule[0], err)
+		}
+		re, err := regexp.Compile(rule[1])
+		if err != nil {
+			return nil, fmt.Errorf("pattern detector %q: bad regex %q: %w", stack, rule[1], err)
+		}
+		d.Contains = append(d.Contains, ContentRule{File: rule[0], Regex: re})
+	}
+	return d, nil
+}
+
+// Detect checks for the configured patterns
+func (d *PatternDetector) Detect(path string) bool {
+	dir, err := ReadDir(path)
+	if err != nil {
+		return false
+	}
+	return d.Match(dir).Matched()
+}
+
+// Match scores a directory listing against the configured patterns
+func (d *PatternDetector) Match(dir *Dir) Detection {
+	det := Detection{Stack: d.Stack}
+	miss := 1.0
+
+	for _, glob := range d.Files {
+		for _, name := range dir.names() {
+			if ok, _ := path.Match(glob, name); ok {
+				miss *= 1 - d.Weight
+				det.Evidence = append(det.Evidence, name)
+			}
+		}
+	}
+
+	for _, rule := range d.Contains {
+		for _, name := range dir.Files() {
+			if ok, _ := path.Match(rule.File, name); !ok {
+				continue
+			}
+			if fileContains(filepath.Join(dir.Path, name), rule.Regex) {
+				miss *= 1 - d.Weight
+				det.Evidence = append(det.Evidence, fmt.Sprintf("%s =~ /%s/", name, rule.Regex))
+			}
+		}
+	}
+
+	if len(det.Evidence) > 0 {
+		det.Confidence = 1 - miss
+	}
+	return det
+}
+
+// Type returns the configured stack
+func (d *PatternDetector) Type() StackType {
+	return d.Stack
+}
+
+// fileContains reports whether the head of a file matches re
+func fileContains(path string, re *regexp.Regexp) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, maxContentBytes))
+	if err != nil {
+		return false
+	}
+	return re.Match(content)
+}
@@ -0,0 +1,111 @@
+package detector
+
+import (
+	"testing"
+)
+
+func TestNewPatternDetector_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		stack    StackType
+		files    []string
+		contains [][2]string
+		weight   float64
+	}{
+		{"missing stack", "", []string{"WORKSPACE"}, nil, 1},
+		{"no rules", "bazel", nil, nil, 1},
+		{"zero weight", "bazel", []string{"WORKSPACE"}, nil, 0},
+		{"weight above one", "bazel", []string{"WORKSPACE"}, nil, 1.5},
+		{"bad glob", "bazel", []string{"[WORKSPACE"}, nil, 1},
+		{"bad regex", "bazel", nil, [][2]string{{"BUILD", "go_library("}}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPatternDetector(tt.stack, tt.files, tt.contains, tt.weight); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPatternDetector_Match(t *testing.T) {
+	d, err := NewPatternDetector("bazel",
+		[]string{"WORKSPACE", "*.bzl"},
+		[][2]string{{"BUILD*", `go_(library|binary)\(`}},
+		0.6,
+	)
+	if err != nil {
+		t.Fatalf("NewPatternDetector() error = %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		files            map[string]string
+		expectMatch      bool
+		expectedEvidence int
+	}{
+		{
+			name:             "file glob",
+			files:            map[string]string{"defs.bzl": ""},
+			expectMatch:      true,
+			expectedEvidence: 1,
+		},
+		{
+			name:             "content rule",
+			files:            map[string]string{"BUILD.bazel": "go_library(\n  name = \"x\",\n)"},
+			expectMatch:      true,
+			expectedEvidence: 1,
+		},
+		{
+			name:        "content rule without match",
+			files:       map[string]string{"BUILD": "py_library()"},
+			expectMatch: false,
+		},
+		{
+			name:             "evidence combines",
+			files:            map[string]string{"WORKSPACE": "", "defs.bzl": "", "BUILD": "go_binary()"},
+			expectMatch:      true,
+			expectedEvidence: 3,
+		},
+		{
+			name:        "no match",
+			files:       map[string]string{"go.mod": ""},
+			expectMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ReadDir(createTempFiles(t, tt.files))
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+
+			det := d.Match(dir)
+			if det.Matched() != tt.expectMatch {
+				t.Fatalf("Matched() = %v, want %v (%v)", det.Matched(), tt.expectMatch, det)
+			}
+			if len(det.Evidence) != tt.expectedEvidence {
+				t.Errorf("evidence = %v, want %d items", det.Evidence, tt.expectedEvidence)
+			}
+			if det.Stack != "bazel" {
+				t.Errorf("Stack = %s, want bazel", det.Stack)
+			}
+		})
+	}
+}
+
+func TestPatternDetector_CombinedConfidence(t *testing.T) {
+	d, _ := NewPatternDetector("bazel", []string{"WORKSPACE", "MODULE.bazel"}, nil, 0.6)
+
+	one, _ := ReadDir(createTempProject(t, []string{"WORKSPACE"}))
+	two, _ := ReadDir(createTempProject(t, []string{"WORKSPACE", "MODULE.bazel"}))
+
+	if c := d.Match(one).Confidence; c < 0.59 || c > 0.61 {
+		t.Errorf("single match confidence = %v, want 0.6", c)
+	}
+	if c := d.Match(two).Confidence; c < 0.83 || c > 0.85 {
+		t.Errorf("double match confidence = %v, want 0.84", c)
+	}
+}
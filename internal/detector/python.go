@@ -1,35 +1,46 @@
 package detector
 
-import (
-	"os"
-	"path/filepath"
-)
-
 // PythonDetector detects Python projects
 type PythonDetector struct{}
 
 // Detect checks for Python project indicators
 func (d *PythonDetector) Detect(path string) bool {
-	indicators := []string{
-		"pyproject.toml",
-		"requirements.txt",
-		"setup.py",
-		"setup.cfg",
-		"uv.lock",
-		"poetry.lock",
-		"Pipfile",
-	}
-
-	for _, indicator := range indicators {
-		if _, err := os.Stat(filepath.Join(path, indicator)); err == nil {
-			return true
-		}
+	dir, err := ReadDir(path)
+	if err != nil {
+		return false
 	}
+	return d.Match(dir).Matched()
+}
 
-	return false
+// Match scores a directory listing for Python project indicators
+func (d *PythonDetector) Match(dir *Dir) Detection {
+	return score(dir, StackPython, []indicator{
+		{name: "pyproject.toml", weight: 0.9},
+		{name: "setup.py", weight: 0.9},
+		{name: "setup.cfg", weight: 0.6},
+		{name: "uv.lock", weight: 0.8},
+		{name: "poetry.lock", weight: 0.8},
+		{name: "Pipfile", weight: 0.8},
+		// requirements.txt alone is common in docs and tooling folders
+		{name: "requirements.txt", weight: 0.4},
+	}, sourceHint{".py", 0.3})
 }
 
 // Type returns the stack type
 func (d *PythonDetector) Type() StackType {
 	return StackPython
 }
+
+// Tools reports the Python packaging metadata for a detected project
+func (d *PythonDetector) Tools(dir *Dir) map[string]string {
+	manager := "pip"
+	switch {
+	case dir.Has("uv.lock"):
+		manager = "uv"
+	case dir.Has("poetry.lock"):
+		manager = "poetry"
+	case dir.Has("Pipfile"):
+		manager = "pipenv"
+	}
+	return map[string]string{"package_manager": manager}
+}
@@ -1,6 +1,7 @@
 package detector
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -86,3 +87,29 @@ func TestPythonDetector_Type(t *testing.T) {
 		t.Errorf("PythonDetector.Type() = %v, want %v", detector.Type(), StackPython)
 	}
 }
+
+func TestPythonDetector_Tools(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string
+	}{
+		{"uv", map[string]string{"pyproject.toml": "", "uv.lock": ""}, map[string]string{"package_manager": "uv"}},
+		{"poetry", map[string]string{"pyproject.toml": "", "poetry.lock": ""}, map[string]string{"package_manager": "poetry"}},
+		{"pipenv", map[string]string{"Pipfile": ""}, map[string]string{"package_manager": "pipenv"}},
+		{"pip", map[string]string{"requirements.txt": ""}, map[string]string{"package_manager": "pip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ReadDir(createTempFiles(t, tt.files))
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			got := (&PythonDetector{}).Tools(dir)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("PythonDetector.Tools() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
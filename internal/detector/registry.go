@@ -0,0 +1,142 @@
+package detector
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry is an ordered set of detectors. When several detectors match a
+// directory with the same confidence, the one registered first wins. All
+// methods are safe for concurrent use, including while a scan is running.
+type Registry struct {
+	mu        sync.RWMutex
+	detectors []Detector
+}
+
+// NewRegistry creates a registry holding the given detectors in order
+func NewRegistry(detectors ...Detector) *Registry {
+	return &Registry{detectors: append([]Detector(nil), detectors...)}
+}
+
+// BuiltinDetectors returns fresh instances of the built-in detectors in
+// their default priority order
+func BuiltinDetectors() []Detector {
+	return []Detector{
+		&GoDetector{},
+		&PythonDetector{},
+		&NodeDetector{},
+		&JavaDetector{},
+	}
+}
+
+// defaultRegistry is used by Scan and by scans without an explicit registry
+var defaultRegistry = NewRegistry(BuiltinDetectors()...)
+
+// DefaultRegistry returns the process-wide registry used when
+// ScanOptions.Registry is nil
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a detector to the default registry
+func Register(d Detector) {
+	defaultRegistry.Register(d)
+}
+
+// Register adds a detector at the end of the registry. A detector for a
+// stack that is already registered replaces the existing one in place.
+func (r *Registry) Register(d Detector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.detectors {
+		if existing.Type() == d.Type() {
+			r.detectors[i] = d
+			return
+		}
+	}
+	r.detectors = append(r.detectors, d)
+}
+
+// Prepend adds a detector at the front of the registry, giving it the
+// highest priority. An existing detector for the same stack is removed.
+func (r *Registry) Prepend(d Detector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.detectors = append([]Detector{d}, without(r.detectors, d.Type())...)
+}
+
+// Unregister removes the detector for a stack and reports whether one was
+// registered
+func (r *Registry) Unregister(stack StackType) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := len(r.detectors)
+	r.detectors = without(r.detectors, stack)
+	return len(r.detectors) != n
+}
+
+// SetOrder moves the named stacks to the front in the given order. Stacks
+// that are not named keep their relative order after them.
+func (r *Registry) SetOrder(stacks ...StackType) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	byStack := map[StackType]Detector{}
+	for _, d := range r.detectors {
+		byStack[d.Type()] = d
+	}
+
+	var ordered []Detector
+	named := map[StackType]bool{}
+	for _, stack := range stacks {
+		d, ok := byStack[stack]
+		if !ok {
+			return fmt.Errorf("no detector registered for stack %q", stack)
+		}
+		if named[stack] {
+			return fmt.Errorf("stack %q listed twice", stack)
+		}
+		named[stack] = true
+		ordered = append(ordered, d)
+	}
+	for _, d := range r.detectors {
+		if !named[d.Type()] {
+			ordered = append(ordered, d)
+		}
+	}
+
+	r.detectors = ordered
+	return nil
+}
+
+// Clone returns an independent copy of the registry
+func (r *Registry) Clone() *Registry {
+	return NewRegistry(r.Detectors()...)
+}
+
+// Detectors returns a snapshot of the registered detectors in order
+func (r *Registry) Detectors() []Detector {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Detector(nil), r.detectors...)
+}
+
+// Stacks returns the registered stacks in priority order
+func (r *Registry) Stacks() []StackType {
+	var stacks []StackType
+	for _, d := range r.Detectors() {
+		stacks = append(stacks, d.Type())
+	}
+	return stacks
+}
+
+// without returns detectors minus the one for stack
+func without(detectors []Detector, stack StackType) []Detector {
+	var out []Detector
+	for _, d := range detectors {
+		if d.Type() != stack {
+			out = append(out, d)
+		}
+	}
+	return out
+}
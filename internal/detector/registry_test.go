@@ -0,0 +1,131 @@
+package detector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry_Register(t *testing.T) {
+	reg := NewRegistry(BuiltinDetectors()...)
+
+	reg.Register(&markerDetector{stack: "bazel", marker: "WORKSPACE"})
+	want := []StackType{StackGo, StackPython, StackNode, StackJava, "bazel"}
+	if got := reg.Stacks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stacks() = %v, want %v", got, want)
+	}
+
+	// Registering an existing stack replaces it in place
+	replacement := &markerDetector{stack: StackPython, marker: "pixi.toml"}
+	reg.Register(replacement)
+	if got := reg.Stacks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stacks() after replace = %v, want %v", got, want)
+	}
+	if reg.Detectors()[1] != replacement {
+		t.Error("expected python detector to be replaced")
+	}
+}
+
+func TestRegistry_Prepend(t *testing.T) {
+	reg := NewRegistry(BuiltinDetectors()...)
+	reg.Prepend(&markerDetector{stack: StackJava, marker: "pom.xml"})
+
+	want := []StackType{StackJava, StackGo, StackPython, StackNode}
+	if got := reg.Stacks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stacks() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_Unregister(t *testing.T) {
+	reg := NewRegistry(BuiltinDetectors()...)
+
+	if !reg.Unregister(StackPython) {
+		t.Error("Unregister(python) = false, want true")
+	}
+	if reg.Unregister(StackPython) {
+		t.Error("second Unregister(python) = true, want false")
+	}
+
+	want := []StackType{StackGo, StackNode, StackJava}
+	if got := reg.Stacks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Stacks() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_SetOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []StackType
+		want    []StackType
+		wantErr bool
+	}{
+		{
+			name:  "moves named stacks to the front",
+			order: []StackType{StackJava, StackNode},
+			want:  []StackType{StackJava, StackNode, StackGo, StackPython},
+		},
+		{
+			name:  "empty order keeps everything",
+			order: nil,
+			want:  []StackType{StackGo, StackPython, StackNode, StackJava},
+		},
+		{
+			name:    "unknown stack is an error",
+			order:   []StackType{"rust"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate stack is an error",
+			order:   []StackType{StackGo, StackGo},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := NewRegistry(BuiltinDetectors()...)
+			err := reg.SetOrder(tt.order...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := reg.Stacks(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Stacks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry_CloneIsIndependent(t *testing.T) {
+	reg := NewRegistry(BuiltinDetectors()...)
+	clone := reg.Clone()
+	clone.Unregister(StackGo)
+
+	if len(reg.Stacks()) != 4 {
+		t.Error("modifying a clone changed the original")
+	}
+}
+
+func TestScanWithOptions_Registry(t *testing.T) {
+	dir := createTempProject(t, []string{"api/go.mod", "api/WORKSPACE", "web/package.json"})
+
+	// Without Go, and with a custom detector first
+	reg := NewRegistry(BuiltinDetectors()...)
+	reg.Unregister(StackGo)
+	reg.Prepend(&markerDetector{stack: "bazel", marker: "WORKSPACE"})
+
+	results, err := ScanWithOptions(dir, ScanOptions{MaxDepth: 1, Registry: reg})
+	if err != nil {
+		t.Fatalf("ScanWithOptions() error = %v", err)
+	}
+
+	got := map[string]StackType{}
+	for _, r := range results {
+		got[r.Path[len(dir)+1:]] = r.Stack
+	}
+	want := map[string]StackType{"api": "bazel", "web": StackNode}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+}
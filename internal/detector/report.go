@@ -0,0 +1,78 @@
+package detector
+
+import (
+	"math"
+	"path/filepath"
+)
+
+// ReportSchemaVersion is bumped whenever a Report field is removed or
+// changes meaning. Adding fields is backwards compatible and does not
+// change the version.
+const ReportSchemaVersion = 1
+
+// Report is the stable, serializable form of a scan, used by
+// `agentic-repo detect --format json|yaml`
+type Report struct {
+	// SchemaVersion identifies the layout of this document
+	SchemaVersion int `json:"schema_version" yaml:"schema_version"`
+	// Root is the absolute path that was scanned
+	Root string `json:"root" yaml:"root"`
+	// Monorepo is true when more than one project was detected
+	Monorepo bool `json:"monorepo" yaml:"monorepo"`
+	// Projects lists detected projects in depth-first path order
+	Projects []ProjectReport `json:"projects" yaml:"projects"`
+}
+
+// ProjectReport describes one detected project
+type ProjectReport struct {
+	// Path is relative to Root, slash-separated, "." for the root itself
+	Path string `json:"path" yaml:"path"`
+	// Stack is the winning stack
+	Stack StackType `json:"stack" yaml:"stack"`
+	// Stacks lists every stack that passed the threshold, winner first
+	Stacks []StackType `json:"stacks" yaml:"stacks"`
+	// Confidence of the winning stack, rounded to two decimals
+	Confidence float64 `json:"confidence" yaml:"confidence"`
+	// Evidence lists the files that led to Stack
+	Evidence []string `json:"evidence" yaml:"evidence"`
+	// Tools holds tooling metadata such as package_manager or build_tool
+	Tools map[string]string `json:"tools,omitempty" yaml:"tools,omitempty"`
+}
+
+// NewReport builds a Report from scan results
+func NewReport(root string, results []Result) Report {
+	report := Report{
+		SchemaVersion: ReportSchemaVersion,
+		Root:          root,
+		Monorepo:      IsMonorepo(results),
+		Projects:      make([]ProjectReport, 0, len(results)),
+	}
+
+	for _, r := range results {
+		rel, err := filepath.Rel(root, r.Path)
+		if err != nil {
+			rel = r.Path
+		}
+
+		stacks := []StackType{r.Stack}
+		for _, alt := range r.Alternatives {
+			stacks = append(stacks, alt.Stack)
+		}
+
+		evidence := r.Evidence
+		if evidence == nil {
+			evidence = []string{}
+		}
+
+		report.Projects = append(report.Projects, ProjectReport{
+			Path:       filepath.ToSlash(rel),
+			Stack:      r.Stack,
+			Stacks:     stacks,
+			Confidence: math.Round(r.Confidence*100) / 100,
+			Evidence:   evidence,
+			Tools:      r.Tools,
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,100 @@
+package detector
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	root := "/repo"
+	results := []Result{
+		{
+			Path:         filepath.Join(root, "services", "api"),
+			Stack:        StackGo,
+			Confidence:   0.8425,
+			Evidence:     []string{"go.mod"},
+			Alternatives: []Detection{{Stack: StackNode, Confidence: 0.6}},
+			Tools:        map[string]string{"package_manager": "go modules"},
+		},
+		{
+			Path:       filepath.Join(root, "web"),
+			Stack:      StackNode,
+			Confidence: 0.9,
+		},
+	}
+
+	report := NewReport(root, results)
+
+	if report.SchemaVersion != ReportSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", report.SchemaVersion, ReportSchemaVersion)
+	}
+	if !report.Monorepo {
+		t.Error("expected Monorepo to be true")
+	}
+	if len(report.Projects) != 2 {
+		t.Fatalf("got %d projects, want 2", len(report.Projects))
+	}
+
+	api := report.Projects[0]
+	if api.Path != "services/api" {
+		t.Errorf("Path = %q, want %q", api.Path, "services/api")
+	}
+	if !reflect.DeepEqual(api.Stacks, []StackType{StackGo, StackNode}) {
+		t.Errorf("Stacks = %v, want [go node]", api.Stacks)
+	}
+	if api.Confidence != 0.84 {
+		t.Errorf("Confidence = %v, want 0.84", api.Confidence)
+	}
+
+	web := report.Projects[1]
+	if web.Evidence == nil {
+		t.Error("Evidence should be an empty list, not null")
+	}
+}
+
+func TestNewReport_EmptyAndRoot(t *testing.T) {
+	report := NewReport("/repo", nil)
+	if report.Projects == nil {
+		t.Error("Projects should be an empty list, not null")
+	}
+
+	report = NewReport("/repo", []Result{{Path: "/repo", Stack: StackGo}})
+	if report.Projects[0].Path != "." {
+		t.Errorf("root Path = %q, want %q", report.Projects[0].Path, ".")
+	}
+	if report.Monorepo {
+		t.Error("single project should not be a monorepo")
+	}
+}
+
+func TestReport_JSONSchema(t *testing.T) {
+	report := NewReport("/repo", []Result{{Path: "/repo/api", Stack: StackGo, Confidence: 1}})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	for _, key := range []string{"schema_version", "root", "monorepo", "projects"} {
+		if _, ok := doc[key]; !ok {
+			t.Errorf("missing top-level key %q", key)
+		}
+	}
+
+	project := doc["projects"].([]any)[0].(map[string]any)
+	for _, key := range []string{"path", "stack", "stacks", "confidence", "evidence"} {
+		if _, ok := project[key]; !ok {
+			t.Errorf("missing project key %q", key)
+		}
+	}
+	if _, ok := project["tools"]; ok {
+		t.Error("empty tools should be omitted")
+	}
+}
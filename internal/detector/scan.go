@@ -0,0 +1,299 @@
+package detector
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Shaked/agentic-repo/internal/ignore"
+)
+
+// DefaultMaxDepth is how many directory levels below the root are scanned
+// for subprojects (e.g. services/api/)
+const DefaultMaxDepth = 2
+
+// DefaultExcludes are gitignore-style patterns for the directories that
+// never hold subprojects: VCS and tool metadata, dependencies and build
+// output. Like ignore files they are dropped by NoIgnoreFiles, and an
+// Include glob reaching into one of them overrides it.
+var DefaultExcludes = []string{
+	".git/", ".agent/", ".claude/",
+	"node_modules/", "vendor/", "venv/", ".venv/", "__pycache__/",
+	"target/", "bin/", "dist/", "build/",
+}
+
+// ScanOptions controls how Scan walks the directory tree
+type ScanOptions struct {
+	// MaxDepth limits how deep below the root subprojects are detected;
+	// 0 only checks the root itself
+	MaxDepth int
+	// Include, when non-empty, restricts detection to directories whose
+	// root-relative path matches one of these gitignore-style globs
+	Include []string
+	// Exclude skips directories matching these gitignore-style globs
+	Exclude []string
+	// NoIgnoreFiles disables reading .gitignore and .agentignore files and
+	// the DefaultExcludes
+	NoIgnoreFiles bool
+	// MinConfidence filters out detections weaker than this
+	MinConfidence float64
+	// Registry holds the detectors to run; nil uses DefaultRegistry
+	Registry *Registry
+	// Workers bounds how many directories are read concurrently;
+	// 0 uses one worker per CPU
+	Workers int
+	// Progress, if set, is called after each directory is visited. Calls
+	// are serialized, so the callback does not need to be thread-safe.
+	Progress func(Progress)
+}
+
+// Progress describes how far a scan has got
+type Progress struct {
+	// Visited is the number of directories read so far
+	Visited int
+	// Found is the number of projects detected so far
+	Found int
+	// Path is the directory that was just visited
+	Path string
+}
+
+// DefaultScanOptions returns the options used by Scan
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{MaxDepth: DefaultMaxDepth, MinConfidence: DefaultMinConfidence}
+}
+
+// Scan recursively scans a directory for project types
+func Scan(root string) ([]Result, error) {
+	return ScanWithOptions(root, DefaultScanOptions())
+}
+
+// ScanWithOptions scans a directory for project types, honouring ignore
+// files, include/exclude globs and the depth limit in opts
+func ScanWithOptions(root string, opts ScanOptions) ([]Result, error) {
+	return ScanContext(context.Background(), root, opts)
+}
+
+// ScanContext is ScanWithOptions with cancellation. Directories are read
+// by a bounded pool of workers; the result order is the same as a
+// sequential depth-first walk in lexical order.
+func ScanContext(ctx context.Context, root string, opts ScanOptions) ([]Result, error) {
+	// Fail early if the root cannot be listed
+	rootDir, err := ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	s := &scanner{
+		opts:     opts,
+		registry: opts.Registry,
+		exclude:  ignore.New(opts.Exclude...),
+		defaults: ignore.New(),
+	}
+	if !opts.NoIgnoreFiles {
+		s.defaults = ignore.New(DefaultExcludes...)
+	}
+	if s.registry == nil {
+		s.registry = defaultRegistry
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.queue = []scanJob{{path: root, dir: rootDir}}
+	s.pending = 1
+
+	// Wake idle workers when the context is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(s.found, func(i, j int) bool {
+		return lessPath(s.found[i].rel, s.found[j].rel)
+	})
+	results := make([]Result, 0, len(s.found))
+	for _, f := range s.found {
+		results = append(results, f.result)
+	}
+
+	// Deduplicate - if root is detected, remove subdirectory matches of same type
+	return deduplicateResults(results, root), nil
+}
+
+// scanJob is a directory waiting to be visited
+type scanJob struct {
+	path  string
+	rel   string // slash-separated path relative to the root, "" for root
+	depth int
+	rules *ignore.Matcher
+	dir   *Dir // pre-read listing, if any
+}
+
+// found is a detection tagged with its relative path for ordering
+type found struct {
+	rel    string
+	result Result
+}
+
+// scanner holds the shared state of a single ScanContext call
+type scanner struct {
+	opts     ScanOptions
+	registry *Registry
+	exclude  *ignore.Matcher
+	defaults *ignore.Matcher
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []scanJob
+	pending int // queued plus in-flight jobs
+	visited int
+	found   []found
+}
+
+// work processes jobs until the queue drains or ctx is cancelled
+func (s *scanner) work(ctx context.Context) {
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && s.pending > 0 && ctx.Err() == nil {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 || ctx.Err() != nil {
+			s.mu.Unlock()
+			return
+		}
+		job := s.queue[len(s.queue)-1]
+		s.queue = s.queue[:len(s.queue)-1]
+		s.mu.Unlock()
+
+		result, children := s.visit(job)
+
+		s.mu.Lock()
+		s.visited++
+		if result != nil {
+			s.found = append(s.found, found{rel: job.rel, result: *result})
+		}
+		s.queue = append(s.queue, children...)
+		s.pending += len(children) - 1
+		if s.opts.Progress != nil {
+			s.opts.Progress(Progress{Visited: s.visited, Found: len(s.found), Path: job.path})
+		}
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+// visit reads one directory, detects its stack and returns the
+// subdirectories that should be walked next
+func (s *scanner) visit(job scanJob) (*Result, []scanJob) {
+	dir := job.dir
+	if dir == nil {
+		var err error
+		if dir, err = ReadDir(job.path); err != nil {
+			return nil, nil
+		}
+	}
+
+	var result *Result
+	if job.depth == 0 || s.included(job.rel) {
+		if r, ok := detectDir(dir, s.registry, s.opts.MinConfidence); ok {
+			result = &r
+		}
+	}
+
+	if job.depth >= s.opts.MaxDepth {
+		return result, nil
+	}
+
+	rules := job.rules
+	if !s.opts.NoIgnoreFiles {
+		var names []string
+		for _, name := range []string{ignore.GitIgnoreFile, ignore.AgentIgnoreFile} {
+			if dir.HasFile(name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			rules = rules.Child(job.rel, job.path, names...)
+		}
+	}
+
+	var children []scanJob
+	for _, name := range dir.Subdirs() {
+		subRel := path.Join(job.rel, name)
+		if s.defaults.Match(subRel, true) && !s.reaches(subRel) {
+			continue
+		}
+		if rules.Match(subRel, true) || s.exclude.Match(subRel, true) {
+			continue
+		}
+		children = append(children, scanJob{
+			path:  filepath.Join(job.path, name),
+			rel:   subRel,
+			depth: job.depth + 1,
+			rules: rules,
+		})
+	}
+	return result, children
+}
+
+// included reports whether a directory passes the include globs
+func (s *scanner) included(rel string) bool {
+	if len(s.opts.Include) == 0 {
+		return true
+	}
+	for _, pattern := range s.opts.Include {
+		if ignore.MatchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// reaches reports whether an include glob matches dir or names a path
+// below it, e.g. "vendor/sdk" reaches "vendor"
+func (s *scanner) reaches(dir string) bool {
+	for _, pattern := range s.opts.Include {
+		pattern = strings.TrimPrefix(pattern, "/")
+		if ignore.MatchGlob(pattern, dir) || strings.HasPrefix(pattern, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// lessPath orders slash-separated paths as a depth-first walk would:
+// component by component, parents before children
+func lessPath(a, b string) bool {
+	if a == "" || b == "" {
+		return a == "" && b != ""
+	}
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] != bs[i] {
+			return as[i] < bs[i]
+		}
+	}
+	return len(as) < len(bs)
+}
@@ -0,0 +1,322 @@
+package detector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanWithOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		opts          ScanOptions
+		expectedPaths []string
+	}{
+		{
+			name: "default depth finds two levels",
+			files: map[string]string{
+				"services/api/go.mod":        "",
+				"services/api/tools/go.mod":  "",
+				"services/web/package.json":  "",
+				"services/web/e2e/pom.xml":   "",
+				"services/web/e2e/extra.txt": "",
+			},
+			opts:          DefaultScanOptions(),
+			expectedPaths: []string{"services/api", "services/web"},
+		},
+		{
+			name: "deeper max depth finds nested projects",
+			files: map[string]string{
+				"services/api/go.mod":       "",
+				"services/web/package.json": "",
+				"services/web/e2e/pom.xml":  "",
+			},
+			opts:          ScanOptions{MaxDepth: 3},
+			expectedPaths: []string{"services/api", "services/web", "services/web/e2e"},
+		},
+		{
+			name: "depth zero only checks root",
+			files: map[string]string{
+				"backend/go.mod": "",
+			},
+			opts:          ScanOptions{MaxDepth: 0},
+			expectedPaths: []string{},
+		},
+		{
+			name: "gitignored directories are skipped",
+			files: map[string]string{
+				".gitignore":                 "sdk/generated/\n",
+				"backend/go.mod":             "",
+				"sdk/generated/package.json": "",
+			},
+			opts:          DefaultScanOptions(),
+			expectedPaths: []string{"backend"},
+		},
+		{
+			name: "agentignored directories are skipped",
+			files: map[string]string{
+				".agentignore":                 "third_party/\n",
+				"backend/go.mod":               "",
+				"third_party/lib/package.json": "",
+			},
+			opts:          ScanOptions{MaxDepth: 2},
+			expectedPaths: []string{"backend"},
+		},
+		{
+			name: "nested ignore files apply below their directory",
+			files: map[string]string{
+				"apps/.gitignore":          "fixtures\n",
+				"apps/fixtures/go.mod":     "",
+				"apps/web/package.json":    "",
+				"fixtures/python/setup.py": "",
+			},
+			opts:          ScanOptions{MaxDepth: 2},
+			expectedPaths: []string{"apps/web", "fixtures/python"},
+		},
+		{
+			name: "ignore files disabled",
+			files: map[string]string{
+				".gitignore":     "backend/\n",
+				"backend/go.mod": "",
+			},
+			opts:          ScanOptions{MaxDepth: 2, NoIgnoreFiles: true},
+			expectedPaths: []string{"backend"},
+		},
+		{
+			name: "exclude globs skip directories",
+			files: map[string]string{
+				"services/api/go.mod":       "",
+				"services/web/package.json": "",
+				"examples/demo/go.mod":      "",
+			},
+			opts:          ScanOptions{MaxDepth: 2, Exclude: []string{"examples"}},
+			expectedPaths: []string{"services/api", "services/web"},
+		},
+		{
+			name: "include globs restrict detection",
+			files: map[string]string{
+				"services/api/go.mod":       "",
+				"services/web/package.json": "",
+				"tools/lint/go.mod":         "",
+			},
+			opts:          ScanOptions{MaxDepth: 2, Include: []string{"services/*"}},
+			expectedPaths: []string{"services/api", "services/web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("failed to create directory for %s: %v", name, err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatalf("failed to create file %s: %v", name, err)
+				}
+			}
+
+			results, err := ScanWithOptions(dir, tt.opts)
+			if err != nil {
+				t.Fatalf("ScanWithOptions() error = %v", err)
+			}
+
+			var got []string
+			for _, r := range results {
+				rel, _ := filepath.Rel(dir, r.Path)
+				got = append(got, filepath.ToSlash(rel))
+			}
+
+			if len(got) != len(tt.expectedPaths) {
+				t.Fatalf("got paths %v, want %v", got, tt.expectedPaths)
+			}
+			for i := range got {
+				if got[i] != tt.expectedPaths[i] {
+					t.Errorf("path[%d] = %q, want %q", i, got[i], tt.expectedPaths[i])
+				}
+			}
+		})
+	}
+}
+
+// createTree builds a monorepo-like tree with width^depth leaf directories,
+// every leaf holding a Go project
+func createTree(tb testing.TB, width, depth int) string {
+	tb.Helper()
+	root := tb.TempDir()
+	var build func(dir string, level int)
+	build = func(dir string, level int) {
+		if level == depth {
+			os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x"), 0644)
+			os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644)
+			return
+		}
+		for i := 0; i < width; i++ {
+			sub := filepath.Join(dir, fmt.Sprintf("d%02d", i))
+			if err := os.MkdirAll(sub, 0755); err != nil {
+				tb.Fatalf("failed to create %s: %v", sub, err)
+			}
+			build(sub, level+1)
+		}
+	}
+	build(root, 0)
+	return root
+}
+
+func TestScanContext_WorkerCountDoesNotChangeResults(t *testing.T) {
+	root := createTree(t, 4, 3)
+	opts := ScanOptions{MaxDepth: 3, Workers: 1}
+
+	sequential, err := ScanWithOptions(root, opts)
+	if err != nil {
+		t.Fatalf("ScanWithOptions() error = %v", err)
+	}
+	if len(sequential) != 64 {
+		t.Fatalf("got %d results, want 64", len(sequential))
+	}
+
+	for _, workers := range []int{2, 8, 32} {
+		opts.Workers = workers
+		parallel, err := ScanWithOptions(root, opts)
+		if err != nil {
+			t.Fatalf("ScanWithOptions(workers=%d) error = %v", workers, err)
+		}
+		if len(parallel) != len(sequential) {
+			t.Fatalf("workers=%d: got %d results, want %d", workers, len(parallel), len(sequential))
+		}
+		for i := range parallel {
+			if !reflect.DeepEqual(parallel[i], sequential[i]) {
+				t.Errorf("workers=%d: result[%d] = %v, want %v", workers, i, parallel[i], sequential[i])
+			}
+		}
+	}
+}
+
+func TestScanContext_Progress(t *testing.T) {
+	root := createTree(t, 3, 2)
+
+	var calls []Progress
+	_, err := ScanWithOptions(root, ScanOptions{
+		MaxDepth: 2,
+		Workers:  4,
+		Progress: func(p Progress) { calls = append(calls, p) },
+	})
+	if err != nil {
+		t.Fatalf("ScanWithOptions() error = %v", err)
+	}
+
+	// root + 3 + 9 directories
+	if len(calls) != 13 {
+		t.Fatalf("got %d progress calls, want 13", len(calls))
+	}
+	last := calls[len(calls)-1]
+	if last.Visited != 13 {
+		t.Errorf("last Visited = %d, want 13", last.Visited)
+	}
+	if last.Found != 9 {
+		t.Errorf("last Found = %d, want 9", last.Found)
+	}
+}
+
+func TestScanContext_Cancelled(t *testing.T) {
+	root := createTree(t, 3, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ScanContext(ctx, root, DefaultScanOptions())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestScanContext_CancelledMidScan(t *testing.T) {
+	root := createTree(t, 4, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := ScanContext(ctx, root, ScanOptions{
+		MaxDepth: 3,
+		Workers:  2,
+		Progress: func(p Progress) {
+			if p.Visited == 5 {
+				cancel()
+			}
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ScanContext() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestLessPath(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"", "a", true},
+		{"a", "", false},
+		{"a", "a/b", true},
+		{"a/b", "a-c", true}, // depth-first, not byte order
+		{"a/b", "a/c", true},
+		{"b", "a/z", false},
+		{"a", "a", false},
+	}
+
+	for _, tt := range tests {
+		if got := lessPath(tt.a, tt.b); got != tt.expected {
+			t.Errorf("lessPath(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+// perDetectorStack is detection without a shared listing: every detector
+// reads the directory on its own
+func perDetectorStack(path string) StackType {
+	for _, d := range defaultRegistry.Detectors() {
+		if d.Detect(path) {
+			return d.Type()
+		}
+	}
+	return StackUnknown
+}
+
+func BenchmarkDetect_PerDetectorReads(b *testing.B) {
+	dir := createTempProjectTB(b, []string{"pom.xml", "README.md", "src/Main.java"})
+	for i := 0; i < b.N; i++ {
+		perDetectorStack(dir)
+	}
+}
+
+func BenchmarkDetect_SharedListing(b *testing.B) {
+	dir := createTempProjectTB(b, []string{"pom.xml", "README.md", "src/Main.java"})
+	for i := 0; i < b.N; i++ {
+		detectStack(dir)
+	}
+}
+
+func BenchmarkScan_Workers1(b *testing.B) {
+	benchmarkScan(b, 1)
+}
+
+func BenchmarkScan_Workers8(b *testing.B) {
+	benchmarkScan(b, 8)
+}
+
+func benchmarkScan(b *testing.B, workers int) {
+	root := createTree(b, 8, 3)
+	opts := ScanOptions{MaxDepth: 3, Workers: workers}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ScanWithOptions(root, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
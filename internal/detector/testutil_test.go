@@ -8,6 +8,11 @@ import (
 
 // createTempProject creates a temp directory with the specified files
 func createTempProject(t *testing.T, files []string) string {
+	return createTempProjectTB(t, files)
+}
+
+// createTempProjectTB is createTempProject for tests and benchmarks
+func createTempProjectTB(t testing.TB, files []string) string {
 	t.Helper()
 	dir := t.TempDir()
 	for _, f := range files {
@@ -34,3 +39,27 @@ func createTempDir(t *testing.T, dirs []string) string {
 	}
 	return root
 }
+
+// mkdir creates a subdirectory inside root
+func mkdir(t *testing.T, root, name string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(root, name), 0755); err != nil {
+		t.Fatalf("failed to create directory %s: %v", name, err)
+	}
+}
+
+// createTempFiles creates a temp directory with files and their content
+func createTempFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create file %s: %v", name, err)
+		}
+	}
+	return dir
+}
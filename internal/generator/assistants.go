@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"path/filepath"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// rulesData is the data for the plain Markdown rule files shared by
+// several assistants
+type rulesData struct {
+	templateData
+	// Tool is the assistant's display name
+	Tool string
+	// Projects lists monorepo subprojects
+	Projects []templateData
+}
+
+// newRulesData builds rule file data for a tool
+func newRulesData(tool string, in targetInput) rulesData {
+	if in.isMonorepo {
+		return rulesData{
+			templateData: templateData{Stack: detector.StackUnknown, IsMonorepo: true, HasLegacy: in.mono.HasLegacy, Rules: in.rules},
+			Tool:         tool,
+			Projects:     in.mono.Projects,
+		}
+	}
+	return rulesData{templateData: in.project, Tool: tool}
+}
+
+// windsurfSpecs lists the Windsurf rules file
+func windsurfSpecs(in targetInput) []fileSpec {
+	return []fileSpec{{".windsurfrules", "agent-rules.md.tmpl", newRulesData("Windsurf", in)}}
+}
+
+// clineSpecs lists the Cline rules directory
+func clineSpecs(in targetInput) []fileSpec {
+	return []fileSpec{
+		{".clinerules/project.md", "agent-rules.md.tmpl", newRulesData("Cline", in)},
+		{".clinerules/workflow.md", "agent-workflow.md.tmpl", newCommandData(in)},
+	}
+}
+
+// aiderSpecs lists Aider's conventions file and the config that loads it
+// together with the agent context as read-only files
+func aiderSpecs(in targetInput) []fileSpec {
+	return []fileSpec{
+		{"CONVENTIONS.md", "agent-rules.md.tmpl", newRulesData("Aider", in)},
+		{".aider.conf.yml", "aider.conf.yml.tmpl", newCommandData(in)},
+	}
+}
+
+// geminiSpecs lists GEMINI.md at the root and in every monorepo subproject
+func geminiSpecs(in targetInput) []fileSpec {
+	data := newCommandData(in)
+	specs := []fileSpec{{"GEMINI.md", "gemini.md.tmpl", data}}
+	for _, p := range data.Projects {
+		specs = append(specs, fileSpec{filepath.Join(filepath.FromSlash(p.RelPath), "GEMINI.md"), "gemini.md.tmpl", p.data()})
+	}
+	return specs
+}
+
+// continueSpecs lists the Continue rules: an always-applied project rule
+// and, in a monorepo, one rule per subproject scoped by glob
+func continueSpecs(in targetInput) []fileSpec {
+	if !in.isMonorepo {
+		return []fileSpec{{".continue/rules/project.md", "continue-rule.md.tmpl", cursorRuleData{templateData: in.project}}}
+	}
+
+	specs := []fileSpec{{".continue/rules/monorepo.md", "continue-rule.md.tmpl", cursorRuleData{
+		templateData: templateData{Stack: detector.StackUnknown, IsMonorepo: true, Rules: in.rules},
+		Projects:     in.mono.Projects,
+	}}}
+	for _, project := range in.mono.Projects {
+		prefix := project.RelPath + "/"
+		specs = append(specs, fileSpec{
+			filepath.Join(".continue", "rules", instructionsName(project.RelPath)+".md"),
+			"continue-rule.md.tmpl",
+			cursorRuleData{templateData: project, Prefix: prefix, Globs: prefix + "**"},
+		})
+	}
+	return specs
+}
@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// stackCommands are the everyday commands of a stack. Test and Lint are
+// shown in slash commands; Allow lists the permission rules for commands
+// that are safe to run without asking.
+type stackCommands struct {
+	Test  string
+	Lint  string
+	Allow []string
+}
+
+// commonAllow are read-only commands that are safe in any repository
+var commonAllow = []string{
+	"Bash(git status)",
+	"Bash(git diff:*)",
+	"Bash(git log:*)",
+	"Bash(pre-commit run:*)",
+	"Bash(make build)",
+	"Bash(make test)",
+	"Bash(make lint)",
+	"Bash(make fmt)",
+}
+
+// claudeDeny keeps secrets out of context and changes off the remote
+var claudeDeny = []string{
+	"Read(./.env)",
+	"Read(./.env.*)",
+	"Bash(git push:*)",
+}
+
+// commandsFor returns the commands for a stack, using the detected tools
+// to pick the package manager or build tool
+func commandsFor(stack detector.StackType, tools map[string]string) stackCommands {
+	switch stack {
+	case detector.StackGo:
+		return stackCommands{
+			Test: "go test ./...",
+			Lint: "golangci-lint run",
+			Allow: []string{
+				"Bash(go build:*)", "Bash(go test:*)", "Bash(go vet:*)",
+				"Bash(go mod tidy)", "Bash(gofmt:*)", "Bash(golangci-lint run:*)",
+			},
+		}
+	case detector.StackPython:
+		run := "uv run"
+		switch tools["package_manager"] {
+		case "poetry":
+			run = "poetry run"
+		case "pipenv":
+			run = "pipenv run"
+		case "pip":
+			run = "python -m"
+		}
+		return stackCommands{
+			Test: run + " pytest",
+			Lint: run + " ruff check .",
+			Allow: []string{
+				"Bash(" + run + " pytest:*)", "Bash(" + run + " ruff:*)",
+				"Bash(pytest:*)", "Bash(ruff check:*)", "Bash(ruff format:*)",
+			},
+		}
+	case detector.StackNode:
+		pm := tools["package_manager"]
+		if pm == "" {
+			pm = "pnpm"
+		}
+		run := pm + " run"
+		if pm == "npm" {
+			run = "npm run"
+		}
+		return stackCommands{
+			Test: pm + " test",
+			Lint: run + " lint",
+			Allow: []string{
+				"Bash(" + pm + " test:*)", "Bash(" + run + " lint:*)",
+				"Bash(" + run + " build)", "Bash(" + run + " typecheck)",
+				"Bash(" + run + " format)",
+			},
+		}
+	case detector.StackJava:
+		if tools["build_tool"] == "gradle" {
+			return stackCommands{
+				Test:  "./gradlew test",
+				Lint:  "./gradlew check",
+				Allow: []string{"Bash(./gradlew test:*)", "Bash(./gradlew check)", "Bash(./gradlew build)"},
+			}
+		}
+		return stackCommands{
+			Test: "./mvnw test",
+			Lint: "./mvnw checkstyle:check",
+			Allow: []string{
+				"Bash(./mvnw test:*)", "Bash(./mvnw verify)", "Bash(./mvnw package:*)",
+				"Bash(./mvnw checkstyle:check)", "Bash(./mvnw spotless:apply)",
+			},
+		}
+	default:
+		return stackCommands{Test: "make test", Lint: "make lint"}
+	}
+}
+
+// resolve replaces the stack's test and lint commands with those the
+// project's docs list
+func (c *stackCommands) resolve(cmds Commands) {
+	c.Test, c.Lint = cmds.Test, cmds.Lint
+}
+
+// Commands are the everyday commands of a detected project
+type Commands struct {
+	Test string
+	Lint string
+}
+
+// CommandsFor returns the test and lint commands of a detected project
+func CommandsFor(r detector.Result) Commands {
+	c := commandsFor(r.Stack, r.Tools)
+	return Commands{Test: c.Test, Lint: c.Lint}
+}
+
+// commandProject is a monorepo subproject with its commands
+type commandProject struct {
+	RelPath  string
+	Stack    detector.StackType
+	Commands stackCommands
+}
+
+// commandData is the data for templates that show or allow the stack's
+// commands, such as .claude/settings.json and the slash commands
+type commandData struct {
+	templateData
+	// Commands are the single project's commands
+	Commands stackCommands
+	// Allow and Deny are the permission rules for settings.json
+	Allow []string
+	Deny  []string
+	// Projects lists monorepo subprojects
+	Projects []commandProject
+}
+
+// newCommandData builds the command data for a single project or a
+// monorepo root; the allow-list is the union of every project's safe
+// commands. The test and lint commands are those the generated docs list.
+func newCommandData(in targetInput) commandData {
+	results, isMonorepo := in.results, in.isMonorepo
+	data := commandData{
+		templateData: templateData{Stack: detector.StackUnknown, IsMonorepo: isMonorepo, Rules: in.rules},
+		Deny:         claudeDeny,
+	}
+
+	allow := map[string]bool{}
+	for _, rule := range commonAllow {
+		allow[rule] = true
+	}
+
+	if !isMonorepo && len(results) > 0 {
+		data.Stack = results[0].Stack
+		data.Tools = results[0].Tools
+	}
+	data.Commands = commandsFor(data.Stack, data.Tools)
+	if !isMonorepo {
+		data.Commands.resolve(in.project.projectCommands())
+	}
+
+	for _, r := range results {
+		for _, rule := range commandsFor(r.Stack, r.Tools).Allow {
+			allow[rule] = true
+		}
+	}
+	for _, p := range in.mono.Projects {
+		cmds := commandsFor(p.Stack, p.Tools)
+		cmds.resolve(p.projectCommands())
+		data.Projects = append(data.Projects, commandProject{
+			RelPath:  p.RelPath,
+			Stack:    p.Stack,
+			Commands: cmds,
+		})
+	}
+
+	for rule := range allow {
+		data.Allow = append(data.Allow, rule)
+	}
+	sort.Strings(data.Allow)
+	return data
+}
+
+// claudeSpecs lists CLAUDE.md, the settings and the slash commands at the
+// root, plus a CLAUDE.md in every monorepo subproject
+func claudeSpecs(in targetInput) []fileSpec {
+	data := newCommandData(in)
+
+	specs := []fileSpec{
+		{"CLAUDE.md", "claude.md.tmpl", data},
+		{".claude/settings.json", "claude-settings.json.tmpl", data},
+		{".claude/commands/test.md", "claude-command-test.md.tmpl", data},
+		{".claude/commands/lint.md", "claude-command-lint.md.tmpl", data},
+		{".claude/commands/review.md", "claude-command-review.md.tmpl", data},
+	}
+	for _, p := range data.Projects {
+		specs = append(specs, fileSpec{filepath.Join(filepath.FromSlash(p.RelPath), "CLAUDE.md"), "claude.md.tmpl", p.data()})
+	}
+	return specs
+}
+
+// data returns the template data for a file inside the subproject
+func (p commandProject) data() commandData {
+	return commandData{
+		templateData: templateData{Stack: p.Stack, IsMonorepo: true, RelPath: p.RelPath},
+		Commands:     p.Commands,
+	}
+}
@@ -0,0 +1,178 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+func TestCommandsFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		stack     detector.StackType
+		tools     map[string]string
+		wantTest  string
+		wantAllow string
+	}{
+		{"go", detector.StackGo, nil, "go test ./...", "Bash(go test:*)"},
+		{"python defaults to uv", detector.StackPython, nil, "uv run pytest", "Bash(uv run pytest:*)"},
+		{"python with poetry", detector.StackPython, map[string]string{"package_manager": "poetry"}, "poetry run pytest", "Bash(poetry run pytest:*)"},
+		{"node defaults to pnpm", detector.StackNode, nil, "pnpm test", "Bash(pnpm run lint:*)"},
+		{"node with npm", detector.StackNode, map[string]string{"package_manager": "npm"}, "npm test", "Bash(npm run lint:*)"},
+		{"java maven", detector.StackJava, nil, "./mvnw test", "Bash(./mvnw test:*)"},
+		{"java gradle", detector.StackJava, map[string]string{"build_tool": "gradle"}, "./gradlew test", "Bash(./gradlew test:*)"},
+		{"custom stack", detector.StackType("bazel"), nil, "make test", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := commandsFor(tt.stack, tt.tools)
+			if got.Test != tt.wantTest {
+				t.Errorf("Test = %q, want %q", got.Test, tt.wantTest)
+			}
+			if tt.wantAllow != "" && !contains(got.Allow, tt.wantAllow) {
+				t.Errorf("Allow = %v, want to contain %q", got.Allow, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestNewCommandData_AllowIsSortedUnion(t *testing.T) {
+	root := t.TempDir()
+	results := []detector.Result{
+		{Path: filepath.Join(root, "api"), Stack: detector.StackGo},
+		{Path: filepath.Join(root, "worker"), Stack: detector.StackGo},
+		{Path: filepath.Join(root, "web"), Stack: detector.StackNode},
+	}
+
+	data := newCommandData(newTargetInput(root, results, true, nil))
+
+	if !sort.StringsAreSorted(data.Allow) {
+		t.Errorf("Allow is not sorted: %v", data.Allow)
+	}
+	seen := map[string]bool{}
+	for _, rule := range data.Allow {
+		if seen[rule] {
+			t.Errorf("duplicate rule %q", rule)
+		}
+		seen[rule] = true
+	}
+	for _, want := range []string{"Bash(go test:*)", "Bash(pnpm test:*)", "Bash(make test)"} {
+		if !seen[want] {
+			t.Errorf("Allow missing %q", want)
+		}
+	}
+	if len(data.Projects) != 3 || data.Projects[0].RelPath != "api" {
+		t.Errorf("Projects = %+v", data.Projects)
+	}
+}
+
+func TestRender_ClaudeCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		results    func(dir string) []detector.Result
+		isMonorepo bool
+		wantFiles  []string
+	}{
+		{
+			name: "single project",
+			results: func(dir string) []detector.Result {
+				return []detector.Result{{Path: dir, Stack: detector.StackPython}}
+			},
+			wantFiles: []string{"CLAUDE.md", ".claude/commands/test.md", ".claude/commands/lint.md", ".claude/commands/review.md"},
+		},
+		{
+			name: "monorepo",
+			results: func(dir string) []detector.Result {
+				return []detector.Result{
+					{Path: filepath.Join(dir, "api"), Stack: detector.StackGo},
+					{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+				}
+			},
+			isMonorepo: true,
+			wantFiles:  []string{"CLAUDE.md", "api/CLAUDE.md", "web/CLAUDE.md", ".claude/commands/test.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := renderMap(t, New(Options{}), dir, tt.results(dir), tt.isMonorepo)
+
+			var settings struct {
+				Permissions struct {
+					Allow []string `json:"allow"`
+					Deny  []string `json:"deny"`
+				} `json:"permissions"`
+			}
+			if err := json.Unmarshal([]byte(files[".claude/settings.json"]), &settings); err != nil {
+				t.Fatalf("settings.json is not valid JSON: %v\n%s", err, files[".claude/settings.json"])
+			}
+			if len(settings.Permissions.Allow) == 0 || len(settings.Permissions.Deny) == 0 {
+				t.Errorf("expected allow and deny rules, got %+v", settings.Permissions)
+			}
+
+			for _, f := range tt.wantFiles {
+				content, ok := files[f]
+				if !ok {
+					t.Errorf("expected %s", f)
+					continue
+				}
+				if strings.HasSuffix(f, "CLAUDE.md") && !strings.Contains(content, "\n@AGENTS.md\n") {
+					t.Errorf("%s should import AGENTS.md:\n%s", f, content)
+				}
+			}
+		})
+	}
+}
+
+// contains reports whether list holds s
+func TestRender_ClaudeCodeProjectCommands(t *testing.T) {
+	dir := t.TempDir()
+	workflow := "jobs:\n  api:\n    steps:\n      - run: cd services/api && go test -race ./...\n"
+	os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0755)
+	os.WriteFile(filepath.Join(dir, ".github", "workflows", "ci.yml"), []byte(workflow), 0644)
+	gen := New(Options{Integrations: []Integration{IntegrationClaude}})
+
+	// Single projects use the generated Makefile, like AGENTS.md
+	other := t.TempDir()
+	single := renderMap(t, gen, other, []detector.Result{{Path: other, Stack: detector.StackGo}}, false)
+	if got := single[".claude/commands/test.md"]; !strings.Contains(got, "make test") || strings.Contains(got, "go test ./...") {
+		t.Errorf("test.md should use the AGENTS.md test command:\n%s", got)
+	}
+
+	results := []detector.Result{
+		{Path: filepath.Join(dir, "services", "api"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+	}
+	files := renderMap(t, gen, dir, results, true)
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"services/api/CLAUDE.md", "`go test -race ./...`"},
+		{".claude/commands/test.md", "cd services/api && go test -race ./..."},
+		// Projects CI does not reach keep the defaults
+		{"web/CLAUDE.md", "`pnpm test`"},
+		{".claude/commands/test.md", "cd web && pnpm test"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(files[tt.file], tt.want) {
+			t.Errorf("%s missing %q:\n%s", tt.file, tt.want, files[tt.file])
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
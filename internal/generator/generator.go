@@ -1,21 +1,41 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
+	"github.com/Shaked/agentic-repo/internal/agentignore"
+	"github.com/Shaked/agentic-repo/internal/arch"
+	"github.com/Shaked/agentic-repo/internal/ci"
+	"github.com/Shaked/agentic-repo/internal/commands"
 	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/manifest"
 	"github.com/Shaked/agentic-repo/internal/templates"
 	"github.com/fatih/color"
 )
 
+// RulesFile holds the repository's own rules, relative to the root. Its
+// content is embedded in every root-level AI tool file, making it the
+// single place to edit rules shared by all assistants.
+const RulesFile = ".agent/rules.md"
+
 // Options configures the generator behavior
 type Options struct {
 	Force   bool
 	DryRun  bool
 	Verbose bool
+	// Quiet suppresses all progress output, for library use
+	Quiet bool
+	// TemplateDirs maps stacks to directories on disk whose templates take
+	// precedence over the embedded ones, e.g. for configured custom stacks
+	TemplateDirs map[detector.StackType]string
+	// Integrations selects the AI tools whose files are generated; nil
+	// means DefaultIntegrations
+	Integrations []Integration
 }
 
 // Generator creates agent context files
@@ -28,203 +48,356 @@ func New(opts Options) *Generator {
 	return &Generator{opts: opts}
 }
 
+// File is a rendered output file
+type File struct {
+	// Path is the absolute path the file is written to
+	Path    string
+	Content []byte
+}
+
+// fileSpec pairs an output path with the template that renders it
+type fileSpec struct {
+	path     string
+	template string
+	data     any
+}
+
 // Generate creates all necessary files for the detected stacks
 func (g *Generator) Generate(root string, results []detector.Result, isMonorepo bool) error {
+	_, err := g.GenerateFiles(root, results, isMonorepo)
+	return err
+}
+
+// GenerateFiles is Generate that also returns the paths it wrote
+func (g *Generator) GenerateFiles(root string, results []detector.Result, isMonorepo bool) ([]string, error) {
+	// Migrate existing AGENTS.md files to their legacy location first so
+	// the freshly rendered routers are not skipped as existing files
+	for _, dir := range projectDirs(root, results, isMonorepo) {
+		if _, err := g.migrateLegacyAgents(root, dir); err != nil {
+			return nil, fmt.Errorf("failed to migrate legacy AGENTS.md in %s: %w", dir, err)
+		}
+	}
+
+	files, err := g.Render(root, results, isMonorepo)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.WriteFiles(files)
+}
+
+// Render renders every file for the detected stacks in memory without
+// touching the disk
+func (g *Generator) Render(root string, results []detector.Result, isMonorepo bool) ([]File, error) {
+	var available []commands.Command
+	if !isMonorepo {
+		available = g.plannedCommands(root, singleStack(results))
+	}
+	in := newTargetInput(root, results, isMonorepo, available)
+	in.ignore = g.scanIgnore(root)
+
+	specs := routerSpecs(in, false)
 	if isMonorepo {
-		return g.generateMonorepo(root, results)
+		specs = append(specs, monorepoSpecs(in)...)
+	} else {
+		specs = append(specs, singleProjectSpecs(in)...)
 	}
+	specs = append(specs, g.integrationSpecs(in)...)
+	return g.renderSpecs(specs)
+}
 
-	// Single project - use first result or unknown
-	stack := detector.StackUnknown
-	if len(results) > 0 {
-		stack = results[0].Stack
+// RenderDerived renders only the files derived from the .agent/ context
+// and the config: the AGENTS.md routers and the enabled AI tool files.
+// These are the files that sync keeps up to date.
+func (g *Generator) RenderDerived(root string, results []detector.Result, isMonorepo bool) ([]File, error) {
+	var available []commands.Command
+	if !isMonorepo {
+		available = commands.Extract(root)
+	}
+	in := newTargetInput(root, results, isMonorepo, available)
+
+	specs := routerSpecs(in, true)
+	specs = append(specs, g.integrationSpecs(in)...)
+	return g.renderSpecs(specs)
+}
+
+// renderSpecs renders every spec in order
+func (g *Generator) renderSpecs(specs []fileSpec) ([]File, error) {
+	files := make([]File, 0, len(specs))
+	for _, spec := range specs {
+		content, err := g.renderTemplate(spec.template, spec.data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", spec.path, err)
+		}
+		files = append(files, File{Path: spec.path, Content: content})
 	}
 
-	return g.generateSingleProject(root, stack)
+	return files, nil
+}
+
+// WriteFiles writes rendered files to disk, honouring the Force and DryRun
+// options. It returns the paths that were actually written.
+func (g *Generator) WriteFiles(files []File) ([]string, error) {
+	var written []string
+	for _, f := range files {
+		ok, err := g.writeFile(f)
+		if err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+		if ok {
+			written = append(written, f.Path)
+		}
+	}
+	return written, nil
 }
 
-// generateSingleProject generates files for a single-stack project
-func (g *Generator) generateSingleProject(root string, stack detector.StackType) error {
-	// Migrate existing AGENTS.md to legacy location
-	hasLegacy, err := g.migrateLegacyAgents(root)
+// projectDirs returns every directory that gets its own AGENTS.md
+func projectDirs(root string, results []detector.Result, isMonorepo bool) []string {
+	dirs := []string{root}
+	if !isMonorepo {
+		return dirs
+	}
+	for _, result := range results {
+		if result.Path != root {
+			dirs = append(dirs, result.Path)
+		}
+	}
+	return dirs
+}
+
+// singleStack returns the stack of a single project, or unknown
+func singleStack(results []detector.Result) detector.StackType {
+	if len(results) > 0 {
+		return results[0].Stack
+	}
+	return detector.StackUnknown
+}
+
+// plannedCommands returns the commands of a single project's root. When
+// the project has no Makefile, the targets of the one that is generated
+// are included so the rendered files match the repository after init.
+func (g *Generator) plannedCommands(root string, stack detector.StackType) []commands.Command {
+	cmds := commands.Extract(root)
+	if commands.HasMakefile(root) {
+		return cmds
+	}
+	content, err := g.renderTemplate(fmt.Sprintf("%s/Makefile.tmpl", stack), templateData{Stack: stack})
 	if err != nil {
-		return fmt.Errorf("failed to migrate legacy AGENTS.md: %w", err)
+		return cmds
+	}
+	return append(commands.ParseMakefile(content), cmds...)
+}
+
+// newTargetInput gathers what a render reads from the repository, once:
+// parsing CI, reading task files and deriving the dependency graph each
+// walk the tree, which is slow in a large monorepo. available are the
+// commands of a single project's root.
+func newTargetInput(root string, results []detector.Result, isMonorepo bool, available []commands.Command) targetInput {
+	in := targetInput{root: root, results: results, isMonorepo: isMonorepo, rules: readRules(root)}
+	pipeline := ci.Parse(root)
+	if isMonorepo {
+		in.mono = newMonorepoData(root, results, pipeline)
+		in.mono.Rules = in.rules
+		return in
+	}
+	in.project = templateData{
+		Stack:     singleStack(results),
+		HasLegacy: hasLegacyAgents(root),
+		Rules:     in.rules,
+		Available: available,
+		CI:        pipeline,
+	}
+	if len(results) > 0 {
+		in.project.Tools = results[0].Tools
+	}
+	return in
+}
+
+// routerSpecs lists the AGENTS.md routers: one at the root and, in a
+// monorepo, one per subproject. When derived is set, existing AGENTS.md
+// files are earlier renderings rather than legacy files to migrate.
+func routerSpecs(in targetInput, derived bool) []fileSpec {
+	hasLegacy := hasLegacyAgents
+	if derived {
+		hasLegacy = hasMigratedAgents
+	}
+
+	if !in.isMonorepo {
+		data := in.project
+		data.HasLegacy = hasLegacy(in.root)
+		return under(in.root, []fileSpec{{"AGENTS.md", "agents.md.tmpl", data}})
 	}
 
-	// Template data with legacy flag
-	data := templateData{Stack: stack, IsMonorepo: false, HasLegacy: hasLegacy}
+	monoData := in.mono
+	monoData.HasLegacy = hasLegacy(in.root)
+	specs := under(in.root, []fileSpec{{"AGENTS.md", "agents-monorepo.md.tmpl", monoData}})
+	for _, subData := range in.mono.Projects {
+		dir := filepath.Join(in.root, filepath.FromSlash(subData.RelPath))
+		subData.HasLegacy = hasLegacy(dir)
+		specs = append(specs, under(dir, []fileSpec{{"AGENTS.md", "agents.md.tmpl", subData}})...)
+	}
+	return specs
+}
+
+// singleProjectSpecs lists the files for a single-stack project
+func singleProjectSpecs(in targetInput) []fileSpec {
+	root, stack := in.root, in.project.Stack
+	data := in.project
+	data.Architecture = LoadArchitecture(root, stack)
 
 	// Generate root files
-	files := []struct {
-		path     string
-		template string
-		data     any
-	}{
-		{"AGENTS.md", "agents.md.tmpl", data},
+	files := []fileSpec{
 		{"CODE_REVIEW_RULES.md", fmt.Sprintf("%s/code-review-rules.md.tmpl", stack), data},
 		{"repo-best-practices.md", "repo-best-practices.md.tmpl", data},
 		{"USAGE.md", "usage.md.tmpl", data},
 		{"Makefile", fmt.Sprintf("%s/Makefile.tmpl", stack), data},
 		{".gitignore", "gitignore.tmpl", data},
-		{".agentignore", "agentignore.tmpl", data},
+		{".agentignore", "agentignore.tmpl", templateData{Stack: stack, Ignore: in.ignore}},
 		{".pre-commit-config.yaml", fmt.Sprintf("%s/pre-commit-config.yaml.tmpl", stack), data},
 		{".agent/stack.md", fmt.Sprintf("%s/stack.md.tmpl", stack), data},
 		{".agent/testing.md", fmt.Sprintf("%s/testing.md.tmpl", stack), data},
 		{".agent/commands.md", fmt.Sprintf("%s/commands.md.tmpl", stack), data},
 		{".agent/architecture.md", "architecture.md.tmpl", data},
-		{".cursorrules", "cursorrules.tmpl", data},
-		{".claude/settings.json", "claude-settings.json.tmpl", data},
 		{"INSTALL.md", "install.md.tmpl", data},
 	}
 
-	for _, f := range files {
-		fullPath := filepath.Join(root, f.path)
-		if err := g.writeTemplate(fullPath, f.template, f.data); err != nil {
-			return fmt.Errorf("failed to write %s: %w", f.path, err)
-		}
-	}
-
-	return nil
+	return under(root, files)
 }
 
-// generateMonorepo generates files for a monorepo structure
-func (g *Generator) generateMonorepo(root string, results []detector.Result) error {
-	// Migrate existing AGENTS.md to legacy location
-	hasLegacy, err := g.migrateLegacyAgents(root)
-	if err != nil {
-		return fmt.Errorf("failed to migrate legacy AGENTS.md: %w", err)
-	}
-
-	// Monorepo data with legacy flag
-	monoData := monorepoData{Results: results, Root: root, HasLegacy: hasLegacy}
+// monorepoSpecs lists the files for a monorepo structure
+func monorepoSpecs(in targetInput) []fileSpec {
+	root, monoData := in.root, in.mono
 
 	// Generate root-level files
-	rootFiles := []struct {
-		path     string
-		template string
-		data     any
-	}{
-		{"AGENTS.md", "agents-monorepo.md.tmpl", monoData},
+	specs := under(root, []fileSpec{
 		{"CODE_REVIEW_RULES.md", "code-review-rules.md.tmpl", monoData},
 		{"repo-best-practices.md", "repo-best-practices.md.tmpl", monoData},
 		{"USAGE.md", "usage-monorepo.md.tmpl", monoData},
 		{"Makefile", "Makefile-monorepo.tmpl", monoData},
 		{".gitignore", "gitignore.tmpl", templateData{Stack: detector.StackUnknown}},
-		{".agentignore", "agentignore.tmpl", templateData{Stack: detector.StackUnknown}},
+		{".agentignore", "agentignore.tmpl", templateData{Stack: detector.StackUnknown, Ignore: in.ignore}},
 		{".agent/overview.md", "overview.md.tmpl", monoData},
 		{".agent/architecture.md", "architecture.md.tmpl", monoData},
-		{".cursorrules", "cursorrules-monorepo.tmpl", monoData},
-		{".claude/settings.json", "claude-settings-monorepo.json.tmpl", monoData},
 		{"INSTALL.md", "install.md.tmpl", templateData{Stack: detector.StackUnknown}},
-	}
-
-	for _, f := range rootFiles {
-		fullPath := filepath.Join(root, f.path)
-		if err := g.writeTemplate(fullPath, f.template, f.data); err != nil {
-			return fmt.Errorf("failed to write %s: %w", f.path, err)
-		}
-	}
+	})
 
 	// Generate per-project files
-	for _, result := range results {
-		if result.Path == root {
-			continue // Skip root, already handled
-		}
-
-		// Check for legacy in subproject
-		subHasLegacy, err := g.migrateLegacyAgents(result.Path)
-		if err != nil {
-			return fmt.Errorf("failed to migrate legacy AGENTS.md in %s: %w", result.Path, err)
-		}
-
-		relPath, _ := filepath.Rel(root, result.Path)
-		subData := templateData{Stack: result.Stack, IsMonorepo: true, RelPath: relPath, HasLegacy: subHasLegacy}
-
-		projectFiles := []struct {
-			path     string
-			template string
-			data     any
-		}{
-			{"AGENTS.md", "agents.md.tmpl", subData},
-			{"CODE_REVIEW_RULES.md", fmt.Sprintf("%s/code-review-rules.md.tmpl", result.Stack), subData},
+	for _, subData := range monoData.Projects {
+		stack := subData.Stack
+		specs = append(specs, under(filepath.Join(root, filepath.FromSlash(subData.RelPath)), []fileSpec{
+			{"CODE_REVIEW_RULES.md", fmt.Sprintf("%s/code-review-rules.md.tmpl", stack), subData},
 			{"repo-best-practices.md", "repo-best-practices.md.tmpl", subData},
 			{"USAGE.md", "usage.md.tmpl", subData},
-			{".pre-commit-config.yaml", fmt.Sprintf("%s/pre-commit-config.yaml.tmpl", result.Stack), subData},
-			{".agent/stack.md", fmt.Sprintf("%s/stack.md.tmpl", result.Stack), subData},
-			{".agent/testing.md", fmt.Sprintf("%s/testing.md.tmpl", result.Stack), subData},
-			{".agent/commands.md", fmt.Sprintf("%s/commands.md.tmpl", result.Stack), subData},
-		}
-
-		for _, f := range projectFiles {
-			fullPath := filepath.Join(result.Path, f.path)
-			if err := g.writeTemplate(fullPath, f.template, f.data); err != nil {
-				return fmt.Errorf("failed to write %s: %w", fullPath, err)
-			}
-		}
+			{".pre-commit-config.yaml", fmt.Sprintf("%s/pre-commit-config.yaml.tmpl", stack), subData},
+			{".agent/stack.md", fmt.Sprintf("%s/stack.md.tmpl", stack), subData},
+			{".agent/testing.md", fmt.Sprintf("%s/testing.md.tmpl", stack), subData},
+			{".agent/commands.md", fmt.Sprintf("%s/commands.md.tmpl", stack), subData},
+		})...)
 	}
 
-	return nil
+	return specs
 }
 
-// writeTemplate renders a template and writes it to disk
-func (g *Generator) writeTemplate(path, tmplName string, data any) error {
-	// Check if file exists
-	if !g.opts.Force {
-		if _, err := os.Stat(path); err == nil {
-			if g.opts.Verbose {
-				color.Yellow("   ⏭  Skipping %s (exists)", path)
-			}
-			return nil
-		}
+// under resolves relative spec paths against dir
+func under(dir string, specs []fileSpec) []fileSpec {
+	for i := range specs {
+		specs[i].path = filepath.Join(dir, specs[i].path)
 	}
+	return specs
+}
 
+// renderTemplate executes a named template, falling back to the generic
+// template when no stack-specific one exists
+func (g *Generator) renderTemplate(tmplName string, data any) ([]byte, error) {
 	// Get template content
-	content, err := templates.Get(tmplName)
+	content, err := g.stackTemplate(tmplName)
+	if err != nil {
+		content, err = templates.Get(tmplName)
+	}
 	if err != nil {
-		// Fall back to generic template if stack-specific doesn't exist
+		// Fall back to the generic template, then to the unknown stack's
+		// template for stacks without templates of their own
 		genericName := filepath.Base(tmplName)
 		content, err = templates.Get(genericName)
 		if err != nil {
-			return fmt.Errorf("template not found: %s", tmplName)
+			content, err = templates.Get(fmt.Sprintf("%s/%s", detector.StackUnknown, genericName))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("template not found: %s", tmplName)
 		}
 	}
 
 	// Parse and execute template
 	tmpl, err := template.New(tmplName).Parse(content)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// stackTemplate reads a "<stack>/<name>" template from the stack's
+// configured template directory
+func (g *Generator) stackTemplate(tmplName string) (string, error) {
+	stack, name, ok := strings.Cut(tmplName, "/")
+	if !ok {
+		return "", fmt.Errorf("not a stack template: %s", tmplName)
+	}
+	dir, ok := g.opts.TemplateDirs[detector.StackType(stack)]
+	if !ok {
+		return "", fmt.Errorf("no template directory for stack %s", stack)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// writeFile writes a rendered file unless it exists (without Force) or
+// this is a dry run. It reports whether the file was written.
+func (g *Generator) writeFile(f File) (bool, error) {
+	// Check if file exists
+	if !g.opts.Force {
+		if _, err := os.Stat(f.Path); err == nil {
+			if g.opts.Verbose {
+				g.printf(color.FgYellow, "   ⏭  Skipping %s (exists)", f.Path)
+			}
+			return false, nil
+		}
 	}
 
-	// Create output
-	var output string
 	if g.opts.DryRun {
 		// In dry-run mode, just print what would be created
-		color.Cyan("   📄 Would create: %s", path)
-		return nil
+		g.printf(color.FgCyan, "   📄 Would create: %s", f.Path)
+		return false, nil
 	}
 
 	// Ensure directory exists
-	dir := filepath.Dir(path)
+	dir := filepath.Dir(f.Path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return false, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Create file
-	f, err := os.Create(path)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	if err := os.WriteFile(f.Path, f.Content, 0644); err != nil {
+		return false, fmt.Errorf("failed to create file: %w", err)
 	}
-	defer f.Close()
 
-	// Execute template
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("failed to execute template: %w", err)
-	}
-
-	color.Green("   ✓ Created: %s", path)
-	_ = output // silence unused warning
+	g.printf(color.FgGreen, "   ✓ Created: %s", f.Path)
+	return true, nil
+}
 
-	return nil
+// printf prints a colored progress line unless the generator is quiet
+func (g *Generator) printf(c color.Attribute, format string, args ...any) {
+	if g.opts.Quiet {
+		return
+	}
+	color.New(c).Printf(format+"\n", args...)
 }
 
 // templateData holds data for single-project templates
@@ -233,6 +406,73 @@ type templateData struct {
 	IsMonorepo bool
 	RelPath    string
 	HasLegacy  bool
+	// Tools is the detected tooling, e.g. "package_manager": "pnpm"
+	Tools map[string]string
+	// Rules is the content of RulesFile, embedded in root-level AI tool
+	// files
+	Rules string
+	// Available are the commands found in the project's task files
+	Available []commands.Command
+	// CI is the part of the repository's CI configuration that runs in
+	// the project
+	CI ci.Pipeline
+	// Architecture is the project's import graph, nil when it cannot be
+	// derived for the stack
+	Architecture *arch.Graph
+	// Ignore lists what .agentignore should hide beyond its defaults
+	Ignore []agentignore.Section
+}
+
+// CommandGroups returns the available commands grouped by task file
+func (d templateData) CommandGroups() []commands.Group {
+	return commands.Groups(d.Available)
+}
+
+// Shortcuts returns the available install, build, test and lint commands
+func (d templateData) Shortcuts() []commands.Shortcut {
+	return commands.Shortcuts(d.Available)
+}
+
+// TestCommands returns how the project's tests are run: as in CI, else
+// the test command of its task files, else none
+func (d templateData) TestCommands() []string {
+	if runs := d.CI.Runs(ci.Test); len(runs) > 0 {
+		return runs
+	}
+	for _, s := range d.Shortcuts() {
+		if s.Label == "Test" {
+			return []string{s.Run}
+		}
+	}
+	return nil
+}
+
+// ProjectCommands returns the test and lint commands of detected
+// projects, in the order of results, as their generated docs list them:
+// from CI and their task files, else the stack's defaults. Several test
+// commands are joined to run one after the other.
+func ProjectCommands(root string, results []detector.Result) []Commands {
+	pipeline := ci.Parse(root)
+	cmds := make([]Commands, len(results))
+	for i, r := range results {
+		cmds[i] = newProjectData(root, r, pipeline).projectCommands()
+	}
+	return cmds
+}
+
+// projectCommands returns the test and lint commands the project's docs
+// list, falling back to the stack's defaults
+func (d templateData) projectCommands() Commands {
+	cmds := CommandsFor(detector.Result{Stack: d.Stack, Tools: d.Tools})
+	if runs := d.TestCommands(); len(runs) > 0 {
+		cmds.Test = strings.Join(runs, " && ")
+	}
+	for _, sc := range d.Shortcuts() {
+		if sc.Label == "Lint" {
+			cmds.Lint = sc.Run
+		}
+	}
+	return cmds
 }
 
 // monorepoData holds data for monorepo templates
@@ -240,44 +480,157 @@ type monorepoData struct {
 	Results   []detector.Result
 	Root      string
 	HasLegacy bool
+	// Projects are the subprojects with root-relative paths
+	Projects []templateData
+	Rules    string
+	// Architecture is unused for monorepos; architecture.md.tmpl falls
+	// back to its placeholders
+	Architecture *arch.Graph
+	// Dependencies is the graph of dependencies between the Projects
+	Dependencies *arch.Graph
+}
+
+// newProjectData builds the template data for a monorepo subproject from
+// the repository's parsed CI configuration
+func newProjectData(root string, result detector.Result, pipeline ci.Pipeline) templateData {
+	relPath, _ := filepath.Rel(root, result.Path)
+	relPath = filepath.ToSlash(relPath)
+	return templateData{
+		Stack:      result.Stack,
+		IsMonorepo: true,
+		RelPath:    relPath,
+		HasLegacy:  hasLegacyAgents(result.Path),
+		Tools:      result.Tools,
+		Available:  commands.Extract(result.Path),
+		CI:         pipeline.For(relPath),
+	}
+}
+
+// newMonorepoData builds the template data for a monorepo root and its
+// subprojects
+func newMonorepoData(root string, results []detector.Result, pipeline ci.Pipeline) monorepoData {
+	data := monorepoData{Results: results, Root: root, HasLegacy: hasLegacyAgents(root)}
+	var dirs []string
+	for _, r := range results {
+		if r.Path != root {
+			project := newProjectData(root, r, pipeline)
+			data.Projects = append(data.Projects, project)
+			dirs = append(dirs, project.RelPath)
+		}
+	}
+	data.Dependencies = arch.LoadProjects(root, dirs)
+	return data
+}
+
+// scanIgnore finds what .agentignore should hide in the repository at
+// root. A tree that cannot be read gets the defaults only, with a warning
+// in verbose output.
+func (g *Generator) scanIgnore(root string) []agentignore.Section {
+	sections, err := agentignore.Scan(root)
+	if err != nil && g.opts.Verbose {
+		g.printf(color.FgYellow, "   ⚠️  .agentignore lists the defaults only: %v", err)
+	}
+	return sections
+}
+
+// LoadArchitecture derives the import graph of a project, or returns nil
+// when its stack is not supported or its sources cannot be read
+func LoadArchitecture(dir string, stack detector.StackType) *arch.Graph {
+	var (
+		graph *arch.Graph
+		err   error
+	)
+	switch stack {
+	case detector.StackGo:
+		graph, err = arch.LoadGo(dir)
+	case detector.StackPython:
+		graph, err = arch.LoadPython(dir)
+	case detector.StackNode:
+		graph, err = arch.LoadTypeScript(dir)
+	default:
+		return nil
+	}
+	if err != nil || len(graph.Components) == 0 {
+		return nil
+	}
+	return graph
+}
+
+// readRules returns the trimmed content of the repository's RulesFile,
+// or "" when it does not exist
+func readRules(root string) string {
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(RulesFile)))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// hasLegacyAgents reports whether dir has an AGENTS.md that is, or will
+// be, migrated to .agent/AGENTS_LEGACY.md
+func hasLegacyAgents(dir string) bool {
+	for _, name := range []string{"AGENTS.md", filepath.Join(".agent", "AGENTS_LEGACY.md")} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMigratedAgents reports whether dir has a .agent/AGENTS_LEGACY.md
+func hasMigratedAgents(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".agent", "AGENTS_LEGACY.md"))
+	return err == nil
 }
 
-// migrateLegacyAgents moves existing AGENTS.md to .agent/AGENTS_LEGACY.md
+// migrateLegacyAgents moves an existing hand-written AGENTS.md in dir to
+// .agent/AGENTS_LEGACY.md, appending it when import already created that
+// file. An AGENTS.md that agentic-repo generated, according to the
+// manifest of the repository at root, is left to be regenerated.
 // Returns true if migration occurred, false otherwise
-func (g *Generator) migrateLegacyAgents(root string) (bool, error) {
-	agentsPath := filepath.Join(root, "AGENTS.md")
-	legacyPath := filepath.Join(root, ".agent", "AGENTS_LEGACY.md")
+func (g *Generator) migrateLegacyAgents(root, dir string) (bool, error) {
+	agentsPath := filepath.Join(dir, "AGENTS.md")
+	legacyPath := filepath.Join(dir, ".agent", "AGENTS_LEGACY.md")
 
-	// Check if AGENTS.md exists
-	if _, err := os.Stat(agentsPath); os.IsNotExist(err) {
+	// Read existing AGENTS.md
+	content, err := os.ReadFile(agentsPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read existing AGENTS.md: %w", err)
+	}
+	if generatedFile(root, agentsPath, content) {
 		return false, nil
 	}
 
-	// Check if legacy already exists (don't overwrite)
-	if _, err := os.Stat(legacyPath); err == nil {
+	// Append to a legacy file written by import, unless it already holds
+	// this content
+	legacy, err := os.ReadFile(legacyPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read legacy file: %w", err)
+	}
+	if strings.Contains(string(legacy), strings.TrimSpace(string(content))) {
 		if g.opts.Verbose {
-			color.Yellow("   ⏭  Legacy file already exists: %s", legacyPath)
+			g.printf(color.FgYellow, "   ⏭  Legacy file already exists: %s", legacyPath)
 		}
-		return true, nil // Legacy exists, consider it migrated
+		return true, nil
+	}
+	if len(legacy) > 0 {
+		content = append(append(bytes.TrimRight(legacy, "\n"), "\n\n"...), content...)
 	}
 
 	if g.opts.DryRun {
-		color.Cyan("   📦 Would migrate: AGENTS.md → .agent/AGENTS_LEGACY.md")
+		g.printf(color.FgCyan, "   📦 Would migrate: AGENTS.md → .agent/AGENTS_LEGACY.md")
 		return true, nil
 	}
 
 	// Ensure .agent directory exists
-	agentDir := filepath.Join(root, ".agent")
+	agentDir := filepath.Join(dir, ".agent")
 	if err := os.MkdirAll(agentDir, 0755); err != nil {
 		return false, fmt.Errorf("failed to create .agent directory: %w", err)
 	}
 
-	// Read existing AGENTS.md
-	content, err := os.ReadFile(agentsPath)
-	if err != nil {
-		return false, fmt.Errorf("failed to read existing AGENTS.md: %w", err)
-	}
-
 	// Write to legacy location
 	if err := os.WriteFile(legacyPath, content, 0644); err != nil {
 		return false, fmt.Errorf("failed to write legacy file: %w", err)
@@ -288,6 +641,20 @@ func (g *Generator) migrateLegacyAgents(root string) (bool, error) {
 		return false, fmt.Errorf("failed to remove original AGENTS.md: %w", err)
 	}
 
-	color.Magenta("   📦 Migrated: AGENTS.md → .agent/AGENTS_LEGACY.md")
+	g.printf(color.FgMagenta, "   📦 Migrated: AGENTS.md → .agent/AGENTS_LEGACY.md")
 	return true, nil
 }
+
+// generatedFile reports whether the manifest of the repository at root
+// records path with this content
+func generatedFile(root, path string, content []byte) bool {
+	m, err := manifest.Load(root)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return m.Files[filepath.ToSlash(rel)] == manifest.Hash(content)
+}
@@ -1,11 +1,16 @@
 package generator
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/manifest"
+	"github.com/Shaked/agentic-repo/internal/tokens"
 )
 
 func TestNew(t *testing.T) {
@@ -41,7 +46,7 @@ func TestNew(t *testing.T) {
 			if gen == nil {
 				t.Error("New() returned nil")
 			}
-			if gen.opts != tt.opts {
+			if !reflect.DeepEqual(gen.opts, tt.opts) {
 				t.Errorf("New() opts = %v, want %v", gen.opts, tt.opts)
 			}
 		})
@@ -70,6 +75,7 @@ func TestGenerate_SingleProject(t *testing.T) {
 				".agent/architecture.md",
 				".cursorrules",
 				".claude/settings.json",
+				".github/copilot-instructions.md",
 			},
 		},
 		{
@@ -160,6 +166,9 @@ func TestGenerate_Monorepo(t *testing.T) {
 		".agent/architecture.md",
 		".cursorrules",
 		".claude/settings.json",
+		".github/copilot-instructions.md",
+		".github/instructions/backend.instructions.md",
+		".github/instructions/frontend.instructions.md",
 	}
 
 	for _, f := range rootFiles {
@@ -288,13 +297,12 @@ func TestMigrateLegacyAgents(t *testing.T) {
 		name           string
 		setupFiles     map[string]string // files to create before migration
 		expectMigrated bool
-		expectLegacy   bool // should .agent/AGENTS_LEGACY.md exist after
+		expectLegacy   string // content of .agent/AGENTS_LEGACY.md after, "" for none
 	}{
 		{
 			name:           "no existing AGENTS.md",
 			setupFiles:     map[string]string{},
 			expectMigrated: false,
-			expectLegacy:   false,
 		},
 		{
 			name: "migrates existing AGENTS.md",
@@ -302,16 +310,33 @@ func TestMigrateLegacyAgents(t *testing.T) {
 				"AGENTS.md": "# Original content",
 			},
 			expectMigrated: true,
-			expectLegacy:   true,
+			expectLegacy:   "# Original content",
 		},
 		{
-			name: "legacy already exists",
+			name: "appends to a legacy file written by import",
 			setupFiles: map[string]string{
-				"AGENTS.md":               "# New content",
-				".agent/AGENTS_LEGACY.md": "# Old content",
+				"AGENTS.md":               "# New content\n",
+				".agent/AGENTS_LEGACY.md": "# Old content\n",
 			},
-			expectMigrated: true, // returns true because legacy exists
-			expectLegacy:   true,
+			expectMigrated: true,
+			expectLegacy:   "# Old content\n\n# New content\n",
+		},
+		{
+			name: "legacy already holds AGENTS.md",
+			setupFiles: map[string]string{
+				"AGENTS.md":               "# Original content\n",
+				".agent/AGENTS_LEGACY.md": "# Original content\n",
+			},
+			expectMigrated: true,
+			expectLegacy:   "# Original content\n",
+		},
+		{
+			name: "generated AGENTS.md is not migrated",
+			setupFiles: map[string]string{
+				"AGENTS.md":            "# Agent Context Router\n",
+				".agent/manifest.json": `{"version": 1, "files": {"AGENTS.md": "` + manifest.Hash([]byte("# Agent Context Router\n")) + `"}}`,
+			},
+			expectMigrated: false,
 		},
 	}
 
@@ -331,7 +356,7 @@ func TestMigrateLegacyAgents(t *testing.T) {
 			}
 
 			gen := New(Options{})
-			migrated, err := gen.migrateLegacyAgents(dir)
+			migrated, err := gen.migrateLegacyAgents(dir, dir)
 
 			if err != nil {
 				t.Fatalf("migrateLegacyAgents() error = %v", err)
@@ -341,12 +366,9 @@ func TestMigrateLegacyAgents(t *testing.T) {
 				t.Errorf("migrateLegacyAgents() = %v, want %v", migrated, tt.expectMigrated)
 			}
 
-			legacyPath := filepath.Join(dir, ".agent", "AGENTS_LEGACY.md")
-
-			if tt.expectLegacy {
-				if _, err := os.Stat(legacyPath); os.IsNotExist(err) {
-					t.Error("expected legacy file to exist")
-				}
+			legacy, _ := os.ReadFile(filepath.Join(dir, ".agent", "AGENTS_LEGACY.md"))
+			if string(legacy) != tt.expectLegacy {
+				t.Errorf("legacy file = %q, want %q", legacy, tt.expectLegacy)
 			}
 		})
 	}
@@ -363,7 +385,7 @@ func TestMigrateLegacyAgents_DryRun(t *testing.T) {
 	}
 
 	gen := New(Options{DryRun: true})
-	migrated, err := gen.migrateLegacyAgents(dir)
+	migrated, err := gen.migrateLegacyAgents(dir, dir)
 
 	if err != nil {
 		t.Fatalf("migrateLegacyAgents() error = %v", err)
@@ -385,17 +407,19 @@ func TestMigrateLegacyAgents_DryRun(t *testing.T) {
 	}
 }
 
-func TestWriteTemplate_CreatesDirectories(t *testing.T) {
+func TestWriteFiles_CreatesDirectories(t *testing.T) {
 	dir := t.TempDir()
 
-	gen := New(Options{})
+	gen := New(Options{Quiet: true})
+	files, err := gen.Render(dir, []detector.Result{{Path: dir, Stack: detector.StackGo}}, false)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
 
-	// Write to a nested path that doesn't exist
+	// Write a rendered file to a nested path that doesn't exist
 	nestedPath := filepath.Join(dir, "deep", "nested", "path", "file.md")
-	err := gen.writeTemplate(nestedPath, "agentignore.tmpl", struct{}{})
-
-	if err != nil {
-		t.Fatalf("writeTemplate() error = %v", err)
+	if _, err := gen.WriteFiles([]File{{Path: nestedPath, Content: files[0].Content}}); err != nil {
+		t.Fatalf("WriteFiles() error = %v", err)
 	}
 
 	if _, err := os.Stat(nestedPath); os.IsNotExist(err) {
@@ -403,21 +427,465 @@ func TestWriteTemplate_CreatesDirectories(t *testing.T) {
 	}
 }
 
-func TestWriteTemplate_TemplateFallback(t *testing.T) {
+func TestRenderTemplate_GenericFallback(t *testing.T) {
+	gen := New(Options{})
+
+	// A stack-specific name without a template of its own falls back to
+	// the generic template
+	content, err := gen.renderTemplate("go/agentignore.tmpl", templateData{Stack: detector.StackGo})
+	if err != nil {
+		t.Fatalf("renderTemplate() error = %v", err)
+	}
+	if len(content) == 0 {
+		t.Error("renderTemplate() returned no content")
+	}
+}
+
+func TestRender_DoesNotTouchDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	gen := New(Options{})
+	results := []detector.Result{{Path: dir, Stack: detector.StackGo}}
+
+	files, err := gen.Render(dir, results, false)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("Render() returned no files")
+	}
+	for _, f := range files {
+		if !filepath.IsAbs(f.Path) {
+			t.Errorf("file path %q is not absolute", f.Path)
+		}
+		if len(f.Content) == 0 {
+			t.Errorf("file %s rendered empty", f.Path)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("Render() wrote %d entries", len(entries))
+	}
+}
+
+func TestWriteFiles_ReportsWritten(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "keep.md")
+	os.WriteFile(existing, []byte("keep"), 0644)
+
+	gen := New(Options{Quiet: true})
+	written, err := gen.WriteFiles([]File{
+		{Path: existing, Content: []byte("new")},
+		{Path: filepath.Join(dir, "sub", "new.md"), Content: []byte("new")},
+	})
+	if err != nil {
+		t.Fatalf("WriteFiles() error = %v", err)
+	}
+
+	if len(written) != 1 || written[0] != filepath.Join(dir, "sub", "new.md") {
+		t.Errorf("written = %v, want only sub/new.md", written)
+	}
+}
+
+func TestRender_CustomStackFallsBackToUnknownTemplates(t *testing.T) {
 	dir := t.TempDir()
 
 	gen := New(Options{})
+	results := []detector.Result{{Path: dir, Stack: detector.StackType("rust")}}
 
-	// Try to write with a stack-specific template that falls back to generic
-	// The code should fall back gracefully
-	path := filepath.Join(dir, "test.md")
+	files, err := gen.Render(dir, results, false)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	found := false
+	for _, f := range files {
+		if f.Path == filepath.Join(dir, ".agent", "stack.md") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected .agent/stack.md from the unknown stack template")
+	}
+}
+
+func TestRender_StackTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	tmplDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmplDir, "stack.md.tmpl"), []byte("# {{.Stack}} stack\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
 
-	// Create template data
-	data := templateData{Stack: detector.StackGo}
+	gen := New(Options{TemplateDirs: map[detector.StackType]string{"bazel": tmplDir}})
+	results := []detector.Result{{Path: dir, Stack: detector.StackType("bazel")}}
 
-	// This should work because agentignore.tmpl exists
-	err := gen.writeTemplate(path, "agentignore.tmpl", data)
+	files, err := gen.Render(dir, results, false)
 	if err != nil {
-		t.Fatalf("writeTemplate() error = %v", err)
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	contents := map[string]string{}
+	for _, f := range files {
+		contents[f.Path] = string(f.Content)
+	}
+	if got := contents[filepath.Join(dir, ".agent", "stack.md")]; got != "# bazel stack\n" {
+		t.Errorf("stack.md = %q, want custom template output", got)
+	}
+	// Templates missing from the directory fall back to the generic ones
+	if contents[filepath.Join(dir, ".agent", "testing.md")] == "" {
+		t.Error("expected testing.md from the generic templates")
+	}
+}
+
+func TestRender_ProjectCommands(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "Makefile"), []byte("test: ## Run the suite\n\tgo test ./...\n\nrelease:\n\tgoreleaser\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"scripts": {"lint": "eslint ."}}`), 0644)
+
+	gen := New(Options{Integrations: []Integration{}})
+	files := renderMap(t, gen, dir, []detector.Result{{Path: dir, Stack: detector.StackGo}}, false)
+
+	agents := files["AGENTS.md"]
+	if want := "- **Test**: `make test`\n- **Lint**: `npm run lint`\n"; !strings.Contains(agents, want) {
+		t.Errorf("AGENTS.md missing %q:\n%s", want, agents)
+	}
+	if strings.Contains(agents, "make build") {
+		t.Error("AGENTS.md should not list targets the Makefile lacks")
+	}
+
+	cheatSheet := files[".agent/commands.md"]
+	for _, want := range []string{
+		"## Project Commands",
+		"### Makefile\n```bash\nmake test     # Run the suite\nmake release\n```",
+		"### package.json\n```bash\nnpm run lint  # eslint .\n```",
+	} {
+		if !strings.Contains(cheatSheet, want) {
+			t.Errorf("commands.md missing %q:\n%s", want, cheatSheet)
+		}
+	}
+	if strings.Contains(cheatSheet, "make build") {
+		t.Errorf("commands.md should not list targets the Makefile lacks:\n%s", cheatSheet)
+	}
+
+	testingMD := files[".agent/testing.md"]
+	if want := "## Test Commands\n```bash\nmake test\n```\n"; !strings.Contains(testingMD, want) {
+		t.Errorf("testing.md missing %q:\n%s", want, testingMD)
+	}
+	if strings.Contains(testingMD, "go test -race ./...") {
+		t.Errorf("testing.md should not list the stack defaults:\n%s", testingMD)
+	}
+}
+
+func TestRender_ProjectCommandsFromGeneratedMakefile(t *testing.T) {
+	dir := t.TempDir()
+	results := []detector.Result{{Path: dir, Stack: detector.StackNode}}
+	gen := New(Options{Integrations: []Integration{}, Quiet: true})
+
+	files := renderMap(t, gen, dir, results, false)
+	if want := "- **Install**: `make install`"; !strings.Contains(files["AGENTS.md"], want) {
+		t.Errorf("AGENTS.md should use the generated Makefile, missing %q:\n%s", want, files["AGENTS.md"])
+	}
+
+	// Once written, the routers sync renders are unchanged
+	if err := gen.Generate(dir, results, false); err != nil {
+		t.Fatal(err)
+	}
+	derived, err := gen.RenderDerived(dir, results, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(derived[0].Content) != files["AGENTS.md"] {
+		t.Errorf("derived AGENTS.md differs from the rendered one:\n%s\nwant\n%s", derived[0].Content, files["AGENTS.md"])
+	}
+}
+
+func TestRender_NoProjectCommands(t *testing.T) {
+	dir := t.TempDir()
+	gen := New(Options{Integrations: []Integration{}})
+
+	// A monorepo subproject gets no generated Makefile
+	files := renderMap(t, gen, dir, []detector.Result{{Path: filepath.Join(dir, "svc"), Stack: detector.StackJava}}, true)
+	if !strings.Contains(files["svc/AGENTS.md"], "- **Test**: `./mvnw test`") {
+		t.Errorf("svc/AGENTS.md should keep the stack defaults:\n%s", files["svc/AGENTS.md"])
+	}
+	if strings.Contains(files["svc/.agent/commands.md"], "## Project Commands") {
+		t.Error("commands.md should have no project commands")
+	}
+	if !strings.Contains(files["svc/.agent/commands.md"], "## Build & Run\n```bash\n./mvnw package") {
+		t.Errorf("commands.md should keep the stack defaults:\n%s", files["svc/.agent/commands.md"])
+	}
+}
+
+func TestRender_CICommands(t *testing.T) {
+	dir := t.TempDir()
+	workflow := `jobs:
+  api:
+    steps:
+      - uses: actions/setup-go@v5
+        with:
+          go-version: "1.22"
+      - run: cd services/api && go test -race ./...
+      - run: golangci-lint run
+        working-directory: services/api
+  root:
+    steps:
+      - run: make test-all
+`
+	os.MkdirAll(filepath.Join(dir, ".github", "workflows"), 0755)
+	os.WriteFile(filepath.Join(dir, ".github", "workflows", "ci.yml"), []byte(workflow), 0644)
+
+	gen := New(Options{Integrations: []Integration{}})
+	results := []detector.Result{
+		{Path: filepath.Join(dir, "services", "api"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+	}
+	files := renderMap(t, gen, dir, results, true)
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"services/api/AGENTS.md", "2. **Run tests**: `go test -race ./...`\n"},
+		{"services/api/.agent/testing.md", "## In CI\n\nCI runs the tests with:\n\n```bash\ngo test -race ./...\n```\n\nEnvironment:\n- actions/setup-go (go-version: 1.22)\n"},
+		{"services/api/.agent/commands.md", "```bash\ngo test -race ./...  # test, ci.yml: api\ngolangci-lint run    # lint, ci.yml: api\n```"},
+		// Projects CI does not reach keep the defaults
+		{"web/AGENTS.md", "2. **Run tests**: `pnpm test`\n"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(files[tt.file], tt.want) {
+			t.Errorf("%s missing %q:\n%s", tt.file, tt.want, files[tt.file])
+		}
+	}
+	if strings.Contains(files["web/.agent/testing.md"], "## In CI") {
+		t.Error("web/.agent/testing.md should have no CI section")
+	}
+	if !strings.Contains(files["web/.agent/testing.md"], "pnpm test:watch") {
+		t.Errorf("web/.agent/testing.md should keep the stack defaults:\n%s", files["web/.agent/testing.md"])
+	}
+	for _, file := range []string{"services/api/.agent/testing.md", "services/api/.agent/commands.md"} {
+		if strings.Contains(files[file], "go test ./...  ") || strings.Contains(files[file], "make test  ") {
+			t.Errorf("%s should replace the stack defaults with the CI commands:\n%s", file, files[file])
+		}
+	}
+}
+
+func TestRender_GoArchitecture(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "cmd", "app"), 0755)
+	os.MkdirAll(filepath.Join(dir, "store"), 0755)
+	os.WriteFile(filepath.Join(dir, "cmd", "app", "main.go"), []byte("// Command app serves orders.\npackage main\n\nimport _ \"example.com/app/store\"\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "store", "store.go"), []byte("// Package store persists orders.\npackage store\n\ntype Repo interface{}\n"), 0644)
+
+	gen := New(Options{Integrations: []Integration{}})
+	files := renderMap(t, gen, dir, []detector.Result{{Path: dir, Stack: detector.StackGo}}, false)
+
+	got := files[".agent/architecture.md"]
+	for _, want := range []string{
+		"Go module `example.com/app` with 2 packages.",
+		"## Entry Points\n\n- `cmd/app` — Command app serves orders.\n",
+		"| store | Persists orders. | `store` | 0 | `Repo` |\n",
+		"- **Layer 1**: `cmd/app`\n",
+		"## Data Flow\n\nEach entry point and the components it reaches, from the outermost layer inwards.\n\n- `cmd/app` → `store`\n",
+		"```mermaid\ngraph TD\n",
+		"    n_cmd_app --> n_store\n```\n",
+		"## Key Design Decisions",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("architecture.md missing %q:\n%s", want, got)
+		}
+	}
+
+	if strings.Contains(got, "## Import Cycles") {
+		t.Errorf("architecture.md should list no cycles:\n%s", got)
+	}
+
+	// Without Go sources the placeholders stay
+	files = renderMap(t, gen, t.TempDir(), []detector.Result{{Stack: detector.StackGo}}, false)
+	if got := files[".agent/architecture.md"]; !strings.Contains(got, "<!-- List and describe the main components") {
+		t.Errorf("architecture.md should keep its placeholders:\n%s", got)
+	}
+}
+
+func TestRender_GoArchitectureBounded(t *testing.T) {
+	// A mid-sized module: a deep chain of documented packages and many
+	// commands
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644)
+	doc := "handles one part of the order pipeline, from validating the request to persisting the result and notifying the interested services."
+	for i := 0; i < 60; i++ {
+		pkg := fmt.Sprintf("pkg%02d", i)
+		src := fmt.Sprintf("// Package %s %s\npackage %s\n", pkg, doc, pkg)
+		for j := max(i-3, 0); j < i; j++ {
+			src += fmt.Sprintf("\nimport _ \"example.com/app/internal/pkg%02d\"\n", j)
+		}
+		src += "\ntype Store interface{}\n"
+		os.MkdirAll(filepath.Join(dir, "internal", pkg), 0755)
+		os.WriteFile(filepath.Join(dir, "internal", pkg, pkg+".go"), []byte(src), 0644)
+	}
+	for i := 0; i < 10; i++ {
+		cmd := filepath.Join(dir, "cmd", fmt.Sprintf("tool%d", i))
+		os.MkdirAll(cmd, 0755)
+		os.WriteFile(filepath.Join(cmd, "main.go"), []byte("// Command tool "+doc+"\npackage main\n\nimport _ \"example.com/app/internal/pkg59\"\n"), 0644)
+	}
+
+	gen := New(Options{Integrations: []Integration{}})
+	files := renderMap(t, gen, dir, []detector.Result{{Path: dir, Stack: detector.StackGo}}, false)
+	got := files[".agent/architecture.md"]
+	if !strings.Contains(got, "with 70 packages.") {
+		t.Fatalf("architecture.md should describe the module:\n%s", got)
+	}
+	if n := tokens.Count(got); n > 2000 {
+		t.Errorf("architecture.md is ~%d tokens, over the default .agent/*.md budget of 2000:\n%s", n, got)
+	}
+}
+
+func TestRender_PythonArchitecture(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"pyproject.toml":       "[project]\nname = \"shop\"\n",
+		"shop/__init__.py":     "\"\"\"Order service.\"\"\"\n",
+		"shop/__main__.py":     "from shop.api import app\n",
+		"shop/api/__init__.py": "from ..db import session\n",
+		"shop/db/__init__.py":  "from shop.api import app\n",
+		"tests/test_api.py":    "import shop.api\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	gen := New(Options{Integrations: []Integration{}})
+	files := renderMap(t, gen, dir, []detector.Result{{Path: dir, Stack: detector.StackPython}}, false)
+
+	got := files[".agent/architecture.md"]
+	for _, want := range []string{
+		"Python project `shop` with 3 packages.",
+		"## Entry Points\n\n- `shop` — Order service.\n",
+		"## Data Flow\n",
+		"- `shop` → `shop/",
+		"## Import Cycles\n",
+		"- `shop/api` ⇄ `shop/db`\n",
+		"    n_shop --> n_shop_api\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("architecture.md missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "tests") {
+		t.Errorf("architecture.md should skip tests:\n%s", got)
+	}
+}
+
+func TestRender_TypeScriptArchitecture(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"package.json":      `{"name": "web", "main": "dist/index.js"}`,
+		"tsconfig.json":     `{"compilerOptions": {"baseUrl": ".", "paths": {"~/*": ["src/*"]}}}`,
+		"src/index.ts":      "import { api } from '~/api/client'\n",
+		"src/api/client.ts": "import axios from 'axios'\nexport interface Client {}\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	gen := New(Options{Integrations: []Integration{}})
+	files := renderMap(t, gen, dir, []detector.Result{{Path: dir, Stack: detector.StackNode}}, false)
+
+	got := files[".agent/architecture.md"]
+	for _, want := range []string{
+		"TypeScript project `web` with 2 directories.",
+		"| api |  | `src/api` | 0 | `Client` |\n",
+		"- `src` → `src/api`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("architecture.md missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRender_MonorepoDependencies(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"libs/core/go.mod":     "module example.com/core\n",
+		"services/api/go.mod":  "module example.com/api\n\nrequire example.com/core v0.0.0\n",
+		"web/package.json":     `{"name": "web"}`,
+		"services/jobs/go.mod": "module example.com/jobs\n\nreplace example.com/api => ../api\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	results := []detector.Result{
+		{Path: filepath.Join(dir, "libs", "core"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "services", "api"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "services", "jobs"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+	}
+
+	gen := New(Options{Integrations: []Integration{}})
+	files := renderMap(t, gen, dir, results, true)
+
+	got := files[".agent/overview.md"]
+	for _, want := range []string{
+		"### libs/core\n- **Stack**: go\n- **Context**: `libs/core/.agent/`\n" +
+			"- **Depended on by**: `services/api`\n" +
+			"- **Blast radius**: a change here can break `services/api`, `services/jobs`\n",
+		"### services/jobs\n- **Stack**: go\n- **Context**: `services/jobs/.agent/`\n- **Depends on**: `services/api`\n\n",
+		"### web\n- **Stack**: node\n- **Context**: `web/.agent/`\n\n",
+		"    n_services_api --> n_libs_core\n",
+		"- **Layer 0**: `libs/core`, `web`\n- **Layer 1**: `services/api`\n- **Layer 2**: `services/jobs`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("overview.md missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, dir) || strings.Contains(got, "## Dependency Cycles") {
+		t.Errorf("overview.md should use relative paths and list no cycles:\n%s", got)
+	}
+	if got := files["Makefile"]; !strings.Contains(got, "test-affected:\n\t@cmds=$$(agentic-repo affected --base $(BASE) --format commands --kind test) && sh -ex -c \"$$cmds\"") {
+		t.Errorf("Makefile missing test-affected:\n%s", got)
+	}
+
+	// Independent projects get a note instead of a graph
+	os.Remove(filepath.Join(dir, "services", "api", "go.mod"))
+	os.Remove(filepath.Join(dir, "services", "jobs", "go.mod"))
+	files = renderMap(t, gen, dir, results, true)
+	if got := files[".agent/overview.md"]; !strings.Contains(got, "No dependencies between the projects were found") || strings.Contains(got, "## Build Order") {
+		t.Errorf("overview.md without dependencies:\n%s", got)
+	}
+}
+
+func TestRender_AgentIgnore(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":                       "module example.com/shop\n",
+		"api/v1/order.pb.go":           "package v1\n",
+		"mocks/store.go":               "// Code generated by MockGen. DO NOT EDIT.\npackage mocks\n",
+		"mocks/client.go":              "// Code generated by MockGen. DO NOT EDIT.\npackage mocks\n",
+		"testdata/fixtures/order.json": "{}\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	gen := New(Options{Integrations: []Integration{}})
+	files := renderMap(t, gen, dir, []detector.Result{{Path: dir, Stack: detector.StackGo}}, false)
+
+	got := files[".agentignore"]
+	for _, want := range []string{
+		"node_modules/\n",
+		"\n\n# Generated code found in this repository\n",
+		"# 1 file, e.g. api/v1/order.pb.go\n*.pb.go\n",
+		"# all 2 files marked like \"Code generated by MockGen. DO NOT EDIT.\"\n/mocks/\n",
+		"# Test fixtures found in this repository\n# test data, read only when a test needs it\n/testdata/fixtures/\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf(".agentignore missing %q:\n%s", want, got)
+		}
 	}
 }
@@ -0,0 +1,267 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/agentignore"
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// Integration is an AI tool whose native instruction files are generated
+type Integration string
+
+const (
+	IntegrationCursor Integration = "cursor"
+	// IntegrationCursorLegacy adds the single-file .cursorrules next to the
+	// .cursor/rules directory; it has no effect without IntegrationCursor
+	IntegrationCursorLegacy Integration = "cursor_legacy"
+	IntegrationClaude       Integration = "claude"
+	IntegrationCopilot      Integration = "copilot"
+	IntegrationWindsurf     Integration = "windsurf"
+	IntegrationCline        Integration = "cline"
+	IntegrationAider        Integration = "aider"
+	IntegrationGemini       Integration = "gemini"
+	IntegrationContinue     Integration = "continue"
+	// IntegrationMCP registers "agentic-repo serve --mcp" with the MCP
+	// clients among the enabled integrations (Claude Code and Cursor). It
+	// is opt-in since the server's run_tests tool runs shell commands.
+	IntegrationMCP Integration = "mcp"
+)
+
+// targetInput is the scan a target renders its files from
+type targetInput struct {
+	root       string
+	results    []detector.Result
+	isMonorepo bool
+	// project is the template data of a single-project repository
+	project templateData
+	// mono is the template data of a monorepo root
+	mono monorepoData
+	// rules is the content of RulesFile
+	rules string
+	// ignore lists what .agentignore should hide beyond its defaults,
+	// scanned only when the full set of files is rendered
+	ignore []agentignore.Section
+	// enabled holds the integrations being generated
+	enabled map[Integration]bool
+}
+
+// target generates the native files of one AI tool. Spec paths are
+// relative to the repository root.
+type target struct {
+	name Integration
+	// byDefault targets are generated when no integrations are configured
+	byDefault bool
+	// requires is another integration that must be enabled as well
+	requires Integration
+	specs    func(in targetInput) []fileSpec
+}
+
+// targets lists every integration in generation order. Adding an AI tool
+// means adding its templates and an entry here.
+var targets = []target{
+	{name: IntegrationCursor, byDefault: true, specs: cursorSpecs},
+	{name: IntegrationCursorLegacy, byDefault: true, requires: IntegrationCursor, specs: cursorLegacySpecs},
+	{name: IntegrationClaude, byDefault: true, specs: claudeSpecs},
+	{name: IntegrationCopilot, byDefault: true, specs: copilotSpecs},
+	{name: IntegrationWindsurf, specs: windsurfSpecs},
+	{name: IntegrationCline, specs: clineSpecs},
+	{name: IntegrationAider, specs: aiderSpecs},
+	{name: IntegrationGemini, specs: geminiSpecs},
+	{name: IntegrationContinue, specs: continueSpecs},
+	{name: IntegrationMCP, specs: mcpSpecs},
+}
+
+// KnownIntegrations returns every integration in generation order
+func KnownIntegrations() []Integration {
+	names := make([]Integration, 0, len(targets))
+	for _, t := range targets {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// DefaultIntegrations returns the integrations generated when none are
+// configured
+func DefaultIntegrations() []Integration {
+	var names []Integration
+	for _, t := range targets {
+		if t.byDefault {
+			names = append(names, t.name)
+		}
+	}
+	return names
+}
+
+// ParseIntegration validates an integration name
+func ParseIntegration(name string) (Integration, error) {
+	for _, t := range targets {
+		if string(t.name) == name {
+			return t.name, nil
+		}
+	}
+	known := make([]string, 0, len(targets))
+	for _, t := range targets {
+		known = append(known, string(t.name))
+	}
+	return "", fmt.Errorf("unknown integration %q (want one of %s)", name, strings.Join(known, ", "))
+}
+
+// enabled reports whether files for the integration should be generated
+func (g *Generator) enabled(i Integration) bool {
+	integrations := g.opts.Integrations
+	if integrations == nil {
+		integrations = DefaultIntegrations()
+	}
+	for _, enabled := range integrations {
+		if enabled == i {
+			return true
+		}
+	}
+	return false
+}
+
+// integrationSpecs lists the files for the enabled AI tool integrations
+func (g *Generator) integrationSpecs(in targetInput) []fileSpec {
+	in.enabled = map[Integration]bool{}
+	for _, t := range targets {
+		in.enabled[t.name] = g.enabled(t.name) && (t.requires == "" || g.enabled(t.requires))
+	}
+
+	var specs []fileSpec
+	for _, t := range targets {
+		if in.enabled[t.name] {
+			specs = append(specs, t.specs(in)...)
+		}
+	}
+	return under(in.root, specs)
+}
+
+// mcpSpecs lists the MCP client configs that start the built-in server
+func mcpSpecs(in targetInput) []fileSpec {
+	var specs []fileSpec
+	if in.enabled[IntegrationClaude] {
+		specs = append(specs, fileSpec{".mcp.json", "mcp.json.tmpl", nil})
+	}
+	if in.enabled[IntegrationCursor] {
+		specs = append(specs, fileSpec{".cursor/mcp.json", "mcp.json.tmpl", nil})
+	}
+	return specs
+}
+
+// cursorSpecs lists the .cursor/rules/*.mdc project rules
+func cursorSpecs(in targetInput) []fileSpec {
+	if in.isMonorepo {
+		return cursorMonorepoRuleSpecs(in.mono)
+	}
+	return cursorRuleSpecs(in.project)
+}
+
+// cursorLegacySpecs lists the single-file .cursorrules
+func cursorLegacySpecs(in targetInput) []fileSpec {
+	if in.isMonorepo {
+		return []fileSpec{{".cursorrules", "cursorrules-monorepo.tmpl", in.mono}}
+	}
+	return []fileSpec{{".cursorrules", "cursorrules.tmpl", in.project}}
+}
+
+// copilotSpecs lists the Copilot repository instructions and, in a
+// monorepo, instructions scoped to each subproject's path
+func copilotSpecs(in targetInput) []fileSpec {
+	if !in.isMonorepo {
+		return []fileSpec{{".github/copilot-instructions.md", "copilot-instructions.md.tmpl", in.project}}
+	}
+
+	specs := []fileSpec{{".github/copilot-instructions.md", "copilot-instructions-monorepo.md.tmpl", in.mono}}
+	for _, project := range in.mono.Projects {
+		path := filepath.Join(".github", "instructions", instructionsName(project.RelPath)+".instructions.md")
+		specs = append(specs, fileSpec{path, "copilot-path-instructions.md.tmpl", project})
+	}
+	return specs
+}
+
+// instructionsName turns a root-relative project path into a file name,
+// e.g. "services/api" becomes "services-api"
+func instructionsName(relPath string) string {
+	return strings.ReplaceAll(relPath, "/", "-")
+}
+
+// cursorRuleData is the data for a .cursor/rules/*.mdc template
+type cursorRuleData struct {
+	templateData
+	// Prefix is prepended to project paths: "" for a single project,
+	// "services/api/" for a monorepo subproject
+	Prefix string
+	// Globs is the comma-separated globs the rule auto-attaches to
+	Globs string
+	// Projects lists subprojects for the monorepo root rule
+	Projects []templateData
+}
+
+// cursorRuleSpecs lists the Cursor rules for a single project
+func cursorRuleSpecs(data templateData) []fileSpec {
+	return []fileSpec{
+		{".cursor/rules/project.mdc", "cursor-project.mdc.tmpl", cursorRuleData{templateData: data}},
+		{".cursor/rules/testing.mdc", "cursor-testing.mdc.tmpl", cursorRuleData{
+			templateData: data,
+			Globs:        strings.Join(testGlobs(data.Stack, ""), ","),
+		}},
+		{".cursor/rules/code-review.mdc", "cursor-review.mdc.tmpl", cursorRuleData{templateData: data}},
+	}
+}
+
+// cursorMonorepoRuleSpecs lists the Cursor rules for a monorepo: an
+// always-applied router plus, per subproject, a rule scoped to its path
+// and a testing rule scoped to its test files
+func cursorMonorepoRuleSpecs(monoData monorepoData) []fileSpec {
+	root := cursorRuleData{
+		templateData: templateData{Stack: detector.StackUnknown, IsMonorepo: true, Rules: monoData.Rules},
+		Projects:     monoData.Projects,
+	}
+	specs := []fileSpec{
+		{".cursor/rules/monorepo.mdc", "cursor-monorepo.mdc.tmpl", root},
+		{".cursor/rules/code-review.mdc", "cursor-review.mdc.tmpl", root},
+	}
+
+	for _, project := range monoData.Projects {
+		prefix := project.RelPath + "/"
+		name := instructionsName(project.RelPath)
+		specs = append(specs,
+			fileSpec{filepath.Join(".cursor", "rules", name+".mdc"), "cursor-project.mdc.tmpl", cursorRuleData{
+				templateData: project,
+				Prefix:       prefix,
+				Globs:        prefix + "**",
+			}},
+			fileSpec{filepath.Join(".cursor", "rules", name+"-testing.mdc"), "cursor-testing.mdc.tmpl", cursorRuleData{
+				templateData: project,
+				Prefix:       prefix,
+				Globs:        strings.Join(testGlobs(project.Stack, prefix), ","),
+			}},
+		)
+	}
+	return specs
+}
+
+// testGlobs returns globs matching a stack's test files below prefix
+func testGlobs(stack detector.StackType, prefix string) []string {
+	var globs []string
+	switch stack {
+	case detector.StackGo:
+		globs = []string{"**/*_test.go"}
+	case detector.StackPython:
+		globs = []string{"**/test_*.py", "**/*_test.py", "**/conftest.py"}
+	case detector.StackNode:
+		globs = []string{"**/*.test.ts", "**/*.test.tsx", "**/*.test.js", "**/*.spec.ts", "**/*.spec.js"}
+	case detector.StackJava:
+		globs = []string{"**/src/test/**"}
+	default:
+		globs = []string{"**/test/**", "**/tests/**", "**/*_test.*", "**/*.test.*"}
+	}
+
+	for i, g := range globs {
+		globs[i] = prefix + g
+	}
+	return globs
+}
@@ -0,0 +1,489 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// renderMap renders results and indexes the content by root-relative path
+func renderMap(t *testing.T, gen *Generator, root string, results []detector.Result, isMonorepo bool) map[string]string {
+	t.Helper()
+	files, err := gen.Render(root, results, isMonorepo)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	contents := map[string]string{}
+	for _, f := range files {
+		rel, _ := filepath.Rel(root, f.Path)
+		contents[filepath.ToSlash(rel)] = string(f.Content)
+	}
+	return contents
+}
+
+func TestRender_CopilotInstructions(t *testing.T) {
+	dir := t.TempDir()
+	results := []detector.Result{{
+		Path:  dir,
+		Stack: detector.StackNode,
+		Tools: map[string]string{"package_manager": "pnpm"},
+	}}
+
+	files := renderMap(t, New(Options{}), dir, results, false)
+
+	content, ok := files[".github/copilot-instructions.md"]
+	if !ok {
+		t.Fatal("expected .github/copilot-instructions.md")
+	}
+	for _, want := range []string{"Node.js/TypeScript project", ".agent/testing.md", "package_manager: pnpm"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("copilot-instructions.md missing %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestRender_CopilotPathInstructions(t *testing.T) {
+	dir := t.TempDir()
+	results := []detector.Result{
+		{Path: dir, Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "services", "api"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+	}
+
+	files := renderMap(t, New(Options{}), dir, results, true)
+
+	api, ok := files[".github/instructions/services-api.instructions.md"]
+	if !ok {
+		t.Fatalf("expected path-scoped instructions for services/api, got %v", keys(files))
+	}
+	if !strings.HasPrefix(api, "---\napplyTo: \"services/api/**\"\n---\n") {
+		t.Errorf("unexpected front matter:\n%s", api)
+	}
+	if _, ok := files[".github/instructions/web.instructions.md"]; !ok {
+		t.Error("expected path-scoped instructions for web")
+	}
+	scoped := 0
+	for name := range files {
+		if strings.HasPrefix(name, ".github/instructions/") {
+			scoped++
+		}
+	}
+	if scoped != 2 {
+		t.Errorf("got %d path-scoped files, want 2 (none for the root project)", scoped)
+	}
+
+	root := files[".github/copilot-instructions.md"]
+	if !strings.Contains(root, "`services/api/` — go project") {
+		t.Errorf("root instructions should list projects by relative path:\n%s", root)
+	}
+}
+
+func TestRender_Integrations(t *testing.T) {
+	tests := []struct {
+		name         string
+		integrations []Integration
+		want         []string
+		notWant      []string
+	}{
+		{
+			name:    "defaults enable everything",
+			want:    []string{".cursorrules", ".claude/settings.json", ".github/copilot-instructions.md"},
+			notWant: nil,
+		},
+		{
+			name:         "copilot only",
+			integrations: []Integration{IntegrationCopilot},
+			want:         []string{".github/copilot-instructions.md"},
+			notWant:      []string{".cursorrules", ".claude/settings.json"},
+		},
+		{
+			name:         "none",
+			integrations: []Integration{},
+			notWant:      []string{".cursorrules", ".claude/settings.json", ".github/copilot-instructions.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			results := []detector.Result{{Path: dir, Stack: detector.StackGo}}
+
+			files := renderMap(t, New(Options{Integrations: tt.integrations}), dir, results, false)
+
+			for _, f := range tt.want {
+				if _, ok := files[f]; !ok {
+					t.Errorf("expected %s", f)
+				}
+			}
+			for _, f := range tt.notWant {
+				if _, ok := files[f]; ok {
+					t.Errorf("did not expect %s", f)
+				}
+			}
+			if _, ok := files["AGENTS.md"]; !ok {
+				t.Error("core files must not depend on integrations")
+			}
+		})
+	}
+}
+
+func TestInstructionsName(t *testing.T) {
+	tests := []struct {
+		relPath  string
+		expected string
+	}{
+		{"api", "api"},
+		{"services/api", "services-api"},
+	}
+	for _, tt := range tests {
+		if got := instructionsName(tt.relPath); got != tt.expected {
+			t.Errorf("instructionsName(%q) = %q, want %q", tt.relPath, got, tt.expected)
+		}
+	}
+}
+
+// keys returns the keys of a rendered file map
+func keys(files map[string]string) []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestRender_CursorRules(t *testing.T) {
+	dir := t.TempDir()
+	results := []detector.Result{{Path: dir, Stack: detector.StackGo}}
+
+	files := renderMap(t, New(Options{}), dir, results, false)
+
+	tests := []struct {
+		file     string
+		contains []string
+	}{
+		{".cursor/rules/project.mdc", []string{"---\ndescription: ", "alwaysApply: true\n", "golangci-lint"}},
+		{".cursor/rules/testing.mdc", []string{"globs: **/*_test.go\n", "alwaysApply: false\n", "table-driven"}},
+		{".cursor/rules/code-review.mdc", []string{"alwaysApply: false\n", "CODE_REVIEW_RULES.md"}},
+	}
+	for _, tt := range tests {
+		content, ok := files[tt.file]
+		if !ok {
+			t.Errorf("expected %s", tt.file)
+			continue
+		}
+		for _, want := range tt.contains {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s missing %q:\n%s", tt.file, want, content)
+			}
+		}
+	}
+}
+
+func TestRender_CursorMonorepoRules(t *testing.T) {
+	dir := t.TempDir()
+	results := []detector.Result{
+		{Path: filepath.Join(dir, "services", "api"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+	}
+
+	files := renderMap(t, New(Options{}), dir, results, true)
+
+	if !strings.Contains(files[".cursor/rules/monorepo.mdc"], "alwaysApply: true") {
+		t.Error("expected an always-applied monorepo rule")
+	}
+
+	api := files[".cursor/rules/services-api.mdc"]
+	for _, want := range []string{"globs: services/api/**\n", "alwaysApply: false\n", "`services/api/.agent/stack.md`"} {
+		if !strings.Contains(api, want) {
+			t.Errorf("services-api.mdc missing %q:\n%s", want, api)
+		}
+	}
+
+	web := files[".cursor/rules/web-testing.mdc"]
+	if !strings.Contains(web, "globs: web/**/*.test.ts,") {
+		t.Errorf("web-testing.mdc should be scoped to web test files:\n%s", web)
+	}
+}
+
+func TestRender_CursorLegacyToggle(t *testing.T) {
+	tests := []struct {
+		name         string
+		integrations []Integration
+		wantLegacy   bool
+		wantRules    bool
+	}{
+		{"default keeps both", nil, true, true},
+		{"legacy disabled", []Integration{IntegrationCursor}, false, true},
+		{"legacy alone does nothing", []Integration{IntegrationCursorLegacy}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			results := []detector.Result{{Path: dir, Stack: detector.StackPython}}
+
+			files := renderMap(t, New(Options{Integrations: tt.integrations}), dir, results, false)
+
+			if _, ok := files[".cursorrules"]; ok != tt.wantLegacy {
+				t.Errorf(".cursorrules generated = %v, want %v", ok, tt.wantLegacy)
+			}
+			if _, ok := files[".cursor/rules/project.mdc"]; ok != tt.wantRules {
+				t.Errorf(".cursor/rules generated = %v, want %v", ok, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestTestGlobs(t *testing.T) {
+	tests := []struct {
+		stack    detector.StackType
+		prefix   string
+		expected []string
+	}{
+		{detector.StackGo, "", []string{"**/*_test.go"}},
+		{detector.StackJava, "api/", []string{"api/**/src/test/**"}},
+		{detector.StackType("bazel"), "", []string{"**/test/**", "**/tests/**", "**/*_test.*", "**/*.test.*"}},
+	}
+	for _, tt := range tests {
+		got := testGlobs(tt.stack, tt.prefix)
+		if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("testGlobs(%s, %q) = %v, want %v", tt.stack, tt.prefix, got, tt.expected)
+		}
+	}
+}
+
+func TestParseIntegration(t *testing.T) {
+	for _, i := range KnownIntegrations() {
+		if got, err := ParseIntegration(string(i)); err != nil || got != i {
+			t.Errorf("ParseIntegration(%q) = %q, %v", i, got, err)
+		}
+	}
+	if _, err := ParseIntegration("vim"); err == nil {
+		t.Error("expected error for unknown integration")
+	}
+}
+
+func TestDefaultIntegrations(t *testing.T) {
+	want := []Integration{IntegrationCursor, IntegrationCursorLegacy, IntegrationClaude, IntegrationCopilot}
+	got := DefaultIntegrations()
+	if len(got) != len(want) {
+		t.Fatalf("DefaultIntegrations() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DefaultIntegrations() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRender_AssistantTargets(t *testing.T) {
+	tests := []struct {
+		integration Integration
+		isMonorepo  bool
+		files       map[string]string // path -> expected substring
+	}{
+		{IntegrationWindsurf, false, map[string]string{".windsurfrules": "# Windsurf Rules"}},
+		{IntegrationCline, false, map[string]string{
+			".clinerules/project.md":  "# Cline Rules",
+			".clinerules/workflow.md": "`make test`",
+		}},
+		{IntegrationAider, false, map[string]string{
+			"CONVENTIONS.md":  "# Aider Rules",
+			".aider.conf.yml": "test-cmd: \"make test\"",
+		}},
+		{IntegrationGemini, false, map[string]string{"GEMINI.md": "\n@AGENTS.md\n"}},
+		{IntegrationContinue, false, map[string]string{".continue/rules/project.md": "alwaysApply: true"}},
+		{IntegrationGemini, true, map[string]string{"api/GEMINI.md": "`api` go project"}},
+		{IntegrationContinue, true, map[string]string{".continue/rules/api.md": "globs: \"api/**\""}},
+		{IntegrationAider, true, map[string]string{".aider.conf.yml": ".agent/overview.md"}},
+	}
+
+	for _, tt := range tests {
+		name := string(tt.integration)
+		if tt.isMonorepo {
+			name += " monorepo"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			results := []detector.Result{{Path: dir, Stack: detector.StackGo}}
+			if tt.isMonorepo {
+				results = []detector.Result{
+					{Path: filepath.Join(dir, "api"), Stack: detector.StackGo},
+					{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+				}
+			}
+
+			files := renderMap(t, New(Options{Integrations: []Integration{tt.integration}}), dir, results, tt.isMonorepo)
+
+			for path, want := range tt.files {
+				content, ok := files[path]
+				if !ok {
+					t.Errorf("expected %s", path)
+					continue
+				}
+				if !strings.Contains(content, want) {
+					t.Errorf("%s missing %q:\n%s", path, want, content)
+				}
+			}
+			if _, ok := files[".cursorrules"]; ok {
+				t.Error("only the selected integration should be generated")
+			}
+		})
+	}
+}
+
+func TestRender_ProjectRules(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".agent"), 0755)
+	os.WriteFile(filepath.Join(dir, filepath.FromSlash(RulesFile)), []byte("\n- Never push to main\n\n"), 0644)
+
+	tests := []struct {
+		name       string
+		isMonorepo bool
+		results    []detector.Result
+		withRules  []string
+		without    []string
+	}{
+		{
+			name:      "single project",
+			results:   []detector.Result{{Path: dir, Stack: detector.StackGo}},
+			withRules: []string{".cursorrules", ".cursor/rules/project.mdc", "CLAUDE.md", ".github/copilot-instructions.md", ".windsurfrules", "GEMINI.md", ".continue/rules/project.md"},
+			without:   []string{"AGENTS.md", ".claude/settings.json", ".cursor/rules/testing.mdc"},
+		},
+		{
+			name:       "monorepo",
+			isMonorepo: true,
+			results: []detector.Result{
+				{Path: filepath.Join(dir, "api"), Stack: detector.StackGo},
+				{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+			},
+			withRules: []string{".cursorrules", ".cursor/rules/monorepo.mdc", "CLAUDE.md", ".github/copilot-instructions.md", ".windsurfrules", "GEMINI.md", ".continue/rules/monorepo.md"},
+			without:   []string{"api/CLAUDE.md", ".cursor/rules/api.mdc", ".github/instructions/api.instructions.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := New(Options{Integrations: KnownIntegrations()})
+			files := renderMap(t, gen, dir, tt.results, tt.isMonorepo)
+
+			for _, path := range tt.withRules {
+				if !strings.Contains(files[path], "## Project Rules\n\n- Never push to main\n") {
+					t.Errorf("%s should embed the project rules:\n%s", path, files[path])
+				}
+			}
+			for _, path := range tt.without {
+				if strings.Contains(files[path], "Never push to main") {
+					t.Errorf("%s should not embed the project rules", path)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDerived(t *testing.T) {
+	dir := t.TempDir()
+	results := []detector.Result{
+		{Path: filepath.Join(dir, "api"), Stack: detector.StackGo},
+		{Path: filepath.Join(dir, "web"), Stack: detector.StackNode},
+	}
+	gen := New(Options{Integrations: []Integration{IntegrationClaude}})
+
+	files, err := gen.RenderDerived(dir, results, true)
+	if err != nil {
+		t.Fatalf("RenderDerived() error = %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+
+	want := []string{
+		"AGENTS.md", "api/AGENTS.md", "web/AGENTS.md",
+		"CLAUDE.md", ".claude/settings.json",
+		".claude/commands/test.md", ".claude/commands/lint.md", ".claude/commands/review.md",
+		"api/CLAUDE.md", "web/CLAUDE.md",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("RenderDerived() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderDerived_ExistingRouterIsNotLegacy(t *testing.T) {
+	dir := t.TempDir()
+	results := []detector.Result{{Path: dir, Stack: detector.StackGo}}
+	gen := New(Options{Integrations: []Integration{}})
+
+	// An AGENTS.md from an earlier run must not be reported as legacy
+	// context, or every sync would see it as changed
+	os.WriteFile(filepath.Join(dir, "AGENTS.md"), []byte("# Generated"), 0644)
+	files, err := gen.RenderDerived(dir, results, false)
+	if err != nil {
+		t.Fatalf("RenderDerived() error = %v", err)
+	}
+	if strings.Contains(string(files[0].Content), "AGENTS_LEGACY.md") {
+		t.Error("AGENTS.md should not link a legacy file that does not exist")
+	}
+
+	os.MkdirAll(filepath.Join(dir, ".agent"), 0755)
+	os.WriteFile(filepath.Join(dir, ".agent", "AGENTS_LEGACY.md"), []byte("# Old"), 0644)
+	files, err = gen.RenderDerived(dir, results, false)
+	if err != nil {
+		t.Fatalf("RenderDerived() error = %v", err)
+	}
+	if !strings.Contains(string(files[0].Content), "AGENTS_LEGACY.md") {
+		t.Error("AGENTS.md should link the migrated legacy file")
+	}
+}
+
+func TestRender_MCPConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		integrations []Integration
+		want         []string
+	}{
+		{"claude and cursor", []Integration{IntegrationClaude, IntegrationCursor, IntegrationMCP}, []string{".mcp.json", ".cursor/mcp.json"}},
+		{"claude only", []Integration{IntegrationClaude, IntegrationMCP}, []string{".mcp.json"}},
+		{"no client", []Integration{IntegrationCopilot, IntegrationMCP}, nil},
+		{"mcp disabled", []Integration{IntegrationClaude, IntegrationCursor}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			results := []detector.Result{{Path: dir, Stack: detector.StackGo}}
+			files := renderMap(t, New(Options{Integrations: tt.integrations}), dir, results, false)
+
+			var got []string
+			for _, path := range []string{".mcp.json", ".cursor/mcp.json"} {
+				content, ok := files[path]
+				if !ok {
+					continue
+				}
+				got = append(got, path)
+
+				var cfg struct {
+					MCPServers map[string]struct {
+						Command string   `json:"command"`
+						Args    []string `json:"args"`
+					} `json:"mcpServers"`
+				}
+				if err := json.Unmarshal([]byte(content), &cfg); err != nil {
+					t.Fatalf("%s is not valid JSON: %v", path, err)
+				}
+				server := cfg.MCPServers["agentic-repo"]
+				if server.Command != "agentic-repo" || strings.Join(server.Args, " ") != "serve --mcp" {
+					t.Errorf("%s server = %+v", path, server)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("MCP configs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package hooks runs the shell commands a repository configures to run
+// before detection and after generation.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Stage is the point in a run at which hooks execute
+type Stage string
+
+const (
+	// PreDetect hooks run before the repository is scanned
+	PreDetect Stage = "pre_detect"
+	// PostGenerate hooks run after files have been written
+	PostGenerate Stage = "post_generate"
+)
+
+// Policy decides what a failing hook does to the run
+type Policy string
+
+const (
+	// Abort stops the run with an error; this is the default
+	Abort Policy = "abort"
+	// Warn prints a warning and continues
+	Warn Policy = "warn"
+	// Ignore continues silently
+	Ignore Policy = "ignore"
+)
+
+// DefaultTimeout bounds a hook that sets no timeout of its own
+const DefaultTimeout = 5 * time.Minute
+
+// Environment variables set for every hook
+const (
+	EnvRoot  = "AGENTIC_REPO_ROOT"
+	EnvStage = "AGENTIC_REPO_HOOK"
+	// EnvFiles lists the written files, one root-relative path per line.
+	// The same list is written to the hook's stdin.
+	EnvFiles = "AGENTIC_REPO_FILES"
+)
+
+// Hook is a shell command run at a stage
+type Hook struct {
+	// Run is passed to sh -c (cmd /C on Windows)
+	Run string
+	// OnFailure is Abort when empty
+	OnFailure Policy
+	// Timeout is DefaultTimeout when zero
+	Timeout time.Duration
+}
+
+// ParsePolicy validates a policy name; empty means Abort
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case "":
+		return Abort, nil
+	case Abort, Warn, Ignore:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown failure policy %q (want abort, warn or ignore)", s)
+	}
+}
+
+// Options describes the run the hooks belong to
+type Options struct {
+	// Root is the repository root; hooks run in it
+	Root string
+	// Files are root-relative paths written so far
+	Files []string
+	// Stdout and Stderr receive the hooks' output
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run executes hooks in order. A failing hook with the Abort policy stops
+// the run and its error is returned; Warn prints to opts.Stderr and moves
+// on to the next hook.
+func Run(ctx context.Context, stage Stage, hooks []Hook, opts Options) error {
+	for _, h := range hooks {
+		err := runOne(ctx, stage, h, opts)
+		if err == nil {
+			continue
+		}
+		switch h.OnFailure {
+		case Warn:
+			fmt.Fprintf(opts.Stderr, "⚠️  %s hook %q failed: %v\n", stage, h.Run, err)
+		case Ignore:
+		default:
+			return fmt.Errorf("%s hook %q failed: %w", stage, h.Run, err)
+		}
+	}
+	return nil
+}
+
+// runOne runs a single hook with its timeout
+func runOne(ctx context.Context, stage Stage, h Hook, opts Options) error {
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	files := strings.Join(opts.Files, "\n")
+	if files != "" {
+		files += "\n"
+	}
+
+	cmd := ShellCommand(ctx, h.Run)
+	cmd.Dir = opts.Root
+	cmd.Stdin = strings.NewReader(files)
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	// Children of the shell may keep its output open after a timeout
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(),
+		EnvRoot+"="+opts.Root,
+		EnvStage+"="+string(stage),
+		EnvFiles+"="+files,
+	)
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// ShellCommand runs line through the platform shell
+func ShellCommand(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", line)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", line)
+}
@@ -0,0 +1,128 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX shell syntax")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Policy
+		wantErr  bool
+	}{
+		{"", Abort, false},
+		{"abort", Abort, false},
+		{"warn", Warn, false},
+		{"ignore", Ignore, false},
+		{"retry", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePolicy(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParsePolicy(%q) = %q, %v; want %q, wantErr %v", tt.input, got, err, tt.expected, tt.wantErr)
+		}
+	}
+}
+
+func TestRun_PassesFilesAndEnvironment(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+
+	var stdout bytes.Buffer
+	hooks := []Hook{
+		{Run: `cat > stdin.txt; printf '%s' "$AGENTIC_REPO_FILES" > env.txt; echo "$AGENTIC_REPO_HOOK $AGENTIC_REPO_ROOT"`},
+	}
+	opts := Options{
+		Root:   dir,
+		Files:  []string{"AGENTS.md", filepath.Join(".agent", "stack.md")},
+		Stdout: &stdout,
+		Stderr: &bytes.Buffer{},
+	}
+
+	if err := Run(context.Background(), PostGenerate, hooks, opts); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := "AGENTS.md\n.agent/stack.md\n"
+	for _, name := range []string{"stdin.txt", "env.txt"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("hook did not run in the root: %v", err)
+		}
+		if string(content) != want {
+			t.Errorf("%s = %q, want %q", name, content, want)
+		}
+	}
+	if got := stdout.String(); got != "post_generate "+dir+"\n" {
+		t.Errorf("stdout = %q", got)
+	}
+}
+
+func TestRun_FailurePolicies(t *testing.T) {
+	skipOnWindows(t)
+
+	tests := []struct {
+		name      string
+		policy    Policy
+		wantErr   bool
+		wantWarn  bool
+		wantAfter bool
+	}{
+		{"abort stops the run", Abort, true, false, false},
+		{"default is abort", "", true, false, false},
+		{"warn continues", Warn, false, true, true},
+		{"ignore continues silently", Ignore, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var stderr bytes.Buffer
+			hooks := []Hook{
+				{Run: "exit 2", OnFailure: tt.policy},
+				{Run: "touch after"},
+			}
+
+			err := Run(context.Background(), PreDetect, hooks, Options{Root: dir, Stdout: &bytes.Buffer{}, Stderr: &stderr})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotWarn := strings.Contains(stderr.String(), "failed"); gotWarn != tt.wantWarn {
+				t.Errorf("warning printed = %v, want %v (%q)", gotWarn, tt.wantWarn, stderr.String())
+			}
+			_, statErr := os.Stat(filepath.Join(dir, "after"))
+			if ran := statErr == nil; ran != tt.wantAfter {
+				t.Errorf("next hook ran = %v, want %v", ran, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	skipOnWindows(t)
+
+	hooks := []Hook{{Run: "sleep 5", Timeout: 50 * time.Millisecond}}
+	start := time.Now()
+	err := Run(context.Background(), PreDetect, hooks, Options{Root: t.TempDir(), Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Run() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Run() took %s, want it to stop at the timeout", elapsed)
+	}
+}
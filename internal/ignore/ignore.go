@@ -0,0 +1,224 @@
+// Package ignore matches paths against gitignore-style rules, as found in
+// .gitignore and .agentignore files, and walks trees honouring them.
+package ignore
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// File names that are read as ignore files while walking a tree
+const (
+	GitIgnoreFile   = ".gitignore"
+	AgentIgnoreFile = ".agentignore"
+)
+
+// rule is a single compiled gitignore-style pattern
+type rule struct {
+	base    string // directory the rule is relative to ("" for root)
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// Matcher evaluates slash-separated paths, relative to the matcher root,
+// against an ordered list of gitignore-style rules. Later rules win.
+type Matcher struct {
+	rules []rule
+}
+
+// New creates a Matcher from pattern lines relative to the root
+func New(patterns ...string) *Matcher {
+	m := &Matcher{}
+	for _, p := range patterns {
+		m.add("", p)
+	}
+	return m
+}
+
+// Empty reports whether the matcher has no rules
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.rules) == 0
+}
+
+// Parse reads gitignore-style rules from r. base is the directory, relative
+// to the matcher root, that the rules apply to.
+func (m *Matcher) Parse(base string, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		m.add(base, scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// AddFile reads rules from the ignore file at path. Missing files are not
+// an error.
+func (m *Matcher) AddFile(base, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	return m.Parse(base, f)
+}
+
+// Child returns a copy of the matcher extended with the ignore files found
+// in dir, which is located at rel below the matcher root. The receiver is
+// not modified, so siblings can be walked independently.
+func (m *Matcher) Child(rel, dir string, names ...string) *Matcher {
+	child := &Matcher{}
+	if m != nil {
+		child.rules = append(child.rules, m.rules...)
+	}
+	for _, name := range names {
+		// Unreadable ignore files are skipped rather than failing the walk
+		_ = child.AddFile(rel, filepath.Join(dir, name))
+	}
+	return child
+}
+
+// Match reports whether rel (slash-separated, relative to the root) is
+// ignored. A path is also ignored when any of its parent directories is.
+func (m *Matcher) Match(rel string, isDir bool) bool {
+	if m.Empty() {
+		return false
+	}
+	rel = strings.Trim(rel, "/")
+	if rel == "" || rel == "." {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchOne(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matchOne(rel, isDir)
+}
+
+// matchOne applies the rules to a single path without checking parents
+func (m *Matcher) matchOne(rel string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		sub := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			sub = strings.TrimPrefix(rel, r.base+"/")
+		}
+		if r.re.MatchString(sub) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// add compiles a single pattern line and appends it
+func (m *Matcher) add(base, line string) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimRight(line, " \t")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	r := rule{base: strings.Trim(base, "/")}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return
+	}
+
+	// A slash anywhere but the end anchors the pattern to its base directory
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := globToRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return
+	}
+	r.re = re
+	m.rules = append(m.rules, r)
+}
+
+// MatchGlob reports whether name matches a gitignore-style glob. Unlike
+// path.Match it understands "**" and matches unanchored patterns against
+// any path suffix.
+func MatchGlob(pattern, name string) bool {
+	return New(pattern).matchOne(strings.Trim(name, "/"), true)
+}
+
+// globToRegexp converts a gitignore glob into an unanchored regexp body
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				atStart := i == 0 || glob[i-1] == '/'
+				atEnd := i+2 == len(glob)
+				followedBySlash := i+2 < len(glob) && glob[i+2] == '/'
+				switch {
+				case atStart && followedBySlash:
+					// "**/" matches zero or more directories
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				case atStart && atEnd:
+					b.WriteString(".*")
+					i++
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,132 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"plain name matches at root", []string{"vendor"}, "vendor", true, true},
+		{"plain name matches nested", []string{"vendor"}, "a/b/vendor", true, true},
+		{"plain name does not match prefix", []string{"vendor"}, "vendors", true, false},
+		{"dir-only pattern matches directory", []string{"build/"}, "build", true, true},
+		{"dir-only pattern skips files", []string{"build/"}, "build", false, false},
+		{"children of ignored dir are ignored", []string{"build/"}, "build/out/x.go", false, true},
+		{"leading slash anchors", []string{"/gen"}, "gen", true, true},
+		{"leading slash does not match nested", []string{"/gen"}, "pkg/gen", true, false},
+		{"middle slash anchors", []string{"sdk/generated"}, "sdk/generated", true, true},
+		{"middle slash does not match nested", []string{"sdk/generated"}, "x/sdk/generated", true, false},
+		{"star matches within segment", []string{"*.log"}, "logs/app.log", false, true},
+		{"star does not cross segments", []string{"a/*.go"}, "a/b/c.go", false, false},
+		{"double star prefix", []string{"**/fixtures"}, "x/y/fixtures", true, true},
+		{"double star middle", []string{"a/**/z"}, "a/b/c/z", true, true},
+		{"double star middle zero dirs", []string{"a/**/z"}, "a/z", true, true},
+		{"double star suffix", []string{"third_party/**"}, "third_party/lib/x.c", false, true},
+		{"question mark", []string{"file?.txt"}, "file1.txt", false, true},
+		{"character class", []string{"file[0-9].txt"}, "file7.txt", false, true},
+		{"negation re-includes", []string{"*.md", "!README.md"}, "README.md", false, false},
+		{"negation order matters", []string{"!README.md", "*.md"}, "README.md", false, true},
+		{"negation cannot re-include in ignored dir", []string{"docs/", "!docs/keep.md"}, "docs/keep.md", false, true},
+		{"comments and blanks ignored", []string{"# vendor", "", "  "}, "vendor", true, false},
+		{"escaped hash", []string{`\#notes`}, "#notes", false, true},
+		{"empty path never matches", []string{"*"}, "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.patterns...)
+			if got := m.Match(tt.path, tt.isDir); got != tt.expected {
+				t.Errorf("Match(%q, %v) with %v = %v, want %v", tt.path, tt.isDir, tt.patterns, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMatcher_NilAndEmpty(t *testing.T) {
+	var m *Matcher
+	if !m.Empty() {
+		t.Error("nil matcher should be empty")
+	}
+	if m.Match("anything", true) {
+		t.Error("nil matcher should not match")
+	}
+}
+
+func TestMatcher_ParseWithBase(t *testing.T) {
+	m := New()
+	if err := m.Parse("services/api", strings.NewReader("generated/\n/local.txt\n")); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"services/api/generated", true, true},
+		{"services/api/pkg/generated", true, true},
+		{"services/web/generated", true, false},
+		{"generated", true, false},
+		{"services/api/local.txt", false, true},
+		{"services/api/pkg/local.txt", false, false},
+	}
+
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.expected {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestMatcher_Child(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "pkg")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(root, GitIgnoreFile), []byte("*.tmp\n"), 0644)
+	os.WriteFile(filepath.Join(sub, AgentIgnoreFile), []byte("fixtures/\n"), 0644)
+
+	parent := New().Child("", root, GitIgnoreFile, AgentIgnoreFile)
+	child := parent.Child("pkg", sub, GitIgnoreFile, AgentIgnoreFile)
+
+	if !child.Match("pkg/a.tmp", false) {
+		t.Error("child should inherit parent rules")
+	}
+	if !child.Match("pkg/fixtures", true) {
+		t.Error("child should apply its own rules")
+	}
+	if parent.Match("pkg/fixtures", true) {
+		t.Error("parent should not be modified by Child")
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"services/*", "services/api", true},
+		{"services/*", "services/api/v2", false},
+		{"services/**", "services/api/v2", true},
+		{"api", "services/api", true},
+		{"apps/*/web", "apps/shop/web", true},
+		{"apps/*/web", "libs/shop/web", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.expected {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.expected)
+		}
+	}
+}
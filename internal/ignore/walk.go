@@ -0,0 +1,144 @@
+package ignore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Files are the ignore files read in every directory of a walk, in the
+// order their rules apply
+var Files = []string{GitIgnoreFile, AgentIgnoreFile}
+
+// MaxTextSize is the size above which a file is too large to be useful
+// context, e.g. generated code and data dumps
+const MaxTextSize = 256 << 10
+
+// binarySniff is how many leading bytes are checked for a NUL byte to
+// tell binary files from text
+const binarySniff = 8000
+
+// ErrNotText is returned by ReadText for binary files and files over
+// MaxTextSize
+var ErrNotText = errors.New("not a text file")
+
+// Load returns the matcher for the directory rel below root: the rules of
+// the ignore files in root and in every directory down to rel, inclusive
+func Load(root, rel string) *Matcher {
+	return load(root, rel, Files)
+}
+
+// load is Load reading the named ignore files
+func load(root, rel string, names []string) *Matcher {
+	rel = clean(rel)
+	m := New().Child("", root, names...)
+	if rel == "" {
+		return m
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i <= len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		m = m.Child(dir, filepath.Join(root, filepath.FromSlash(dir)), names...)
+	}
+	return m
+}
+
+// WalkFunc is called by Walk for each entry that is not ignored. rel is
+// the entry's slash-separated path relative to the walk root. Returning
+// fs.SkipDir for a directory skips its contents.
+type WalkFunc func(rel string, d fs.DirEntry) error
+
+// Walk calls fn for every file and directory below the directory rel of
+// root, in lexical order, that the .gitignore and .agentignore files of
+// root, of the directories down to rel and of the walked directories do
+// not exclude. .git directories are always skipped.
+func Walk(root, rel string, fn WalkFunc) error {
+	return WalkWith(root, rel, Files, fn)
+}
+
+// WalkWith is Walk reading only the named ignore files, e.g. just
+// .gitignore when deriving a new .agentignore
+func WalkWith(root, rel string, names []string, fn WalkFunc) error {
+	rel = clean(rel)
+	var parent *Matcher
+	if rel != "" {
+		parent = load(root, path.Dir(rel), names)
+	}
+	return walk(root, rel, names, parent, fn)
+}
+
+// walk visits the directory rel, with rules holding its ancestors' rules
+func walk(root, rel string, names []string, rules *Matcher, fn WalkFunc) error {
+	abs := filepath.Join(root, filepath.FromSlash(rel))
+	rules = rules.Child(rel, abs, names...)
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", abs, err)
+	}
+	for _, e := range entries {
+		child := path.Join(rel, e.Name())
+		if e.Name() == ".git" || rules.Match(child, e.IsDir()) {
+			continue
+		}
+		if err := fn(child, e); err != nil {
+			if e.IsDir() && errors.Is(err, fs.SkipDir) {
+				continue
+			}
+			return err
+		}
+		if e.IsDir() {
+			if err := walk(root, child, names, rules, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReadText reads a walked file for an agent's context. Files over
+// MaxTextSize, and binary files with a NUL byte among their first 8000
+// bytes, return ErrNotText without being read further.
+func ReadText(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > MaxTextSize {
+		return nil, ErrNotText
+	}
+
+	head := make([]byte, binarySniff)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	if bytes.IndexByte(head[:n], 0) >= 0 {
+		return nil, ErrNotText
+	}
+	// The file may have grown since Stat
+	rest, err := io.ReadAll(io.LimitReader(f, int64(MaxTextSize-n+1)))
+	if err != nil {
+		return nil, err
+	}
+	if n+len(rest) > MaxTextSize {
+		return nil, ErrNotText
+	}
+	return append(head[:n], rest...), nil
+}
+
+// clean turns a slash-separated relative directory into the matcher's
+// form, "" for the root
+func clean(rel string) string {
+	return strings.Trim(path.Clean("/"+filepath.ToSlash(rel)), "/")
+}
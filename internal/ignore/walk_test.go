@@ -0,0 +1,189 @@
+package ignore
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files relative to root
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":                "*.log\n/dist/\n",
+		".agentignore":              "fixtures/\n!keep.log\n",
+		".git/config":               "",
+		"main.go":                   "",
+		"debug.log":                 "",
+		"keep.log":                  "",
+		"dist/app.js":               "",
+		"api/dist/app.js":           "",
+		"api/.agentignore":          "/gen/\n**/mocks/**\n",
+		"api/gen/x.go":              "",
+		"api/pkg/gen/x.go":          "",
+		"api/pkg/mocks/a/m.go":      "",
+		"api/fixtures/data.json":    "",
+		"web/src/index.ts":          "",
+		"web/src/vendor/.gitignore": "*\n!.gitignore\n",
+		"web/src/vendor/lib.js":     "",
+	})
+
+	tests := []struct {
+		name string
+		rel  string
+		want []string
+	}{
+		{"root", "", []string{
+			".agentignore", ".gitignore", "api", "api/.agentignore", "api/dist", "api/dist/app.js",
+			"api/pkg", "api/pkg/gen", "api/pkg/gen/x.go", "api/pkg/mocks", "keep.log", "main.go",
+			"web", "web/src", "web/src/index.ts", "web/src/vendor", "web/src/vendor/.gitignore",
+		}},
+		{"subdirectory inherits ancestor rules", "api", []string{
+			"api/.agentignore", "api/dist", "api/dist/app.js", "api/pkg", "api/pkg/gen", "api/pkg/gen/x.go", "api/pkg/mocks",
+		}},
+		{"nested directory", "web/src", []string{
+			"web/src/index.ts", "web/src/vendor", "web/src/vendor/.gitignore",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := Walk(root, tt.rel, func(rel string, d fs.DirEntry) error {
+				got = append(got, rel)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Walk(%q) =\n%v\nwant\n%v", tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalk_SkipDir(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"a/x": "", "b/y": ""})
+
+	var got []string
+	err := Walk(root, ".", func(rel string, d fs.DirEntry) error {
+		got = append(got, rel)
+		if rel == "a" {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if want := []string{"a", "b", "b/y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+
+	if err := Walk(root, "missing", func(string, fs.DirEntry) error { return nil }); err == nil {
+		t.Error("Walk() of a missing directory should fail")
+	}
+}
+
+func TestWalkWith(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":   "*.log\n",
+		".agentignore": "gen/\n",
+		"a.log":        "",
+		"gen/x.go":     "",
+	})
+
+	var got []string
+	err := WalkWith(root, "", []string{GitIgnoreFile}, func(rel string, d fs.DirEntry) error {
+		got = append(got, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkWith() error = %v", err)
+	}
+	if want := []string{".agentignore", ".gitignore", "gen", "gen/x.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkWith() = %v, want %v", got, want)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".gitignore":       "*.tmp\n",
+		"a/.agentignore":   "secret/\n",
+		"a/b/.gitignore":   "!keep.tmp\n",
+		"c/.agentignore":   "*.go\n",
+		"a/b/placeholder":  "",
+		"c/placeholder.go": "",
+	})
+
+	m := Load(root, "a/b")
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"a/b/x.tmp", false, true},
+		{"a/b/keep.tmp", false, false},
+		{"a/b/secret", true, true},
+		{"a/b/x.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("Load(a/b).Match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReadText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{"text", "package main\n", nil},
+		{"empty", "", nil},
+		{"binary", "\x89PNG\x00\x00", ErrNotText},
+		{"nul past the sniffed bytes", strings.Repeat("x", binarySniff) + "\x00", nil},
+		{"at the size cap", strings.Repeat("x", MaxTextSize), nil},
+		{"over the size cap", strings.Repeat("x", MaxTextSize+1), ErrNotText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadText(path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadText() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != tt.content {
+				t.Errorf("ReadText() = %d bytes, want %d", len(got), len(tt.content))
+			}
+		})
+	}
+
+	if _, err := ReadText(filepath.Join(t.TempDir(), "missing")); err == nil || errors.Is(err, ErrNotText) {
+		t.Errorf("ReadText() of a missing file error = %v, want a read error", err)
+	}
+}
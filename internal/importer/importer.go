@@ -0,0 +1,336 @@
+// Package importer moves the content of hand-written AI rule files such as
+// CLAUDE.md or .cursorrules into the .agent/ context files. Sections are
+// classified by their headings; anything that fits no context file is
+// kept in .agent/AGENTS_LEGACY.md.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Shaked/agentic-repo/internal/manifest"
+)
+
+// Sources lists the files that can be imported, relative to the root, in
+// import order
+var Sources = []string{
+	".cursorrules",
+	"CLAUDE.md",
+	".github/copilot-instructions.md",
+	"CONVENTIONS.md",
+}
+
+// LegacyFile keeps the sections that fit no context file
+const LegacyFile = ".agent/AGENTS_LEGACY.md"
+
+// Category is the kind of context a section holds
+type Category string
+
+const (
+	Commands     Category = "commands"
+	Testing      Category = "testing"
+	Style        Category = "style"
+	Architecture Category = "architecture"
+	// Unclassified sections go to LegacyFile
+	Unclassified Category = ""
+)
+
+// category describes where a category is merged and how it is recognised
+type category struct {
+	name Category
+	// file is relative to the root
+	file  string
+	title string
+	// keywords are matched against lowercase headings
+	keywords []string
+}
+
+// categories are checked in order; the first with a matching keyword wins
+var categories = []category{
+	{Testing, ".agent/testing.md", "Testing", []string{"test", "coverage", "fixture", "mock", "tdd", "qa"}},
+	{Commands, ".agent/commands.md", "Commands", []string{"command", "build", "run", "script", "setup", "install", "make", "cli", "workflow", "deploy"}},
+	{Style, ".agent/stack.md", "Stack", []string{"style", "convention", "lint", "format", "naming", "guideline", "best practice", "code quality"}},
+	{Architecture, ".agent/architecture.md", "Architecture", []string{"architecture", "structure", "layout", "design", "overview", "module", "component", "director"}},
+}
+
+// Classify returns the category a heading belongs to. A keyword matches
+// the start of a word, so "test" matches "Tests" but "run" does not
+// match "Truncation"; keywords with spaces match anywhere.
+func Classify(heading string) Category {
+	heading = strings.ToLower(heading)
+	words := strings.FieldsFunc(heading, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, c := range categories {
+		for _, keyword := range c.keywords {
+			if strings.Contains(keyword, " ") {
+				if strings.Contains(heading, keyword) {
+					return c.name
+				}
+				continue
+			}
+			for _, word := range words {
+				if strings.HasPrefix(word, keyword) {
+					return c.name
+				}
+			}
+		}
+	}
+	return Unclassified
+}
+
+// Target returns the file a category is merged into, relative to the root
+func Target(c Category) string {
+	for _, cat := range categories {
+		if cat.name == c {
+			return cat.file
+		}
+	}
+	return LegacyFile
+}
+
+// title returns the heading of a newly created target file
+func title(c Category) string {
+	for _, cat := range categories {
+		if cat.name == c {
+			return cat.title
+		}
+	}
+	return "Legacy Agent Context"
+}
+
+// Section is the content under one Markdown heading, up to the next
+// heading of any level
+type Section struct {
+	// Source is the file the section came from, relative to the root
+	Source string
+	Title  string
+	// Level is the heading level, 0 for text before the first heading
+	Level int
+	Body  string
+	// Category is classified from Title or, failing that, inherited from
+	// the closest enclosing heading
+	Category Category
+}
+
+// Document is a parsed source file
+type Document struct {
+	// Source is relative to the root
+	Source   string
+	Sections []Section
+}
+
+// Parse splits Markdown into sections at its ATX headings. Headings
+// inside fenced code blocks are content.
+func Parse(source string, content []byte) Document {
+	doc := Document{Source: source}
+	current := Section{Source: source}
+	var body []string
+	// parents holds the category of the enclosing heading at each level
+	var parents [7]Category
+	fence := ""
+
+	flush := func() {
+		current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+		if current.Body != "" {
+			doc.Sections = append(doc.Sections, current)
+		}
+		body = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			body = append(body, line)
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			body = append(body, line)
+			continue
+		}
+
+		level, heading, ok := parseHeading(line)
+		if !ok {
+			body = append(body, line)
+			continue
+		}
+
+		flush()
+		c := Classify(heading)
+		if c == Unclassified {
+			for l := level - 1; l > 0; l-- {
+				if parents[l] != Unclassified {
+					c = parents[l]
+					break
+				}
+			}
+		}
+		parents[level] = c
+		for l := level + 1; l < len(parents); l++ {
+			parents[l] = Unclassified
+		}
+		current = Section{Source: source, Title: heading, Level: level, Category: c}
+	}
+	flush()
+	return doc
+}
+
+// parseHeading recognises an ATX heading such as "## Testing"
+func parseHeading(line string) (int, string, bool) {
+	if len(line) < 2 || line[0] != '#' {
+		return 0, "", false
+	}
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level > 6 || level == len(line) || (line[level] != ' ' && line[level] != '\t') {
+		return 0, "", false
+	}
+	heading := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	return level, heading, heading != ""
+}
+
+// Read parses every source file in root. Files recorded in the manifest
+// are skipped: agentic-repo derived them from .agent/, and hand edits to
+// them are pulled back by sync instead.
+func Read(root string) ([]Document, error) {
+	m, err := manifest.Load(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []Document
+	for _, source := range Sources {
+		if _, ok := m.Files[source]; ok {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(source)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		docs = append(docs, Parse(source, content))
+	}
+	return docs, nil
+}
+
+// Change is the content imported into one target file
+type Change struct {
+	// Target is relative to the root
+	Target   string
+	Sections []Section
+	// Sources lists the files the sections came from
+	Sources []string
+}
+
+// Plan groups the sections of docs by target file, in category order,
+// leaving out sections whose content the target already has
+func Plan(root string, docs []Document) ([]Change, error) {
+	var changes []Change
+	for _, c := range append(categories, category{name: Unclassified}) {
+		target := Target(c.name)
+		existing, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(target)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", target, err)
+		}
+
+		change := Change{Target: target}
+		seen := map[string]bool{}
+		for _, doc := range docs {
+			imported := false
+			for _, s := range doc.Sections {
+				if s.Category != c.name || seen[s.Body] || strings.Contains(string(existing), s.Body) {
+					continue
+				}
+				seen[s.Body] = true
+				change.Sections = append(change.Sections, s)
+				imported = true
+			}
+			if imported {
+				change.Sources = append(change.Sources, doc.Source)
+			}
+		}
+		if len(change.Sections) > 0 {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// Apply appends each change to its target file, creating it if needed
+func Apply(root string, changes []Change) error {
+	for _, change := range changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Target))
+		existing, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", change.Target, err)
+		}
+
+		content := strings.TrimRight(string(existing), "\n")
+		if content == "" {
+			content = "# " + title(categoryOf(change.Target))
+		}
+		content += "\n" + render(change)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", change.Target, err)
+		}
+	}
+	return nil
+}
+
+// categoryOf returns the category merged into target
+func categoryOf(target string) Category {
+	for _, c := range categories {
+		if c.file == target {
+			return c.name
+		}
+	}
+	return Unclassified
+}
+
+// render formats a change as one "Imported from" section per source.
+// Headings are shifted so the shallowest one in a source becomes a
+// level 3 heading under the level 2 "Imported from" heading.
+func render(change Change) string {
+	var b strings.Builder
+	for _, source := range change.Sources {
+		var sections []Section
+		for _, s := range change.Sections {
+			if s.Source == source {
+				sections = append(sections, s)
+			}
+		}
+
+		shallowest := 6
+		for _, s := range sections {
+			if s.Level > 0 && s.Level < shallowest {
+				shallowest = s.Level
+			}
+		}
+
+		fmt.Fprintf(&b, "\n## Imported from %s\n", source)
+		for _, s := range sections {
+			if s.Level > 0 {
+				level := min(s.Level-shallowest+3, 6)
+				fmt.Fprintf(&b, "\n%s %s\n", strings.Repeat("#", level), s.Title)
+			}
+			fmt.Fprintf(&b, "\n%s\n", s.Body)
+		}
+	}
+	return b.String()
+}
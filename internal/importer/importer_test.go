@@ -0,0 +1,163 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/manifest"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		heading  string
+		expected Category
+	}{
+		{"Testing", Testing},
+		{"How to run tests", Testing},
+		{"Unit Tests", Testing},
+		{"Build & Run", Commands},
+		{"Useful commands", Commands},
+		{"Setup", Commands},
+		{"Code Style", Style},
+		{"Naming conventions", Style},
+		{"Linting", Style},
+		{"Best practices", Style},
+		{"Architecture", Architecture},
+		{"Directory layout", Architecture},
+		{"Project structure", Architecture},
+		{"Truncation", Unclassified},
+		{"Security", Unclassified},
+		{"Cursor Rules", Unclassified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.heading, func(t *testing.T) {
+			if got := Classify(tt.heading); got != tt.expected {
+				t.Errorf("Classify(%q) = %q, want %q", tt.heading, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	content := "Intro text\n\n" +
+		"# Project\n\n" +
+		"## Testing\n\nUse table-driven tests.\n\n" +
+		"### Fixtures\n\nFixtures live in testdata/.\n\n" +
+		"## Commands\n\n```bash\nmake run\n# not a heading\n```\n\n" +
+		"## Empty\n\n" +
+		"#hashtag is text\n"
+
+	doc := Parse("CLAUDE.md", []byte(content))
+
+	type section struct {
+		title    string
+		level    int
+		category Category
+	}
+	var got []section
+	for _, s := range doc.Sections {
+		got = append(got, section{s.Title, s.Level, s.Category})
+		if s.Source != "CLAUDE.md" {
+			t.Errorf("section %q Source = %q", s.Title, s.Source)
+		}
+	}
+	want := []section{
+		{"", 0, Unclassified},
+		{"Testing", 2, Testing},
+		{"Fixtures", 3, Testing},
+		{"Commands", 2, Commands},
+		{"Empty", 2, Unclassified},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() sections = %+v, want %+v", got, want)
+	}
+
+	if body := doc.Sections[3].Body; !strings.Contains(body, "# not a heading") {
+		t.Errorf("fenced code should stay in the section body, got %q", body)
+	}
+	if body := doc.Sections[4].Body; body != "#hashtag is text" {
+		t.Errorf("Empty section body = %q", body)
+	}
+}
+
+func TestRead_SkipsDerivedFiles(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "CLAUDE.md"), []byte("## Testing\n\nGenerated.\n"), 0644)
+	os.WriteFile(filepath.Join(root, "CONVENTIONS.md"), []byte("## Style\n\nTabs.\n"), 0644)
+
+	m := manifest.New()
+	m.Record("CLAUDE.md", []byte("anything"))
+	if err := m.Save(root); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := Read(root)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(docs) != 1 || docs[0].Source != "CONVENTIONS.md" {
+		t.Errorf("Read() = %+v, want only CONVENTIONS.md", docs)
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, ".agent"), 0755)
+	os.WriteFile(filepath.Join(root, ".agent", "testing.md"), []byte("# Testing\n\nAlready documented.\n"), 0644)
+
+	docs := []Document{
+		Parse("CLAUDE.md", []byte("# Notes\n\nShip weekly.\n\n## Testing\n\nAlready documented.\n\n### Mocks\n\nUse fakes.\n")),
+		Parse(".cursorrules", []byte("## Testing\n\nUse fakes.\n\n## Commands\n\nmake run\n")),
+	}
+
+	changes, err := Plan(root, docs)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	var targets []string
+	for _, c := range changes {
+		targets = append(targets, c.Target)
+	}
+	wantTargets := []string{".agent/testing.md", ".agent/commands.md", LegacyFile}
+	if !reflect.DeepEqual(targets, wantTargets) {
+		t.Fatalf("Plan() targets = %v, want %v", targets, wantTargets)
+	}
+	if n := len(changes[0].Sections); n != 1 {
+		t.Errorf("testing.md should get only the new section, got %d", n)
+	}
+
+	if err := Apply(root, changes); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{".agent/testing.md", "# Testing\n\nAlready documented.\n\n## Imported from CLAUDE.md\n\n### Mocks\n\nUse fakes.\n"},
+		{".agent/commands.md", "# Commands\n\n## Imported from .cursorrules\n\n### Commands\n\nmake run\n"},
+		{LegacyFile, "# Legacy Agent Context\n\n## Imported from CLAUDE.md\n\n### Notes\n\nShip weekly.\n"},
+	}
+	for _, tt := range tests {
+		got, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(tt.file)))
+		if err != nil {
+			t.Fatalf("reading %s: %v", tt.file, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s =\n%s\nwant\n%s", tt.file, got, tt.want)
+		}
+	}
+
+	// A second import finds nothing new
+	changes, err = Plan(root, docs)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("second Plan() = %+v, want no changes", changes)
+	}
+}
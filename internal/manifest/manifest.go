@@ -0,0 +1,152 @@
+// Package manifest records the content of the files agentic-repo derives
+// from the .agent/ context, so that later runs can tell files that are
+// merely out of date from files that were edited by hand.
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Path is the manifest's location relative to the repository root
+const Path = ".agent/manifest.json"
+
+// Version is the manifest format version
+const Version = 1
+
+// Manifest maps derived files to the hash of the content last written
+type Manifest struct {
+	Version int `json:"version"`
+	// Files maps slash-separated, root-relative paths to content hashes
+	Files map[string]string `json:"files"`
+}
+
+// State is how a derived file on disk compares to its fresh rendering
+type State string
+
+const (
+	// Missing files do not exist yet
+	Missing State = "missing"
+	// UpToDate files already have the rendered content
+	UpToDate State = "up-to-date"
+	// Stale files are unchanged since they were last written and can be
+	// regenerated safely
+	Stale State = "stale"
+	// Edited files differ from what was last written, or were never
+	// written by agentic-repo at all
+	Edited State = "edited"
+)
+
+// New returns an empty manifest
+func New() *Manifest {
+	return &Manifest{Version: Version, Files: map[string]string{}}
+}
+
+// Load reads the manifest of the repository at root. A missing manifest
+// is empty.
+func Load(root string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(Path)))
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", Path, err)
+	}
+
+	m := New()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", Path, err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("unsupported %s version %d (want %d)", Path, m.Version, Version)
+	}
+	if m.Files == nil {
+		m.Files = map[string]string{}
+	}
+	return m, nil
+}
+
+// Save writes the manifest to the repository at root
+func (m *Manifest) Save(root string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", Path, err)
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(Path))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", Path, err)
+	}
+	return nil
+}
+
+// Record stores the hash of content written to rel
+func (m *Manifest) Record(rel string, content []byte) {
+	m.Files[filepath.ToSlash(rel)] = Hash(content)
+}
+
+// Forget removes rel from the manifest
+func (m *Manifest) Forget(rel string) {
+	delete(m.Files, filepath.ToSlash(rel))
+}
+
+// Paths returns the recorded paths in sorted order
+func (m *Manifest) Paths() []string {
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// State compares the content on disk at rel with its fresh rendering.
+// onDisk is nil when the file does not exist.
+func (m *Manifest) State(rel string, onDisk, rendered []byte) State {
+	switch {
+	case onDisk == nil:
+		return Missing
+	case string(onDisk) == string(rendered):
+		return UpToDate
+	case m.Files[filepath.ToSlash(rel)] == Hash(onDisk):
+		return Stale
+	default:
+		return Edited
+	}
+}
+
+// Hash returns the content hash stored in the manifest
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// AddedLines returns the non-blank lines of an edited file that its
+// fresh rendering does not contain, in file order. These are the hand
+// edits that can be pulled back into the canonical context.
+func AddedLines(edited, rendered []byte) []string {
+	known := map[string]bool{}
+	for _, line := range strings.Split(string(rendered), "\n") {
+		known[strings.TrimSpace(line)] = true
+	}
+
+	var added []string
+	for _, line := range strings.Split(string(edited), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" || known[strings.TrimSpace(line)] {
+			continue
+		}
+		added = append(added, line)
+	}
+	return added
+}
@@ -0,0 +1,126 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadSave(t *testing.T) {
+	root := t.TempDir()
+
+	m, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() on empty repo error = %v", err)
+	}
+	if len(m.Files) != 0 {
+		t.Fatalf("Load() on empty repo = %v, want no files", m.Files)
+	}
+
+	m.Record("CLAUDE.md", []byte("a"))
+	m.Record(filepath.Join(".cursor", "rules", "project.mdc"), []byte("b"))
+	if err := m.Save(root); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []string{".cursor/rules/project.mdc", "CLAUDE.md"}
+	if got := loaded.Paths(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Paths() = %v, want %v", got, want)
+	}
+	if loaded.Files["CLAUDE.md"] != Hash([]byte("a")) {
+		t.Errorf("CLAUDE.md hash = %q, want %q", loaded.Files["CLAUDE.md"], Hash([]byte("a")))
+	}
+
+	loaded.Forget("CLAUDE.md")
+	if _, ok := loaded.Files["CLAUDE.md"]; ok {
+		t.Error("Forget() kept CLAUDE.md")
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"malformed", "{", "failed to parse"},
+		{"unsupported version", `{"version": 2, "files": {}}`, "unsupported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			path := filepath.Join(root, filepath.FromSlash(Path))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Load(root)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestState(t *testing.T) {
+	m := New()
+	m.Record("AGENTS.md", []byte("old"))
+
+	tests := []struct {
+		name     string
+		rel      string
+		onDisk   []byte
+		rendered []byte
+		expected State
+	}{
+		{"missing", "AGENTS.md", nil, []byte("new"), Missing},
+		{"up to date", "AGENTS.md", []byte("new"), []byte("new"), UpToDate},
+		{"unchanged since written", "AGENTS.md", []byte("old"), []byte("new"), Stale},
+		{"edited by hand", "AGENTS.md", []byte("old plus edits"), []byte("new"), Edited},
+		{"never written", "CLAUDE.md", []byte("mine"), []byte("new"), Edited},
+		{"never written but identical", "CLAUDE.md", []byte("new"), []byte("new"), UpToDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.State(tt.rel, tt.onDisk, tt.rendered); got != tt.expected {
+				t.Errorf("State() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAddedLines(t *testing.T) {
+	rendered := "# Rules\n\n- Run the linter\n- Write tests\n"
+
+	tests := []struct {
+		name     string
+		edited   string
+		expected []string
+	}{
+		{"unchanged", rendered, nil},
+		{"line added", "# Rules\n\n- Run the linter\n- Never push to main\n- Write tests\n", []string{"- Never push to main"}},
+		{"line removed", "# Rules\n\n- Run the linter\n", nil},
+		{"whitespace only changes", "# Rules\n\n  - Run the linter  \n- Write tests\n\n\n", nil},
+		{"crlf line endings", "# Rules\r\n- Use tabs\r\n", []string{"- Use tabs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AddedLines([]byte(tt.edited), []byte(rendered))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("AddedLines() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// resource is an MCP resource descriptor
+type resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType"`
+}
+
+// resources lists the context files of the repository root and of every
+// detected project: AGENTS.md and the Markdown files in .agent/
+func (s *Server) resources() []resource {
+	type contextDir struct {
+		path, label string
+	}
+	dirs := []contextDir{{s.root, "repository"}}
+	for _, r := range s.results {
+		if r.Path != s.root {
+			dirs = append(dirs, contextDir{r.Path, fmt.Sprintf("%s project %s", r.Stack, s.rel(r.Path))})
+		} else {
+			dirs[0].label = fmt.Sprintf("%s project", r.Stack)
+		}
+	}
+
+	var list []resource
+	for _, dir := range dirs {
+		paths := []string{filepath.Join(dir.path, "AGENTS.md")}
+		agent, _ := filepath.Glob(filepath.Join(dir.path, ".agent", "*.md"))
+		sort.Strings(agent)
+		paths = append(paths, agent...)
+
+		for _, path := range paths {
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue
+			}
+			list = append(list, resource{
+				URI:         fileURI(path),
+				Name:        s.rel(path),
+				Description: fmt.Sprintf("Agent context of the %s", dir.label),
+				MimeType:    "text/markdown",
+			})
+		}
+	}
+	return list
+}
+
+// listResources answers resources/list
+func (s *Server) listResources() (any, error) {
+	list := s.resources()
+	if list == nil {
+		list = []resource{}
+	}
+	return map[string]any{"resources": list}, nil
+}
+
+// readResource answers resources/read. Only listed resources can be
+// read, so clients cannot reach other files through the server.
+func (s *Server) readResource(params json.RawMessage) (any, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid resources/read params: %w", err)
+	}
+
+	for _, res := range s.resources() {
+		if res.URI != p.URI {
+			continue
+		}
+		path, err := pathFromURI(res.URI)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", res.Name, err)
+		}
+		return map[string]any{"contents": []map[string]string{{
+			"uri":      res.URI,
+			"mimeType": res.MimeType,
+			"text":     string(content),
+		}}}, nil
+	}
+	return nil, fmt.Errorf("unknown resource: %s", p.URI)
+}
+
+// rel returns path relative to the root in slash form, "." for the root
+func (s *Server) rel(path string) string {
+	rel, err := filepath.Rel(s.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// fileURI returns the file:// URI of an absolute path
+func fileURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		// Windows drive paths, e.g. C:/repo
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+// pathFromURI is the inverse of fileURI
+func pathFromURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("not a file URI: %s", uri)
+	}
+	path := u.Path
+	if len(path) > 2 && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
@@ -0,0 +1,263 @@
+// Package mcp serves a repository's agent context over the Model Context
+// Protocol. The server speaks newline-delimited JSON-RPC 2.0 on stdio and
+// implements the resources and tools parts of the protocol.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// ProtocolVersion is the MCP revision answered when a client asks for one
+// the server does not know
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions are the MCP revisions the server can speak; it only
+// uses features they share
+var supportedVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+// ServerName identifies the server to clients
+const ServerName = "agentic-repo"
+
+// DefaultTestTimeout bounds the run_tests tool
+const DefaultTestTimeout = 10 * time.Minute
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Options configures a Server
+type Options struct {
+	// Version is reported to clients
+	Version string
+	// TestTimeout bounds run_tests; DefaultTestTimeout when zero
+	TestTimeout time.Duration
+}
+
+// Server answers MCP requests about one scanned repository
+type Server struct {
+	root    string
+	results []detector.Result
+	opts    Options
+}
+
+// NewServer creates a server for the repository at root
+func NewServer(root string, results []detector.Result, opts Options) *Server {
+	if opts.TestTimeout == 0 {
+		opts.TestTimeout = DefaultTestTimeout
+	}
+	return &Server{root: root, results: results, opts: opts}
+}
+
+// request is a JSON-RPC request, or a notification when ID is empty
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Serve answers the messages read from r on w, one JSON object per line,
+// until r is exhausted or ctx is cancelled. Tool calls run concurrently, so
+// a long run_tests does not hold up other requests; Serve waits for them
+// before returning.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	in := bufio.NewReader(r)
+	out := &responseWriter{enc: json.NewEncoder(w)}
+	out.enc.SetEscapeHTML(false)
+	var calls sync.WaitGroup
+	defer calls.Wait()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := in.ReadBytes('\n')
+		if len(line) > 0 {
+			req, resp := parse(line)
+			switch {
+			case resp != nil:
+				out.write(resp)
+			case req.Method == "tools/call":
+				calls.Add(1)
+				go func() {
+					defer calls.Done()
+					out.write(s.handle(ctx, req))
+				}()
+			case req.Method != "":
+				out.write(s.handle(ctx, req))
+			}
+			if err := out.failed(); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			calls.Wait()
+			return out.failed()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
+
+// responseWriter writes responses from concurrent requests one at a time
+// and keeps the first write error
+type responseWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+// write encodes resp unless it is nil or an earlier write failed
+func (w *responseWriter) write(resp *response) {
+	if resp == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return
+	}
+	if err := w.enc.Encode(resp); err != nil {
+		w.err = fmt.Errorf("failed to write response: %w", err)
+	}
+}
+
+// failed returns the first write error
+func (w *responseWriter) failed() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// parse decodes one message. Malformed messages get an error response,
+// and blank lines and invalid notifications a request without a method.
+func parse(line []byte) (request, *response) {
+	if isBlank(line) {
+		return request{}, nil
+	}
+
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return request{}, &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error: " + err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if len(req.ID) == 0 {
+			return request{}, nil
+		}
+		return request{}, &response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{codeInvalidRequest, "invalid request"}}
+	}
+	return req, nil
+}
+
+// handle answers one request; notifications get no response
+func (s *Server) handle(ctx context.Context, req request) *response {
+	result, err := s.dispatch(ctx, req)
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	resp := &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	if err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &rpcError{codeInvalidParams, err.Error()}
+		}
+		resp.Result, resp.Error = nil, rpcErr
+	}
+	return resp
+}
+
+// dispatch routes a request to its method
+func (s *Server) dispatch(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "resources/list":
+		return s.listResources()
+	case "resources/read":
+		return s.readResource(req.Params)
+	case "tools/list":
+		return map[string]any{"tools": toolDefinitions}, nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	}
+	if len(req.ID) == 0 {
+		// Unknown notifications, e.g. notifications/initialized, need no action
+		return nil, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+}
+
+// initialize negotiates the protocol version and announces capabilities
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("invalid initialize params: %w", err)
+		}
+	}
+
+	version := ProtocolVersion
+	for _, v := range supportedVersions {
+		if v == p.ProtocolVersion {
+			version = v
+		}
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"resources": map[string]any{},
+			"tools":     map[string]any{},
+		},
+		"serverInfo": map[string]string{"name": ServerName, "version": s.opts.Version},
+		"instructions": "Repository context for AI agents. Read the AGENTS.md resource first, " +
+			"then the .agent/ files of the project you work in.",
+	}, nil
+}
+
+// isBlank reports whether line holds only whitespace
+func isBlank(line []byte) bool {
+	for _, c := range line {
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return false
+		}
+	}
+	return true
+}
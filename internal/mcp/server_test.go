@@ -0,0 +1,305 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// message is a decoded server response
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// session sends each request line to a server and returns the responses
+func session(t *testing.T, s *Server, lines ...string) []message {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(context.Background(), strings.NewReader(strings.Join(lines, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	var msgs []message
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var m message
+		if err := dec.Decode(&m); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
+
+// call sends one request and decodes its result into v
+func call(t *testing.T, s *Server, method, params string, v any) {
+	t.Helper()
+	msgs := session(t, s, `{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":`+params+`}`)
+	if len(msgs) != 1 {
+		t.Fatalf("%s: got %d responses, want 1", method, len(msgs))
+	}
+	if msgs[0].Error != nil {
+		t.Fatalf("%s: error %+v", method, msgs[0].Error)
+	}
+	if err := json.Unmarshal(msgs[0].Result, v); err != nil {
+		t.Fatalf("%s: invalid result %s: %v", method, msgs[0].Result, err)
+	}
+}
+
+// newMonorepo creates a repository with two projects and their context
+func newMonorepo(t *testing.T) (string, *Server) {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"AGENTS.md":               "# Root router",
+		".agent/overview.md":      "# Overview",
+		".agent/manifest.json":    "{}",
+		"api/AGENTS.md":           "# API",
+		"api/.agent/commands.md":  "# API commands\n\nmake api",
+		"web/package.json":        `{"scripts": {"test": "vitest run", "lint": "eslint ."}}`,
+		"web/yarn.lock":           "",
+		"web/.agent/testing.md":   "# Web testing",
+		"web/src/app/index.ts":    "",
+		"secrets/credentials.txt": "hunter2",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		os.MkdirAll(filepath.Dir(full), 0755)
+		os.WriteFile(full, []byte(content), 0644)
+	}
+
+	results := []detector.Result{
+		{Path: filepath.Join(root, "api"), Stack: detector.StackGo, Confidence: 1, Evidence: []string{"go.mod"}},
+		{Path: filepath.Join(root, "web"), Stack: detector.StackNode, Confidence: 0.9, Tools: map[string]string{"package_manager": "yarn"}},
+	}
+	return root, NewServer(root, results, Options{Version: "test"})
+}
+
+func TestServe_Lifecycle(t *testing.T) {
+	_, s := newMonorepo(t)
+
+	msgs := session(t, s,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":"two","method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"prompts/list"}`,
+		`not json`,
+		`{"jsonrpc":"1.0","id":4,"method":"ping"}`,
+	)
+
+	if len(msgs) != 5 {
+		t.Fatalf("got %d responses, want 5 (notifications and blank lines get none)", len(msgs))
+	}
+
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		Capabilities    map[string]any
+		ServerInfo      struct{ Name, Version string }
+	}
+	json.Unmarshal(msgs[0].Result, &init)
+	if init.ProtocolVersion != "2024-11-05" {
+		t.Errorf("protocolVersion = %q, want the client's 2024-11-05", init.ProtocolVersion)
+	}
+	if _, ok := init.Capabilities["tools"]; !ok {
+		t.Error("tools capability missing")
+	}
+	if init.ServerInfo.Name != ServerName || init.ServerInfo.Version != "test" {
+		t.Errorf("serverInfo = %+v", init.ServerInfo)
+	}
+
+	if string(msgs[1].ID) != `"two"` || string(msgs[1].Result) != "{}" {
+		t.Errorf("ping = id %s result %s", msgs[1].ID, msgs[1].Result)
+	}
+
+	wantCodes := []int{codeMethodNotFound, codeParseError, codeInvalidRequest}
+	for i, code := range wantCodes {
+		if msg := msgs[i+2]; msg.Error == nil || msg.Error.Code != code {
+			t.Errorf("response %d error = %+v, want code %d", i+2, msg.Error, code)
+		}
+	}
+}
+
+func TestInitialize_UnknownVersion(t *testing.T) {
+	_, s := newMonorepo(t)
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	call(t, s, "initialize", `{"protocolVersion":"1999-01-01"}`, &result)
+	if result.ProtocolVersion != ProtocolVersion {
+		t.Errorf("protocolVersion = %q, want %q", result.ProtocolVersion, ProtocolVersion)
+	}
+}
+
+func TestResources(t *testing.T) {
+	root, s := newMonorepo(t)
+
+	var list struct{ Resources []resource }
+	call(t, s, "resources/list", `{}`, &list)
+
+	var names []string
+	for _, r := range list.Resources {
+		names = append(names, r.Name)
+	}
+	want := []string{"AGENTS.md", ".agent/overview.md", "api/AGENTS.md", "api/.agent/commands.md", "web/.agent/testing.md"}
+	if strings.Join(names, " ") != strings.Join(want, " ") {
+		t.Fatalf("resources = %v, want %v", names, want)
+	}
+
+	var read struct {
+		Contents []struct{ URI, MimeType, Text string }
+	}
+	params, _ := json.Marshal(map[string]string{"uri": list.Resources[3].URI})
+	call(t, s, "resources/read", string(params), &read)
+	if len(read.Contents) != 1 || !strings.Contains(read.Contents[0].Text, "make api") {
+		t.Errorf("resources/read = %+v", read.Contents)
+	}
+
+	// Files that are not listed cannot be read
+	params, _ = json.Marshal(map[string]string{"uri": fileURI(filepath.Join(root, "secrets", "credentials.txt"))})
+	msgs := session(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":`+string(params)+`}`)
+	if msgs[0].Error == nil || msgs[0].Error.Code != codeInvalidParams {
+		t.Errorf("reading an unlisted file = %+v, want invalid params", msgs[0])
+	}
+}
+
+// toolCall calls a tool and returns its text and error flag
+func toolCall(t *testing.T, s *Server, name string, args map[string]string) (string, bool) {
+	t.Helper()
+	params, _ := json.Marshal(map[string]any{"name": name, "arguments": args})
+	var result toolResult
+	call(t, s, "tools/call", string(params), &result)
+	if len(result.Content) != 1 || result.Content[0].Type != "text" {
+		t.Fatalf("%s content = %+v", name, result.Content)
+	}
+	return result.Content[0].Text, result.IsError
+}
+
+func TestTools(t *testing.T) {
+	_, s := newMonorepo(t)
+
+	var list struct{ Tools []tool }
+	call(t, s, "tools/list", `{}`, &list)
+	if len(list.Tools) != len(toolDefinitions) {
+		t.Errorf("tools/list = %d tools, want %d", len(list.Tools), len(toolDefinitions))
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"owner of nested file", "project_for_path", map[string]string{"path": "web/src/app/index.ts"}, `"path": "web"`, false},
+		{"owner reports tooling", "project_for_path", map[string]string{"path": "web"}, `"package_manager": "yarn"`, false},
+		{"path without project", "project_for_path", map[string]string{"path": "secrets"}, "no detected project", true},
+		{"path outside repository", "project_for_path", map[string]string{"path": "../elsewhere"}, "outside the repository", true},
+		{"path is required", "project_for_path", nil, "path is required", true},
+		{"commands with cheat sheet", "list_commands", map[string]string{"project": "api"}, "Test: go test ./...\nLint: golangci-lint run\n\n# API commands", false},
+		{"commands come from task files", "list_commands", map[string]string{"project": "web"}, "Test: yarn run test\nLint: yarn run lint", false},
+		{"monorepo root is not a project", "list_commands", nil, "projects: api, web", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, isError := toolCall(t, s, tt.tool, tt.args)
+			if isError != tt.wantErr {
+				t.Errorf("isError = %v, want %v: %s", isError, tt.wantErr, text)
+			}
+			if !strings.Contains(text, tt.want) {
+				t.Errorf("result = %q, want to contain %q", text, tt.want)
+			}
+		})
+	}
+
+	msgs := session(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"rm_rf"}}`)
+	if msgs[0].Error == nil {
+		t.Error("unknown tool should be a protocol error")
+	}
+}
+
+func TestRunTests(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make is not installed")
+	}
+
+	tests := []struct {
+		name     string
+		makefile string
+		want     string
+		wantErr  bool
+	}{
+		{"passing", "test:\n\t@echo all good\n", "all good\n\nTests passed", false},
+		{"failing", "test:\n\t@echo broken\n\t@exit 3\n", "Tests failed", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			os.WriteFile(filepath.Join(root, "Makefile"), []byte(tt.makefile), 0644)
+			// The Makefile's test target wins over the stack's go test
+			s := NewServer(root, []detector.Result{{Path: root, Stack: detector.StackGo}}, Options{})
+
+			text, isError := toolCall(t, s, "run_tests", nil)
+			if isError != tt.wantErr {
+				t.Errorf("isError = %v, want %v: %s", isError, tt.wantErr, text)
+			}
+			if !strings.HasPrefix(text, "$ make test  (in .)\n") || !strings.Contains(text, tt.want) {
+				t.Errorf("result = %q, want to contain %q", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestServe_ToolCallsDoNotBlock(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make is not installed")
+	}
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "Makefile"), []byte("test:\n\t@while [ ! -f done ]; do sleep 0.05; done\n"), 0644)
+	s := NewServer(root, []detector.Result{{Path: root, Stack: detector.StackGo}}, Options{})
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(context.Background(), inR, outW)
+		outW.Close()
+	}()
+
+	// The tests run until the ping is answered
+	fmt.Fprintln(inW, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"run_tests"}}`)
+	fmt.Fprintln(inW, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	dec := json.NewDecoder(outR)
+	var first, second message
+	if err := dec.Decode(&first); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if string(first.ID) != "2" {
+		t.Fatalf("first response id = %s, want the ping's 2", first.ID)
+	}
+	os.WriteFile(filepath.Join(root, "done"), nil, 0644)
+	inW.Close()
+
+	if err := dec.Decode(&second); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if string(second.ID) != "1" || !strings.Contains(string(second.Result), "Tests passed") {
+		t.Errorf("run_tests response = %s %s", second.ID, second.Result)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("Serve() error = %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+	"github.com/Shaked/agentic-repo/internal/hooks"
+)
+
+// maxOutput bounds the test output returned by run_tests; the tail is
+// kept because failures are reported last
+const maxOutput = 64 * 1024
+
+// tool is an MCP tool descriptor
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// projectArg is the optional argument naming a project by path
+var projectArg = map[string]any{
+	"type":        "string",
+	"description": `Project path relative to the repository root, e.g. "services/api"; defaults to the project that owns the root`,
+}
+
+// toolDefinitions lists the tools in tools/list order
+var toolDefinitions = []tool{
+	{
+		Name:        "project_for_path",
+		Description: "Find the detected project that owns a file or directory, with its stack, tooling and detection evidence.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"path": map[string]any{"type": "string", "description": "File or directory, relative to the repository root"},
+			},
+			"required": []string{"path"},
+		},
+	},
+	{
+		Name:        "list_commands",
+		Description: "List the test and lint commands of a project, followed by its .agent/commands.md cheat sheet.",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"project": projectArg},
+		},
+	},
+	{
+		Name:        "run_tests",
+		Description: "Run a project's test command, as listed in its AGENTS.md, in the project directory and return its output and exit status.",
+		InputSchema: map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"project": projectArg},
+		},
+	},
+}
+
+// toolResult is the result of tools/call
+type toolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError"`
+}
+
+// textContent is a text content block
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// text returns a successful or failed tool result holding text
+func text(s string, isError bool) toolResult {
+	return toolResult{Content: []textContent{{Type: "text", Text: s}}, IsError: isError}
+}
+
+// callTool answers tools/call. Problems with the arguments are reported
+// as failed tool results so the model can correct itself.
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string `json:"name"`
+		Arguments struct {
+			Path    string `json:"path"`
+			Project string `json:"project"`
+		} `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid tools/call params: %w", err)
+	}
+
+	var (
+		out string
+		err error
+	)
+	switch p.Name {
+	case "project_for_path":
+		out, err = s.projectForPath(p.Arguments.Path)
+	case "list_commands":
+		out, err = s.listCommands(p.Arguments.Project)
+	case "run_tests":
+		return s.runTests(ctx, p.Arguments.Project), nil
+	default:
+		return nil, fmt.Errorf("unknown tool: %s", p.Name)
+	}
+	if err != nil {
+		return text(err.Error(), true), nil
+	}
+	return text(out, false), nil
+}
+
+// projectForPath describes the deepest project containing path
+func (s *Server) projectForPath(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("path is required")
+	}
+	abs, err := s.resolve(path)
+	if err != nil {
+		return "", err
+	}
+
+	r, ok := s.owner(abs)
+	if !ok {
+		return "", fmt.Errorf("no detected project contains %s", path)
+	}
+
+	data, err := json.MarshalIndent(detector.NewReport(s.root, []detector.Result{r}).Projects[0], "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// listCommands returns a project's commands and its commands.md
+func (s *Server) listCommands(project string) (string, error) {
+	r, err := s.project(project)
+	if err != nil {
+		return "", err
+	}
+
+	cmds := s.commands(r)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project: %s (%s)\nTest: %s\nLint: %s\n", s.rel(r.Path), r.Stack, cmds.Test, cmds.Lint)
+	if content, err := os.ReadFile(filepath.Join(r.Path, ".agent", "commands.md")); err == nil {
+		fmt.Fprintf(&b, "\n%s", content)
+	}
+	return b.String(), nil
+}
+
+// runTests runs a project's test command and reports its output
+func (s *Server) runTests(ctx context.Context, project string) toolResult {
+	r, err := s.project(project)
+	if err != nil {
+		return text(err.Error(), true)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.TestTimeout)
+	defer cancel()
+
+	command := s.commands(r).Test
+	var out bytes.Buffer
+	cmd := hooks.ShellCommand(ctx, command)
+	cmd.Dir = r.Path
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second
+	runErr := cmd.Run()
+
+	output := out.String()
+	if len(output) > maxOutput {
+		output = "[output truncated]\n" + output[len(output)-maxOutput:]
+	}
+
+	status := "passed"
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		status = fmt.Sprintf("timed out after %s", s.opts.TestTimeout)
+	case runErr != nil:
+		status = "failed: " + runErr.Error()
+	}
+	return text(fmt.Sprintf("$ %s  (in %s)\n%s\nTests %s", command, s.rel(r.Path), output, status), runErr != nil)
+}
+
+// commands returns the test and lint commands of a project as its
+// generated docs list them
+func (s *Server) commands(r detector.Result) generator.Commands {
+	return generator.ProjectCommands(s.root, []detector.Result{r})[0]
+}
+
+// project returns the project at a root-relative path; an empty path is
+// the project owning the root
+func (s *Server) project(path string) (detector.Result, error) {
+	if path == "" {
+		path = "."
+	}
+	abs, err := s.resolve(path)
+	if err != nil {
+		return detector.Result{}, err
+	}
+	for _, r := range s.results {
+		if r.Path == abs {
+			return r, nil
+		}
+	}
+
+	var known []string
+	for _, r := range s.results {
+		known = append(known, s.rel(r.Path))
+	}
+	return detector.Result{}, fmt.Errorf("no project at %s (projects: %s)", path, strings.Join(known, ", "))
+}
+
+// owner returns the deepest project whose directory contains abs
+func (s *Server) owner(abs string) (detector.Result, bool) {
+	var best detector.Result
+	found := false
+	for _, r := range s.results {
+		rel, err := filepath.Rel(r.Path, abs)
+		if err != nil || !filepath.IsLocal(rel) {
+			continue
+		}
+		if !found || len(r.Path) > len(best.Path) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// resolve turns a root-relative or absolute path into an absolute path
+// inside the repository
+func (s *Server) resolve(path string) (string, error) {
+	abs := path
+	if !filepath.IsAbs(path) {
+		abs = filepath.Join(s.root, filepath.FromSlash(path))
+	}
+	abs = filepath.Clean(abs)
+
+	rel, err := filepath.Rel(s.root, abs)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is outside the repository", path)
+	}
+	return abs, nil
+}
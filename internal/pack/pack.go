@@ -0,0 +1,324 @@
+// Package pack bundles a project's agent context and source files into a
+// single document within a token budget, for assistants that cannot read
+// the repository themselves.
+package pack
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Shaked/agentic-repo/internal/arch"
+	"github.com/Shaked/agentic-repo/internal/ignore"
+	"github.com/Shaked/agentic-repo/internal/tokens"
+)
+
+// minTruncated is the smallest remainder, in tokens, worth filling with
+// a truncated context file
+const minTruncated = 200
+
+// truncatedNote ends a file cut to fit the budget
+const truncatedNote = "\n… (truncated to fit the token budget)\n"
+
+// Relevance tiers, most relevant first
+const (
+	// tierRouter is the AGENTS.md router
+	tierRouter = iota
+	// tierContext is the .agent/ context
+	tierContext
+	// tierManifest is the README and build manifests
+	tierManifest
+	// tierEntry is the source of entry points
+	tierEntry
+	// tierSource is other source, most depended-on first
+	tierSource
+	// tierTest is test code
+	tierTest
+	// tierOther is any other text file
+	tierOther
+)
+
+// contextOrder ranks the .agent/ files; others follow alphabetically
+var contextOrder = []string{"rules.md", "overview.md", "architecture.md", "stack.md", "commands.md", "testing.md"}
+
+// manifestNames are the build manifests packed right after the context
+var manifestNames = map[string]bool{
+	"go.mod": true, "package.json": true, "pyproject.toml": true, "setup.py": true,
+	"requirements.txt": true, "pom.xml": true, "build.gradle": true, "build.gradle.kts": true,
+	"Cargo.toml": true, "Makefile": true, "tsconfig.json": true,
+}
+
+// sourceExts are the extensions of source code
+var sourceExts = map[string]bool{
+	".go": true, ".py": true, ".ts": true, ".tsx": true, ".js": true, ".jsx": true,
+	".mjs": true, ".cjs": true, ".mts": true, ".cts": true, ".java": true, ".kt": true,
+	".kts": true, ".scala": true, ".rs": true, ".rb": true, ".php": true, ".c": true,
+	".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".cs": true, ".swift": true,
+	".sh": true, ".sql": true, ".vue": true, ".svelte": true,
+}
+
+// entryNames are file names, without extension, that are entry points
+// when no import graph says otherwise
+var entryNames = map[string]bool{"main": true, "__main__": true, "index": true, "app": true, "cli": true, "server": true}
+
+// Options configure Build
+type Options struct {
+	// Root is the repository root
+	Root string
+	// Dir is the project directory, Root or below it
+	Dir string
+	// MaxTokens bounds the estimated tokens of the packed files; 0 packs
+	// everything
+	MaxTokens int
+	// Graph is the project's import graph, used to rank source files; it
+	// may be nil
+	Graph *arch.Graph
+	// Exclude lists root-relative paths never to pack, e.g. files derived
+	// from the .agent/ context
+	Exclude []string
+}
+
+// File is a packed file
+type File struct {
+	// Path is root-relative in slash form
+	Path    string
+	Content string
+	// Tokens is the estimated token count of Content
+	Tokens int
+	// Truncated marks files cut to fit the budget
+	Truncated bool
+}
+
+// Bundle is a packed project
+type Bundle struct {
+	// Project names the project: its root-relative directory, or the
+	// repository's name for the root
+	Project string
+	Files   []File
+	// Omitted lists the files left out to stay within the budget, in
+	// order of relevance
+	Omitted []string
+}
+
+// Tokens returns the estimated tokens of the packed files
+func (b *Bundle) Tokens() int {
+	n := 0
+	for _, f := range b.Files {
+		n += f.Tokens
+	}
+	return n
+}
+
+// candidate is a file considered for packing
+type candidate struct {
+	rel        string
+	content    string
+	tier       int
+	importance int
+	rank       int
+}
+
+// Build collects the router, the .agent/ context and the files of the
+// project at opts.Dir, skipping anything .gitignore or .agentignore
+// excludes, hidden files, binary files and files over 256 KiB, and packs
+// them in order of relevance until opts.MaxTokens is reached
+func Build(opts Options) (*Bundle, error) {
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root: %w", err)
+	}
+	dir, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project: %w", err)
+	}
+	relDir, err := filepath.Rel(root, dir)
+	if err != nil || relDir == ".." || strings.HasPrefix(relDir, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("project %s is outside %s", dir, root)
+	}
+	relDir = filepath.ToSlash(relDir)
+
+	excluded := map[string]bool{}
+	for _, p := range opts.Exclude {
+		excluded[filepath.ToSlash(p)] = true
+	}
+
+	candidates := contextFiles(root, relDir)
+	for _, c := range candidates {
+		excluded[c.rel] = true
+	}
+	sources, err := sourceFiles(root, relDir, opts.Graph, excluded)
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates, sources...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.tier != b.tier {
+			return a.tier < b.tier
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.importance != b.importance {
+			return a.importance > b.importance
+		}
+		if da, db := strings.Count(a.rel, "/"), strings.Count(b.rel, "/"); da != db {
+			return da < db
+		}
+		return a.rel < b.rel
+	})
+
+	bundle := &Bundle{Project: relDir}
+	if relDir == "." {
+		bundle.Project = filepath.Base(root)
+	}
+	remaining := opts.MaxTokens
+	for _, c := range candidates {
+		f := File{Path: c.rel, Content: c.content, Tokens: tokens.Count(c.content)}
+		switch {
+		case opts.MaxTokens == 0 || f.Tokens <= remaining:
+		case c.tier <= tierContext && remaining >= minTruncated:
+			// The context is worth keeping in part; sources are all or
+			// nothing so no function is cut in half
+			f = truncate(f, remaining)
+		default:
+			bundle.Omitted = append(bundle.Omitted, c.rel)
+			continue
+		}
+		bundle.Files = append(bundle.Files, f)
+		remaining -= f.Tokens
+	}
+	return bundle, nil
+}
+
+// truncate cuts a file at a line boundary to fit budget tokens
+func truncate(f File, budget int) File {
+	// Start from the share of the content the budget pays for and shrink
+	// until the tokenizer agrees
+	limit := len(f.Content) * budget / max(f.Tokens, 1)
+	for {
+		content := strings.ToValidUTF8(f.Content[:min(limit, len(f.Content))], "")
+		if i := strings.LastIndex(content, "\n"); i > 0 {
+			content = content[:i]
+		}
+		content += truncatedNote
+		if n := tokens.Count(content); n <= budget || limit == 0 {
+			f.Content, f.Tokens, f.Truncated = content, n, true
+			return f
+		}
+		limit = limit * 9 / 10
+	}
+}
+
+// contextFiles returns the project's AGENTS.md and .agent/ Markdown files,
+// plus the repository-wide rules and overview for a subproject
+func contextFiles(root, relDir string) []candidate {
+	var files []candidate
+	add := func(rel string, tier int) {
+		for _, c := range files {
+			if c.rel == rel {
+				return
+			}
+		}
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return
+		}
+		rank := len(contextOrder)
+		for i, name := range contextOrder {
+			if path.Base(rel) == name {
+				rank = i
+			}
+		}
+		files = append(files, candidate{rel: rel, content: string(content), tier: tier, rank: rank})
+	}
+
+	add(path.Join(relDir, "AGENTS.md"), tierRouter)
+	matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(relDir), ".agent", "*.md"))
+	for _, m := range matches {
+		add(path.Join(relDir, ".agent", filepath.Base(m)), tierContext)
+	}
+	if relDir != "." {
+		add(".agent/rules.md", tierContext)
+		add(".agent/overview.md", tierContext)
+	}
+	return files
+}
+
+// sourceFiles walks the project and ranks every packable file
+func sourceFiles(root, relDir string, graph *arch.Graph, excluded map[string]bool) ([]candidate, error) {
+	var files []candidate
+	err := ignore.Walk(root, relDir, func(rel string, d fs.DirEntry) error {
+		if strings.HasPrefix(d.Name(), ".") || excluded[rel] {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := ignore.ReadText(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return nil
+		}
+		c := candidate{rel: rel, content: string(content)}
+		c.tier, c.importance = classify(strings.TrimPrefix(rel, relDir+"/"), graph)
+		files = append(files, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// classify returns the tier of a project-relative file and, for sources,
+// how many components depend on its directory
+func classify(rel string, graph *arch.Graph) (tier, importance int) {
+	base := path.Base(rel)
+	ext := path.Ext(base)
+	switch {
+	case manifestNames[base] || strings.HasPrefix(strings.ToUpper(base), "README") && path.Dir(rel) == ".":
+		return tierManifest, 0
+	case !sourceExts[ext]:
+		return tierOther, 0
+	case isTest(rel):
+		return tierTest, 0
+	}
+
+	dir := path.Dir(rel)
+	if graph != nil {
+		if c := graph.Component(dir); c != nil {
+			if c.Entry {
+				return tierEntry, 0
+			}
+			return tierSource, len(graph.Impact(dir))
+		}
+	}
+	if entryNames[strings.TrimSuffix(base, ext)] {
+		return tierEntry, 0
+	}
+	return tierSource, 0
+}
+
+// isTest reports whether a source file is test code
+func isTest(rel string) bool {
+	base := path.Base(rel)
+	name := strings.TrimSuffix(base, path.Ext(base))
+	if strings.HasSuffix(name, "_test") || strings.HasPrefix(name, "test_") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		strings.HasSuffix(name, "Test") || strings.HasSuffix(name, "Tests") {
+		return true
+	}
+	for _, part := range strings.Split(path.Dir(rel), "/") {
+		if part == "test" || part == "tests" || part == "__tests__" || part == "testdata" {
+			return true
+		}
+	}
+	return false
+}
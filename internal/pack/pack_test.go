@@ -0,0 +1,164 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/arch"
+)
+
+// writeFiles creates files relative to root
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// paths returns the paths of the packed files
+func paths(b *Bundle) []string {
+	var p []string
+	for _, f := range b.Files {
+		p = append(p, f.Path)
+	}
+	return p
+}
+
+func TestBuild_Order(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":                          "*.log\n",
+		".agent/rules.md":                     "# Rules\n",
+		".agent/overview.md":                  "# Overview\n",
+		"services/.agentignore":               "fixtures/\n",
+		"services/api/AGENTS.md":              "# api\n",
+		"services/api/.agent/stack.md":        "# Stack\n",
+		"services/api/.agent/architecture.md": "# Architecture\n",
+		"services/api/.agent/notes.md":        "# Notes\n",
+		"services/api/.agent/manifest.json":   "{}\n",
+		"services/api/CLAUDE.md":              "@AGENTS.md\n",
+		"services/api/go.mod":                 "module example.com/api\n",
+		"services/api/README.md":              "# API\n",
+		"services/api/cmd/api/main.go":        "package main\n",
+		"services/api/store/store.go":         "package store\n",
+		"services/api/store/store_test.go":    "package store\n",
+		"services/api/handler/handler.go":     "package handler\n",
+		"services/api/docs/design.txt":        "design\n",
+		"services/api/debug.log":              "ignored\n",
+		"services/api/fixtures/big.json":      "{}\n",
+		"services/api/.env":                   "SECRET=1\n",
+		"services/api/logo.png":               "\x89PNG\x00\x00",
+		"services/web/index.ts":               "export {}\n",
+	})
+
+	graph := &arch.Graph{Components: []arch.Component{
+		{Dir: "cmd/api", Imports: []string{"handler", "store"}, Entry: true},
+		{Dir: "handler", Imports: []string{"store"}},
+		{Dir: "store"},
+	}}
+	b, err := Build(Options{Root: root, Dir: filepath.Join(root, "services", "api"), Graph: graph, Exclude: []string{"services/api/CLAUDE.md"}})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := []string{
+		"services/api/AGENTS.md",
+		".agent/rules.md",
+		".agent/overview.md",
+		"services/api/.agent/architecture.md",
+		"services/api/.agent/stack.md",
+		"services/api/.agent/notes.md",
+		"services/api/README.md",
+		"services/api/go.mod",
+		"services/api/cmd/api/main.go",
+		"services/api/store/store.go",
+		"services/api/handler/handler.go",
+		"services/api/store/store_test.go",
+		"services/api/docs/design.txt",
+	}
+	if got := paths(b); !reflect.DeepEqual(got, want) {
+		t.Errorf("files =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if b.Project != "services/api" || len(b.Omitted) != 0 {
+		t.Errorf("Project = %q, Omitted = %v", b.Project, b.Omitted)
+	}
+}
+
+func TestBuild_Budget(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"AGENTS.md": "# Router\n",
+		"main.go":   "package main\n",
+		"big.go":    strings.Repeat("// filler\n", 100),
+		"small.go":  "package small\n",
+	})
+
+	// Sources that do not fit are skipped whole; smaller ones still fit
+	b, err := Build(Options{Root: root, Dir: root, MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if got, want := paths(b), []string{"AGENTS.md", "main.go", "small.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(b.Omitted, []string{"big.go"}) {
+		t.Errorf("Omitted = %v", b.Omitted)
+	}
+	if b.Project != filepath.Base(root) {
+		t.Errorf("Project = %q", b.Project)
+	}
+
+	// Context that does not fit is truncated
+	writeFiles(t, root, map[string]string{".agent/stack.md": strings.Repeat("Use the standard library.\n", 100)})
+	b, err = Build(Options{Root: root, Dir: root, MaxTokens: 300})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if got := paths(b); len(got) < 2 || got[1] != ".agent/stack.md" {
+		t.Fatalf("files = %v, want .agent/stack.md second", got)
+	}
+	if !b.Files[1].Truncated || !strings.HasSuffix(b.Files[1].Content, "library.\n"+truncatedNote[1:]) {
+		t.Errorf("stack.md should be truncated at a line end:\n%s", b.Files[1].Content)
+	}
+	if b.Tokens() > 300 {
+		t.Errorf("Tokens() = %d, want at most 300", b.Tokens())
+	}
+}
+
+func TestBuild_OutsideRoot(t *testing.T) {
+	root := t.TempDir()
+	if _, err := Build(Options{Root: filepath.Join(root, "a"), Dir: root}); err == nil {
+		t.Error("Build() with a project outside the root should fail")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		rel  string
+		tier int
+	}{
+		{"package.json", tierManifest},
+		{"README.md", tierManifest},
+		{"docs/README.md", tierOther},
+		{"src/index.ts", tierEntry},
+		{"src/util.ts", tierSource},
+		{"src/util.test.ts", tierTest},
+		{"tests/test_api.py", tierTest},
+		{"src/main/java/AppTest.java", tierTest},
+		{"config.yaml", tierOther},
+	}
+	for _, tt := range tests {
+		if tier, _ := classify(tt.rel, nil); tier != tt.tier {
+			t.Errorf("classify(%q) = %d, want %d", tt.rel, tier, tt.tier)
+		}
+	}
+}
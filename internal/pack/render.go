@@ -0,0 +1,97 @@
+package pack
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// fenceLanguages maps extensions and file names to Markdown fence
+// languages
+var fenceLanguages = map[string]string{
+	".go": "go", ".py": "python", ".ts": "typescript", ".tsx": "tsx", ".js": "javascript",
+	".jsx": "jsx", ".mjs": "javascript", ".cjs": "javascript", ".java": "java", ".kt": "kotlin",
+	".kts": "kotlin", ".rs": "rust", ".rb": "ruby", ".php": "php", ".c": "c", ".h": "c",
+	".cpp": "cpp", ".cs": "csharp", ".swift": "swift", ".sh": "bash", ".sql": "sql",
+	".md": "markdown", ".json": "json", ".yaml": "yaml", ".yml": "yaml", ".toml": "toml",
+	".xml": "xml", ".html": "html", ".css": "css", "Makefile": "make", "Dockerfile": "dockerfile",
+}
+
+// attrEscaper escapes XML attribute values
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// summary describes the bundle in one line
+func (b *Bundle) summary() string {
+	s := fmt.Sprintf("%d files, ~%d tokens", len(b.Files), b.Tokens())
+	if len(b.Omitted) > 0 {
+		s += fmt.Sprintf("; %d omitted to fit the token budget", len(b.Omitted))
+	}
+	return s
+}
+
+// Markdown renders the bundle as one Markdown document with a section per
+// file, each in a fence longer than any backtick run it contains
+func (b *Bundle) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Context: %s\n\nPacked by agentic-repo: %s.\n", b.Project, b.summary())
+	for _, f := range b.Files {
+		fence := strings.Repeat("`", max(3, longestRun(f.Content, '`')+1))
+		fmt.Fprintf(&sb, "\n## %s\n\n%s%s\n%s", f.Path, fence, fenceLanguage(f.Path), f.Content)
+		if !strings.HasSuffix(f.Content, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fence + "\n")
+	}
+	if len(b.Omitted) > 0 {
+		sb.WriteString("\n## Omitted\n\nLeft out to fit the token budget, most relevant first:\n\n")
+		for _, p := range b.Omitted {
+			fmt.Fprintf(&sb, "- `%s`\n", p)
+		}
+	}
+	return sb.String()
+}
+
+// XML renders the bundle as <file> elements in a <context> element. File
+// contents are written as is, the way assistants expect source to appear.
+func (b *Bundle) XML() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<context project=\"%s\" files=\"%d\" tokens=\"%d\">\n", attrEscaper.Replace(b.Project), len(b.Files), b.Tokens())
+	for _, f := range b.Files {
+		truncated := ""
+		if f.Truncated {
+			truncated = ` truncated="true"`
+		}
+		fmt.Fprintf(&sb, "<file path=\"%s\"%s>\n%s", attrEscaper.Replace(f.Path), truncated, f.Content)
+		if !strings.HasSuffix(f.Content, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("</file>\n")
+	}
+	for _, p := range b.Omitted {
+		fmt.Fprintf(&sb, "<omitted path=\"%s\"/>\n", attrEscaper.Replace(p))
+	}
+	sb.WriteString("</context>\n")
+	return sb.String()
+}
+
+// fenceLanguage returns the fence language of a file, "" when unknown
+func fenceLanguage(p string) string {
+	if lang, ok := fenceLanguages[path.Base(p)]; ok {
+		return lang
+	}
+	return fenceLanguages[path.Ext(p)]
+}
+
+// longestRun returns the length of the longest run of c in s
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
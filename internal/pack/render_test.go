@@ -0,0 +1,48 @@
+package pack
+
+import (
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	b := &Bundle{
+		Project: "api",
+		Files: []File{
+			{Path: "api/AGENTS.md", Content: "# api\n\n```bash\nmake test\n```\n", Tokens: 8},
+			{Path: "api/main.go", Content: "package main", Tokens: 3},
+		},
+		Omitted: []string{"api/big.go"},
+	}
+	want := "# Context: api\n\nPacked by agentic-repo: 2 files, ~11 tokens; 1 omitted to fit the token budget.\n" +
+		"\n## api/AGENTS.md\n\n````markdown\n# api\n\n```bash\nmake test\n```\n````\n" +
+		"\n## api/main.go\n\n```go\npackage main\n```\n" +
+		"\n## Omitted\n\nLeft out to fit the token budget, most relevant first:\n\n- `api/big.go`\n"
+	if got := b.Markdown(); got != want {
+		t.Errorf("Markdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestXML(t *testing.T) {
+	b := &Bundle{
+		Project: `a&b`,
+		Files: []File{
+			{Path: "Makefile", Content: "test:\n\tgo test\n", Tokens: 4, Truncated: true},
+		},
+		Omitted: []string{`x"y.go`},
+	}
+	want := "<context project=\"a&amp;b\" files=\"1\" tokens=\"4\">\n" +
+		"<file path=\"Makefile\" truncated=\"true\">\ntest:\n\tgo test\n</file>\n" +
+		"<omitted path=\"x&quot;y.go\"/>\n</context>\n"
+	if got := b.XML(); got != want {
+		t.Errorf("XML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFenceLanguage(t *testing.T) {
+	tests := map[string]string{"a/Makefile": "make", "x.py": "python", "LICENSE": ""}
+	for p, want := range tests {
+		if got := fenceLanguage(p); got != want {
+			t.Errorf("fenceLanguage(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
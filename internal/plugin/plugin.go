@@ -0,0 +1,130 @@
+// Package plugin runs external agentic-repo plugins. Executables named
+// agentic-repo-<name> on PATH become subcommands, and configured detector
+// and generator plugins exchange JSON with agentic-repo over stdio.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix is the file name prefix of plugin executables
+const Prefix = "agentic-repo-"
+
+// EnvBin is set for every plugin to the path of the running agentic-repo,
+// so plugins can call back into it
+const EnvBin = "AGENTIC_REPO_BIN"
+
+// Executable is a plugin executable found on PATH
+type Executable struct {
+	// Name is the subcommand name, the file name without Prefix
+	Name string
+	// Path is the absolute path of the executable
+	Path string
+}
+
+// Discover finds plugin executables in the directories of pathList, which
+// is formatted like the PATH environment variable. When several
+// directories contain the same plugin the first one wins, as in a shell.
+func Discover(pathList string) []Executable {
+	seen := map[string]bool{}
+	var found []Executable
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := pluginName(e.Name())
+			if !ok || seen[name] || e.IsDir() {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			found = append(found, Executable{Name: name, Path: path})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+// pluginName returns the subcommand name for a file name
+func pluginName(file string) (string, bool) {
+	if !strings.HasPrefix(file, Prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(file, Prefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name, name != ""
+}
+
+// isExecutable reports whether path is a regular file that can be run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// ExitError reports that a plugin subcommand exited with a non-zero
+// status, which agentic-repo exits with in turn
+type ExitError struct {
+	Name string
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("plugin %s exited with status %d", e.Name, e.Code)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Exec runs a plugin executable as a subcommand, passing args and stdio
+// through unchanged. A non-zero exit is returned as an *ExitError.
+func (e Executable) Exec(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, e.Path, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = environ()
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return &ExitError{Name: e.Name, Code: exitErr.ExitCode(), Err: err}
+	}
+	return err
+}
+
+// environ returns the environment plugins run with
+func environ() []string {
+	env := os.Environ()
+	if self, err := os.Executable(); err == nil {
+		env = append(env, EnvBin+"="+self)
+	}
+	return env
+}
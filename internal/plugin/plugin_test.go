@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeScript creates an executable shell script in dir
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+
+	writeScript(t, first, "agentic-repo-docs", "exit 0\n")
+	writeScript(t, second, "agentic-repo-docs", "exit 0\n")
+	writeScript(t, second, "agentic-repo-audit", "exit 0\n")
+	writeScript(t, second, "other-tool", "exit 0\n")
+	os.WriteFile(filepath.Join(second, "agentic-repo-notes"), []byte("not executable"), 0644)
+	os.Mkdir(filepath.Join(second, "agentic-repo-dir"), 0755)
+
+	pathList := strings.Join([]string{first, "", "/nonexistent", second}, string(os.PathListSeparator))
+	got := Discover(pathList)
+
+	want := []Executable{
+		{Name: "audit", Path: filepath.Join(second, "agentic-repo-audit")},
+		{Name: "docs", Path: filepath.Join(first, "agentic-repo-docs")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover() = %v, want %v", got, want)
+	}
+}
+
+func TestPluginName(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+		ok       bool
+	}{
+		{"agentic-repo-docs", "docs", true},
+		{"agentic-repo-", "", false},
+		{"agentic-repo", "", false},
+		{"kubectl-foo", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := pluginName(tt.file)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("pluginName(%q) = %q, %v; want %q, %v", tt.file, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestExecutable_Exec(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScript(t, dir, "agentic-repo-echo", "read line\necho \"$line $1 $2\"\nexit 4\n")
+
+	var stdout bytes.Buffer
+	err := Executable{Name: "echo", Path: path}.Exec(context.Background(), []string{"--flag", "arg"}, strings.NewReader("input\n"), &stdout, &bytes.Buffer{})
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 4 || exitErr.Name != "echo" {
+		t.Fatalf("Exec() error = %v, want exit status 4", err)
+	}
+	if got := stdout.String(); got != "input --flag arg\n" {
+		t.Errorf("stdout = %q, want %q", got, "input --flag arg\n")
+	}
+}
@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+	"github.com/Shaked/agentic-repo/internal/generator"
+)
+
+// ProtocolVersion is sent with every request. It is bumped when a request
+// or response field is removed or changes meaning.
+const ProtocolVersion = 1
+
+// DefaultTimeout bounds how long a single plugin invocation may run
+const DefaultTimeout = 30 * time.Second
+
+// Kind selects what a plugin is asked to do
+type Kind string
+
+const (
+	// KindDetector plugins report additional projects
+	KindDetector Kind = "detector"
+	// KindGenerator plugins return additional files to write
+	KindGenerator Kind = "generator"
+)
+
+// Request is written as JSON to a plugin's stdin
+type Request struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Kind            Kind   `json:"kind"`
+	Root            string `json:"root"`
+	// Report is the scan so far, in the `detect --format json` schema
+	Report detector.Report `json:"report"`
+}
+
+// Response is read as JSON from a plugin's stdout
+type Response struct {
+	// Projects are detections from detector plugins
+	Projects []Project `json:"projects,omitempty"`
+	// Files are extra files from generator plugins
+	Files []File `json:"files,omitempty"`
+}
+
+// Project is a detection reported by a plugin
+type Project struct {
+	// Path is relative to the root, slash-separated, "." for the root
+	Path  string `json:"path"`
+	Stack string `json:"stack"`
+	// Confidence defaults to 1 when omitted
+	Confidence float64           `json:"confidence,omitempty"`
+	Evidence   []string          `json:"evidence,omitempty"`
+	Tools      map[string]string `json:"tools,omitempty"`
+}
+
+// File is a file returned by a generator plugin
+type File struct {
+	// Path is relative to the root and slash-separated
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Plugin is a configured detector or generator plugin
+type Plugin struct {
+	Name string
+	Kind Kind
+	// Command is the executable: a path, or a name looked up on PATH
+	Command string
+	Args    []string
+}
+
+// Run sends req to the plugin and decodes its response. The plugin runs
+// in req.Root; anything it writes to stderr is included in errors.
+func (p Plugin) Run(ctx context.Context, req Request) (*Response, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request for plugin %s: %w", p.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Dir = req.Root
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = environ()
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", p.Name, err, msg)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", p.Name, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %w", p.Name, err)
+	}
+	return &resp, nil
+}
+
+// Detect runs every detector plugin and returns the projects they report
+func Detect(ctx context.Context, plugins []Plugin, root string, results []detector.Result) ([]detector.Result, error) {
+	var found []detector.Result
+	for _, p := range plugins {
+		if p.Kind != KindDetector {
+			continue
+		}
+		resp, err := p.Run(ctx, newRequest(KindDetector, root, results))
+		if err != nil {
+			return nil, err
+		}
+		for _, proj := range resp.Projects {
+			r, err := proj.result(root)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+			}
+			found = append(found, r)
+		}
+	}
+	return found, nil
+}
+
+// Generate runs every generator plugin and returns the files they render
+func Generate(ctx context.Context, plugins []Plugin, root string, results []detector.Result) ([]generator.File, error) {
+	var files []generator.File
+	for _, p := range plugins {
+		if p.Kind != KindGenerator {
+			continue
+		}
+		resp, err := p.Run(ctx, newRequest(KindGenerator, root, results))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Files {
+			path, err := resolve(root, f.Path)
+			if err != nil {
+				return nil, fmt.Errorf("plugin %s: %w", p.Name, err)
+			}
+			files = append(files, generator.File{Path: path, Content: []byte(f.Content)})
+		}
+	}
+	return files, nil
+}
+
+// newRequest builds a request for the current scan
+func newRequest(kind Kind, root string, results []detector.Result) Request {
+	return Request{
+		ProtocolVersion: ProtocolVersion,
+		Kind:            kind,
+		Root:            root,
+		Report:          detector.NewReport(root, results),
+	}
+}
+
+// result converts a plugin project to a detector result
+func (p Project) result(root string) (detector.Result, error) {
+	if p.Stack == "" {
+		return detector.Result{}, fmt.Errorf("project %q has no stack", p.Path)
+	}
+	path, err := resolve(root, p.Path)
+	if err != nil {
+		return detector.Result{}, err
+	}
+	confidence := p.Confidence
+	if confidence == 0 {
+		confidence = 1
+	}
+	return detector.Result{
+		Path:       path,
+		Stack:      detector.StackType(p.Stack),
+		Confidence: confidence,
+		Evidence:   p.Evidence,
+		Tools:      p.Tools,
+	}, nil
+}
+
+// resolve turns a plugin-supplied relative path into an absolute path,
+// refusing anything outside root
+func resolve(root, rel string) (string, error) {
+	local := filepath.FromSlash(rel)
+	if rel == "" || !filepath.IsLocal(local) {
+		return "", fmt.Errorf("path %q is not inside the repository", rel)
+	}
+	return filepath.Join(root, local), nil
+}
@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+func TestPlugin_Run(t *testing.T) {
+	dir := t.TempDir()
+	// Echo the request kind back as a file so the round trip is visible
+	cmd := writeScript(t, dir, "gen", `kind=$(sed -n 's/.*"kind":"\([a-z]*\)".*/\1/p')
+printf '{"files":[{"path":"docs/%s.md","content":"hi"}]}' "$kind"
+`)
+
+	p := Plugin{Name: "gen", Kind: KindGenerator, Command: cmd}
+	resp, err := p.Run(context.Background(), newRequest(KindGenerator, dir, nil))
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(resp.Files) != 1 || resp.Files[0].Path != "docs/generator.md" {
+		t.Errorf("Files = %+v, want docs/generator.md", resp.Files)
+	}
+}
+
+func TestPlugin_RunErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		body    string
+		wantMsg string
+	}{
+		{"non-zero exit includes stderr", "echo boom >&2\nexit 1\n", "boom"},
+		{"invalid JSON", "echo not json\n", "invalid JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := writeScript(t, dir, "plugin", tt.body)
+			p := Plugin{Name: "broken", Kind: KindDetector, Command: cmd}
+
+			_, err := p.Run(context.Background(), newRequest(KindDetector, dir, nil))
+			if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Run() error = %v, want it to mention %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	cmd := writeScript(t, dir, "det", `cat > /dev/null
+echo '{"projects":[{"path":"tools","stack":"bazel","evidence":["WORKSPACE"]}]}'
+`)
+	plugins := []Plugin{
+		{Name: "det", Kind: KindDetector, Command: cmd},
+		// Generator plugins are not asked to detect
+		{Name: "gen", Kind: KindGenerator, Command: "/nonexistent"},
+	}
+
+	results, err := Detect(context.Background(), plugins, dir, nil)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	r := results[0]
+	if r.Path != filepath.Join(dir, "tools") || r.Stack != "bazel" || r.Confidence != 1 {
+		t.Errorf("result = %+v, want tools/bazel with confidence 1", r)
+	}
+}
+
+func TestGenerate_RejectsPathsOutsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	cmd := writeScript(t, dir, "gen", `cat > /dev/null
+echo '{"files":[{"path":"../escape.md","content":"x"}]}'
+`)
+	plugins := []Plugin{{Name: "gen", Kind: KindGenerator, Command: cmd}}
+
+	if _, err := Generate(context.Background(), plugins, dir, nil); err == nil {
+		t.Error("expected error for a path outside the root")
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	results := []detector.Result{{Path: "/repo/api", Stack: detector.StackGo, Confidence: 1}}
+	req := newRequest(KindDetector, "/repo", results)
+
+	if req.ProtocolVersion != ProtocolVersion || req.Kind != KindDetector {
+		t.Errorf("request = %+v", req)
+	}
+	if len(req.Report.Projects) != 1 || req.Report.Projects[0].Path != "api" {
+		t.Errorf("report projects = %+v, want api", req.Report.Projects)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		rel     string
+		wantErr bool
+	}{
+		{"docs/a.md", false},
+		{".", false},
+		{"", true},
+		{"../a.md", true},
+		{"/etc/passwd", true},
+	}
+	for _, tt := range tests {
+		_, err := resolve("/repo", tt.rel)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolve(%q) error = %v, wantErr %v", tt.rel, err, tt.wantErr)
+		}
+	}
+}
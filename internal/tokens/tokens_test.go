@@ -0,0 +1,70 @@
+package tokens
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"Hello world", []string{"Hello", " world"}},
+		{"a  b", []string{"a", " ", " b"}},
+		{"x := y", []string{"x", " :=", " y"}},
+		{"f(x)\n", []string{"f", "(x", ")\n"}},
+		{"year 2024!", []string{"year", " ", "202", "4", "!"}},
+		{"a\n\n\tb", []string{"a", "\n\n", "\t", "b"}},
+		{"run .agent", []string{"run", " ", ".agent"}},
+		{"héllo 世界", []string{"héllo", " 世界"}},
+	}
+	for _, tt := range tests {
+		if got := split(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("split(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{" ", 1},
+		{strings.Repeat(" ", 17), 2},
+		{"the", 1},
+		{" function", 1},
+		{"\n", 1},
+	}
+	for _, tt := range tests {
+		if got := Count(tt.in); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCount_Ratio(t *testing.T) {
+	// Tokenizers average three to five bytes per token on English and code
+	samples := []string{
+		"The router lists the context files an agent should read before it changes anything in the repository, so keep it short.",
+		"func (m *Matcher) Match(rel string, isDir bool) bool {\n\tif m.Empty() {\n\t\treturn false\n\t}\n\treturn m.matchOne(rel, isDir)\n}\n",
+		"def load(path):\n    with open(path) as f:\n        return json.load(f)\n",
+	}
+	for _, s := range samples {
+		n := Count(s)
+		if ratio := float64(len(s)) / float64(n); ratio < 2.5 || ratio > 6 {
+			t.Errorf("Count(%q) = %d, %.1f bytes per token", s, n, ratio)
+		}
+	}
+}
+
+func TestCount_NonASCII(t *testing.T) {
+	// Characters outside the vocabulary cost about a token per two bytes
+	if got := Count("世界"); got != 4 {
+		t.Errorf("Count(世界) = %d, want 4", got)
+	}
+}
@@ -0,0 +1,183 @@
+package agentic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files with content below dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create file %s: %v", name, err)
+		}
+	}
+}
+
+func TestScanDir(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"api/go.mod":       "module api\n",
+		"web/package.json": "{}",
+	})
+
+	var progressCalls int
+	opts := DefaultScanOptions()
+	opts.Progress = func(visited, found int) { progressCalls++ }
+
+	scan, err := ScanDir(context.Background(), dir, opts)
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+
+	if !scan.Monorepo {
+		t.Error("expected a monorepo")
+	}
+	if len(scan.Projects) != 2 {
+		t.Fatalf("got %d projects, want 2", len(scan.Projects))
+	}
+	if scan.Projects[0].Stack != StackGo || scan.Projects[1].Stack != StackNode {
+		t.Errorf("stacks = %s, %s; want go, node", scan.Projects[0].Stack, scan.Projects[1].Stack)
+	}
+	if progressCalls == 0 {
+		t.Error("expected progress callbacks")
+	}
+}
+
+func TestScanDir_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ScanDir(ctx, t.TempDir(), DefaultScanOptions()); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+}
+
+// cargoDetector recognizes Rust crates, exercising RegisterDetector
+type cargoDetector struct{}
+
+func (cargoDetector) Stack() Stack { return "rust" }
+
+func (cargoDetector) Detect(dir Listing) Detection {
+	if dir.HasFile("Cargo.toml") {
+		return Detection{Confidence: 1, Evidence: []string{"Cargo.toml"}}
+	}
+	return Detection{}
+}
+
+func TestRegisterDetector(t *testing.T) {
+	RegisterDetector(cargoDetector{})
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"Cargo.toml": "[package]\n"})
+
+	scan, err := ScanDir(context.Background(), dir, DefaultScanOptions())
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+	if len(scan.Projects) != 1 || scan.Projects[0].Stack != "rust" {
+		t.Fatalf("projects = %+v, want one rust project", scan.Projects)
+	}
+
+	// Custom stacks render with the generic templates
+	files, err := Render(scan)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if _, ok := files.Get(".agent/stack.md"); !ok {
+		t.Error("expected .agent/stack.md for a custom stack")
+	}
+}
+
+func TestRender_DoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": "module x\n"})
+
+	scan, err := ScanDir(context.Background(), dir, DefaultScanOptions())
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+
+	files, err := Render(scan)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	agents, ok := files.Get("AGENTS.md")
+	if !ok {
+		t.Fatal("expected AGENTS.md in the file set")
+	}
+	if !strings.Contains(string(agents.Content), "Go project") {
+		t.Errorf("AGENTS.md should describe a Go project, got:\n%s", agents.Content)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Render() touched the disk: %d entries", len(entries))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"Makefile": "# custom\n"})
+
+	fs := &FileSet{Root: dir, Files: []File{
+		{Path: "Makefile", Content: []byte("generated\n")},
+		{Path: ".agent/stack.md", Content: []byte("# Stack\n")},
+	}}
+
+	written, err := Write(fs, WriteOptions{})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(written) != 1 || written[0] != filepath.Join(dir, ".agent", "stack.md") {
+		t.Errorf("written = %v, want only .agent/stack.md", written)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "Makefile"))
+	if string(content) != "# custom\n" {
+		t.Error("Write() overwrote an existing file without Force")
+	}
+
+	written, err = Write(fs, WriteOptions{Force: true})
+	if err != nil {
+		t.Fatalf("Write(Force) error = %v", err)
+	}
+	if len(written) != 2 {
+		t.Errorf("Write(Force) wrote %d files, want 2", len(written))
+	}
+}
+
+func TestGenerate_MigratesLegacyAgents(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":    "module x\n",
+		"AGENTS.md": "# Hand-written notes\n",
+	})
+
+	scan, err := ScanDir(context.Background(), dir, DefaultScanOptions())
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+
+	written, err := Generate(scan, WriteOptions{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(written) == 0 {
+		t.Fatal("Generate() wrote nothing")
+	}
+
+	legacy, err := os.ReadFile(filepath.Join(dir, ".agent", "AGENTS_LEGACY.md"))
+	if err != nil || string(legacy) != "# Hand-written notes\n" {
+		t.Errorf("legacy AGENTS.md not preserved: %q, %v", legacy, err)
+	}
+}
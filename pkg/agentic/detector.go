@@ -0,0 +1,81 @@
+package agentic
+
+import (
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// Listing is a single directory listing handed to detectors. It is read
+// once per directory and shared by all detectors.
+type Listing interface {
+	// Path is the absolute path of the directory
+	Path() string
+	// Has reports whether the directory contains an entry with this name
+	Has(name string) bool
+	// HasFile reports whether the directory contains a non-directory entry
+	HasFile(name string) bool
+	// HasDir reports whether the directory contains a subdirectory
+	HasDir(name string) bool
+	// Files returns the names of non-directory entries in lexical order
+	Files() []string
+	// Subdirs returns the names of subdirectories in lexical order
+	Subdirs() []string
+}
+
+// Detection is a detector's verdict on one directory
+type Detection struct {
+	// Confidence is between 0 (no evidence) and 1 (certain)
+	Confidence float64
+	// Evidence lists the files that contributed to the score
+	Evidence []string
+}
+
+// Detector recognizes one stack from a directory listing
+type Detector interface {
+	// Stack is the stack this detector reports
+	Stack() Stack
+	// Detect scores a directory; return a zero Detection for no match
+	Detect(dir Listing) Detection
+}
+
+// RegisterDetector adds a detector after the built-in ones. When several
+// detectors match a directory the most confident wins, and ties go to the
+// detector registered first. Projects with a custom stack are rendered
+// with the generic templates.
+func RegisterDetector(d Detector) {
+	detector.Register(&detectorAdapter{d: d})
+}
+
+// detectorAdapter exposes a public Detector through the internal interface
+type detectorAdapter struct {
+	d Detector
+}
+
+func (a *detectorAdapter) Detect(path string) bool {
+	dir, err := detector.ReadDir(path)
+	if err != nil {
+		return false
+	}
+	return a.Match(dir).Matched()
+}
+
+func (a *detectorAdapter) Match(dir *detector.Dir) detector.Detection {
+	det := a.d.Detect(listing{dir})
+	return detector.Detection{
+		Stack:      a.Type(),
+		Confidence: det.Confidence,
+		Evidence:   det.Evidence,
+	}
+}
+
+func (a *detectorAdapter) Type() detector.StackType {
+	return detector.StackType(a.d.Stack())
+}
+
+// listing implements Listing on top of the internal directory listing
+type listing struct {
+	*detector.Dir
+}
+
+func (l listing) Path() string {
+	return l.Dir.Path
+}
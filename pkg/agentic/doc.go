@@ -0,0 +1,31 @@
+// Package agentic is the supported Go API of agentic-repo. It exposes the
+// same detection and generation the CLI uses, so other tools can scan a
+// repository, register their own detectors, render the agent context files
+// in memory and write them to disk.
+//
+// A typical program scans a directory, renders the files and writes them:
+//
+//	scan, err := agentic.ScanDir(ctx, root, agentic.DefaultScanOptions())
+//	if err != nil {
+//		return err
+//	}
+//	files, err := agentic.Render(scan)
+//	if err != nil {
+//		return err
+//	}
+//	written, err := agentic.Write(files, agentic.WriteOptions{})
+//
+// # Stability
+//
+// This package follows semantic versioning together with the module.
+// Within a major version, exported identifiers are not removed and their
+// signatures and meaning do not change incompatibly. New functions, new
+// struct fields and new Stack values may be added in minor releases, so
+// construct option structs with field names and do not rely on the
+// exhaustiveness of switch statements over Stack. Before v1.0.0, breaking
+// changes only happen in minor releases and are called out in the release
+// notes.
+//
+// The rendered content of individual files is not part of the API and
+// may change in any release. Everything under internal/ is unsupported.
+package agentic
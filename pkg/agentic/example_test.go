@@ -0,0 +1,107 @@
+package agentic_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Shaked/agentic-repo/pkg/agentic"
+)
+
+// exampleRepo creates a small monorepo for the examples
+func exampleRepo() string {
+	root, _ := os.MkdirTemp("", "agentic-example")
+	os.MkdirAll(filepath.Join(root, "api"), 0755)
+	os.MkdirAll(filepath.Join(root, "web"), 0755)
+	os.WriteFile(filepath.Join(root, "api", "go.mod"), []byte("module api\n"), 0644)
+	os.WriteFile(filepath.Join(root, "web", "package.json"), []byte("{}"), 0644)
+	return root
+}
+
+func ExampleScanDir() {
+	root := exampleRepo()
+	defer os.RemoveAll(root)
+
+	scan, err := agentic.ScanDir(context.Background(), root, agentic.DefaultScanOptions())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println("monorepo:", scan.Monorepo)
+	for _, p := range scan.Projects {
+		rel, _ := filepath.Rel(root, p.Path)
+		fmt.Printf("%s: %s\n", rel, p.Stack)
+	}
+	// Output:
+	// monorepo: true
+	// api: go
+	// web: node
+}
+
+func ExampleRender() {
+	root := exampleRepo()
+	defer os.RemoveAll(root)
+
+	scan, _ := agentic.ScanDir(context.Background(), root, agentic.DefaultScanOptions())
+	files, err := agentic.Render(scan)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, path := range []string{"AGENTS.md", "api/AGENTS.md", "web/.agent/stack.md"} {
+		_, ok := files.Get(path)
+		fmt.Printf("%s: %v\n", path, ok)
+	}
+	// Output:
+	// AGENTS.md: true
+	// api/AGENTS.md: true
+	// web/.agent/stack.md: true
+}
+
+// makefileDetector recognizes plain Makefile projects
+type makefileDetector struct{}
+
+func (makefileDetector) Stack() agentic.Stack { return "make" }
+
+func (makefileDetector) Detect(dir agentic.Listing) agentic.Detection {
+	if dir.HasFile("GNUmakefile") {
+		return agentic.Detection{Confidence: 0.9, Evidence: []string{"GNUmakefile"}}
+	}
+	return agentic.Detection{}
+}
+
+func ExampleRegisterDetector() {
+	agentic.RegisterDetector(makefileDetector{})
+
+	root, _ := os.MkdirTemp("", "agentic-example")
+	defer os.RemoveAll(root)
+	os.WriteFile(filepath.Join(root, "GNUmakefile"), []byte("all:\n"), 0644)
+
+	scan, _ := agentic.ScanDir(context.Background(), root, agentic.DefaultScanOptions())
+	fmt.Println(scan.Projects[0].Stack, scan.Projects[0].Evidence)
+	// Output:
+	// make [GNUmakefile]
+}
+
+func ExampleWrite() {
+	root, _ := os.MkdirTemp("", "agentic-example")
+	defer os.RemoveAll(root)
+
+	files := &agentic.FileSet{Root: root, Files: []agentic.File{
+		{Path: ".agent/notes.md", Content: []byte("# Notes\n")},
+	}}
+	written, err := agentic.Write(files, agentic.WriteOptions{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, path := range written {
+		rel, _ := filepath.Rel(root, path)
+		fmt.Println(filepath.ToSlash(rel))
+	}
+	// Output:
+	// .agent/notes.md
+}
@@ -0,0 +1,89 @@
+package agentic
+
+import (
+	"path/filepath"
+
+	"github.com/Shaked/agentic-repo/internal/generator"
+)
+
+// File is a rendered agent context file
+type File struct {
+	// Path is relative to the FileSet root and slash-separated
+	Path string
+	// Content is the rendered file content
+	Content []byte
+}
+
+// FileSet is a set of rendered files for one repository
+type FileSet struct {
+	// Root is the absolute directory the paths are relative to
+	Root string
+	// Files lists the rendered files in generation order
+	Files []File
+}
+
+// Get returns the file with the given slash-separated relative path
+func (fs *FileSet) Get(path string) (File, bool) {
+	for _, f := range fs.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// WriteOptions controls how files are written to disk
+type WriteOptions struct {
+	// Force overwrites existing files; by default they are left alone
+	Force bool
+	// DryRun reports what would be written without touching the disk
+	DryRun bool
+}
+
+// Render renders every agent context file for the scan in memory. Nothing
+// is written, but the repository is read: CI workflows and task files for
+// the commands, sources for architecture.md, .agent/rules.md, an existing
+// AGENTS.md, and the whole tree for the entries of .agentignore.
+func Render(scan *Scan) (*FileSet, error) {
+	gen := generator.New(generator.Options{Quiet: true})
+	files, err := gen.Render(scan.Root, scan.results(), scan.Monorepo)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := &FileSet{Root: scan.Root}
+	for _, f := range files {
+		rel, err := filepath.Rel(scan.Root, f.Path)
+		if err != nil {
+			return nil, err
+		}
+		fs.Files = append(fs.Files, File{Path: filepath.ToSlash(rel), Content: f.Content})
+	}
+	return fs, nil
+}
+
+// Write writes a rendered file set below its root and returns the
+// absolute paths that were written. Existing files are skipped unless
+// opts.Force is set. Write does not move an existing AGENTS.md aside; use
+// Generate for the full CLI behaviour.
+func Write(fs *FileSet, opts WriteOptions) ([]string, error) {
+	gen := generator.New(generator.Options{Force: opts.Force, DryRun: opts.DryRun, Quiet: true})
+
+	files := make([]generator.File, 0, len(fs.Files))
+	for _, f := range fs.Files {
+		files = append(files, generator.File{
+			Path:    filepath.Join(fs.Root, filepath.FromSlash(f.Path)),
+			Content: f.Content,
+		})
+	}
+	return gen.WriteFiles(files)
+}
+
+// Generate renders and writes the files for a scan exactly like
+// `agentic-repo init`: an existing AGENTS.md is first moved to
+// .agent/AGENTS_LEGACY.md so the new router can take its place. It
+// returns the absolute paths that were written.
+func Generate(scan *Scan, opts WriteOptions) ([]string, error) {
+	gen := generator.New(generator.Options{Force: opts.Force, DryRun: opts.DryRun, Quiet: true})
+	return gen.GenerateFiles(scan.Root, scan.results(), scan.Monorepo)
+}
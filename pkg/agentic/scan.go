@@ -0,0 +1,128 @@
+package agentic
+
+import (
+	"context"
+
+	"github.com/Shaked/agentic-repo/internal/detector"
+)
+
+// Stack identifies a project's technology stack
+type Stack string
+
+// Built-in stacks. Custom detectors may report any other value.
+const (
+	StackGo      Stack = "go"
+	StackPython  Stack = "python"
+	StackNode    Stack = "node"
+	StackJava    Stack = "java"
+	StackUnknown Stack = "unknown"
+)
+
+// Project is a detected project inside the scanned root
+type Project struct {
+	// Path is the absolute directory of the project
+	Path string
+	// Stack is the winning stack
+	Stack Stack
+	// Confidence of Stack, between 0 and 1
+	Confidence float64
+	// Evidence lists the files that led to Stack
+	Evidence []string
+	// Tools holds tooling metadata such as "package_manager"
+	Tools map[string]string
+}
+
+// Scan is the result of scanning a repository
+type Scan struct {
+	// Root is the absolute directory that was scanned
+	Root string
+	// Projects lists detected projects in depth-first path order
+	Projects []Project
+	// Monorepo is true when more than one project was detected
+	Monorepo bool
+}
+
+// ScanOptions controls how ScanDir walks the directory tree
+type ScanOptions struct {
+	// MaxDepth limits how deep below the root projects are detected;
+	// 0 only checks the root itself
+	MaxDepth int
+	// Include, when non-empty, restricts detection to directories whose
+	// root-relative path matches one of these gitignore-style globs
+	Include []string
+	// Exclude skips directories matching these gitignore-style globs
+	Exclude []string
+	// NoIgnoreFiles disables reading .gitignore and .agentignore files and
+	// skipping the default excluded directories such as node_modules/
+	NoIgnoreFiles bool
+	// MinConfidence filters out detections weaker than this
+	MinConfidence float64
+	// Workers bounds how many directories are read concurrently;
+	// 0 uses one worker per CPU
+	Workers int
+	// Progress, if set, is called after each directory is visited with
+	// the number of directories visited and projects found so far. Calls
+	// are serialized.
+	Progress func(visited, found int)
+}
+
+// DefaultScanOptions returns the options the CLI uses by default
+func DefaultScanOptions() ScanOptions {
+	d := detector.DefaultScanOptions()
+	return ScanOptions{MaxDepth: d.MaxDepth, MinConfidence: d.MinConfidence}
+}
+
+// ScanDir detects the projects below root. root must be an absolute path
+// to an existing directory.
+func ScanDir(ctx context.Context, root string, opts ScanOptions) (*Scan, error) {
+	internal := detector.ScanOptions{
+		MaxDepth:      opts.MaxDepth,
+		Include:       opts.Include,
+		Exclude:       opts.Exclude,
+		NoIgnoreFiles: opts.NoIgnoreFiles,
+		MinConfidence: opts.MinConfidence,
+		Workers:       opts.Workers,
+	}
+	if opts.Progress != nil {
+		internal.Progress = func(p detector.Progress) {
+			opts.Progress(p.Visited, p.Found)
+		}
+	}
+
+	results, err := detector.ScanContext(ctx, root, internal)
+	if err != nil {
+		return nil, err
+	}
+
+	scan := &Scan{Root: root, Monorepo: detector.IsMonorepo(results)}
+	for _, r := range results {
+		scan.Projects = append(scan.Projects, Project{
+			Path:       r.Path,
+			Stack:      Stack(r.Stack),
+			Confidence: r.Confidence,
+			Evidence:   r.Evidence,
+			Tools:      r.Tools,
+		})
+	}
+	return scan, nil
+}
+
+// results converts the scan back to detector results for the generator.
+// An empty scan becomes a single unknown project at the root, as in the
+// CLI.
+func (s *Scan) results() []detector.Result {
+	if len(s.Projects) == 0 {
+		return []detector.Result{{Path: s.Root, Stack: detector.StackUnknown}}
+	}
+	results := make([]detector.Result, 0, len(s.Projects))
+	for _, p := range s.Projects {
+		results = append(results, detector.Result{
+			Path:       p.Path,
+			Stack:      detector.StackType(p.Stack),
+			Confidence: p.Confidence,
+			Evidence:   p.Evidence,
+			Tools:      p.Tools,
+		})
+	}
+	return results
+}